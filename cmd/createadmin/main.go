@@ -0,0 +1,62 @@
+// Command createadmin adds an admin account. Admins cannot register through
+// the API.
+//
+//	go run ./cmd/createadmin -email admin@example.com -password secret -name "Admin"
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func main() {
+	email := flag.String("email", "", "admin email")
+	password := flag.String("password", "", "admin password (min 6 characters)")
+	name := flag.String("name", "", "admin display name")
+	flag.Parse()
+
+	if *email == "" || len(*password) < 6 {
+		log.Fatal("usage: createadmin -email <email> -password <password> [-name <name>]")
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: No .env file found")
+	}
+
+	if err := database.ConnectDB(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.DB.Close()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Fatalf("Failed to hash password: %v", err)
+	}
+
+	var adminName *string
+	if *name != "" {
+		adminName = name
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	admin, err := repository.New(database.DB).CreateAdmin(ctx, repository.CreateAdminParams{
+		Email:        *email,
+		PasswordHash: string(hashedPassword),
+		Name:         adminName,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create admin: %v", err)
+	}
+
+	fmt.Printf("Created admin %s (%s)\n", admin.Email, admin.ID.String())
+}
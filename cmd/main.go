@@ -10,7 +10,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/SRIRAMGJ007/Health-Sync/internal/availability"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/loginguard"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/oidc"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/routes"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/scheduler"
@@ -41,20 +45,34 @@ func main() {
 		log.Fatalf("Failed to initialize Firebase: %v", err)
 	}
 
+	// Initialize Mailer
+	mail := mailer.FromEnv()
+
+	// Initialize Login Guard
+	guard := loginguard.FromEnv(queries)
+
+	// Initialize OIDC Providers
+	providers, err := oidc.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure OIDC providers: %v", err)
+	}
+
 	// Initialize Gin Router
 	r := gin.Default()
 
 	// Setup Routes
-	routes.AuthRoutes(r, queries)
-	routes.UserRoutes(r, queries)
+	routes.AuthRoutes(r, queries, mail, guard, providers)
+	routes.UserRoutes(r, queries, mail)
 	routes.DoctorRoutes(r, queries)
 	routes.EMRRoutes(r, queries)
+	routes.AdminRoutes(r, queries, mail)
 
 	// Start Scheduler (as a Go routine)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	go scheduler.StartMedicationScheduler(ctx, queries)
+	go availability.StartMaterializer(ctx, queries, time.Hour)
 
 	// Start Server
 	httpServer := &http.Server{
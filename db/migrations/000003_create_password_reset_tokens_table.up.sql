@@ -0,0 +1,11 @@
+CREATE TABLE password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL, -- users.id or doctors.id depending on role
+    role TEXT NOT NULL CHECK (role IN ('user', 'doctor')),
+    token_hash TEXT UNIQUE NOT NULL, -- SHA-256 of the token mailed to the account
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_password_reset_tokens_account_id ON password_reset_tokens (account_id);
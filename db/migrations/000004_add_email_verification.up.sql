@@ -0,0 +1,18 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE doctors ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- Accounts created before verification existed keep working as before.
+UPDATE users SET email_verified_at = NOW();
+UPDATE doctors SET email_verified_at = NOW();
+
+CREATE TABLE email_verification_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL, -- users.id or doctors.id depending on role
+    role TEXT NOT NULL CHECK (role IN ('user', 'doctor')),
+    token_hash TEXT UNIQUE NOT NULL, -- SHA-256 of the token mailed to the account
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_verification_tokens_account_id ON email_verification_tokens (account_id);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS doctor_license_documents;
+
+ALTER TABLE doctors
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS verification_notes,
+    DROP COLUMN IF EXISTS verification_status;
+
+DROP TABLE IF EXISTS admins;
@@ -0,0 +1,27 @@
+CREATE TABLE admins (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    name TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE doctors
+    ADD COLUMN verification_status TEXT NOT NULL DEFAULT 'pending_verification'
+        CHECK (verification_status IN ('pending_verification', 'verified', 'rejected')),
+    ADD COLUMN verification_notes TEXT, -- reason given by the admin on rejection
+    ADD COLUMN reviewed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN reviewed_by UUID REFERENCES admins(id);
+
+-- Doctors registered before the review process stay listed.
+UPDATE doctors SET verification_status = 'verified';
+
+CREATE TABLE doctor_license_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID REFERENCES doctors(id) ON DELETE CASCADE NOT NULL,
+    file_name TEXT NOT NULL,
+    file_data BYTEA NOT NULL,  -- Stores encrypted file
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_doctor_license_documents_doctor_id ON doctor_license_documents (doctor_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS subject_token_revocations;
+
+ALTER TABLE doctors DROP COLUMN IF EXISTS suspended_at;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_at;
@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE doctors ADD COLUMN suspended_at TIMESTAMP WITH TIME ZONE;
+
+-- Access tokens of a subject issued before revoked_before are rejected,
+-- used by admins to force a logout everywhere.
+CREATE TABLE subject_token_revocations (
+    subject_id UUID PRIMARY KEY, -- users.id, doctors.id or admins.id
+    revoked_before TIMESTAMP WITH TIME ZONE NOT NULL
+);
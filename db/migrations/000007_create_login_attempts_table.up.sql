@@ -0,0 +1,20 @@
+-- Failed login counters used by the login guard, keyed by account or IP.
+CREATE TABLE login_attempts (
+    attempt_key TEXT PRIMARY KEY, -- e.g. account:user:jane@example.com or ip:203.0.113.7
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    blocked_until TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE login_audit (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email TEXT NOT NULL,
+    role TEXT NOT NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    reason TEXT NOT NULL, -- unknown_account, invalid_password, suspended, locked
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_login_audit_email ON login_audit (email);
+CREATE INDEX idx_login_audit_created_at ON login_audit (created_at);
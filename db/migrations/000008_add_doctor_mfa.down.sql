@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS doctor_mfa_recovery_codes;
+
+ALTER TABLE doctors DROP COLUMN IF EXISTS mfa_last_used_step;
+ALTER TABLE doctors DROP COLUMN IF EXISTS mfa_required;
+ALTER TABLE doctors DROP COLUMN IF EXISTS mfa_enabled_at;
+ALTER TABLE doctors DROP COLUMN IF EXISTS mfa_secret;
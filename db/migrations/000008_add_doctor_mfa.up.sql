@@ -0,0 +1,24 @@
+ALTER TABLE doctors ADD COLUMN mfa_secret BYTEA; -- TOTP secret, encrypted with ENCRYPTION_KEY
+ALTER TABLE doctors ADD COLUMN mfa_enabled_at TIMESTAMP WITH TIME ZONE; -- set once enrollment is confirmed
+ALTER TABLE doctors ADD COLUMN mfa_required BOOLEAN NOT NULL DEFAULT FALSE; -- enforced by an admin
+ALTER TABLE doctors ADD COLUMN mfa_last_used_step BIGINT; -- last accepted TOTP time step, stops code replay
+
+CREATE TABLE doctor_mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL, -- SHA-256 of the recovery code
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_doctor_mfa_recovery_codes_doctor_id ON doctor_mfa_recovery_codes (doctor_id);
+
+CREATE TABLE mfa_challenges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL, -- SHA-256 of the challenge token returned by the first login step
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
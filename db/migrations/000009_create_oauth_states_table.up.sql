@@ -0,0 +1,9 @@
+CREATE TABLE oauth_states (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider TEXT NOT NULL,
+    state_hash TEXT UNIQUE NOT NULL, -- SHA-256 of the state parameter sent to the provider
+    code_verifier TEXT NOT NULL, -- PKCE verifier, sent with the code exchange
+    nonce TEXT NOT NULL, -- must be echoed back in the ID token
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
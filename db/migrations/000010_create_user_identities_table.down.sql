@@ -0,0 +1,10 @@
+ALTER TABLE oauth_states DROP COLUMN IF EXISTS role;
+
+ALTER TABLE users ADD COLUMN google_id TEXT UNIQUE;
+
+UPDATE users
+SET google_id = user_identities.subject
+FROM user_identities
+WHERE user_identities.account_id = users.id AND user_identities.provider = 'google' AND user_identities.role = 'user';
+
+DROP TABLE IF EXISTS user_identities;
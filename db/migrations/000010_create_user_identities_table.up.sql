@@ -0,0 +1,24 @@
+CREATE TABLE user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider TEXT NOT NULL, -- name of the configured OIDC provider, e.g. google
+    subject TEXT NOT NULL, -- "sub" claim issued by the provider
+    account_id UUID NOT NULL, -- users.id or doctors.id depending on role
+    role TEXT NOT NULL CHECK (role IN ('user', 'doctor')),
+    email TEXT, -- email asserted by the provider when the identity was linked
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX idx_user_identities_account_id ON user_identities (account_id);
+
+-- Google logins so far were stored on the users row.
+INSERT INTO user_identities (provider, subject, account_id, role, email)
+SELECT 'google', google_id, id, 'user', email
+FROM users
+WHERE google_id IS NOT NULL;
+
+ALTER TABLE users DROP COLUMN google_id;
+
+-- Remembers which kind of account the login was started for.
+ALTER TABLE oauth_states ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'doctor'));
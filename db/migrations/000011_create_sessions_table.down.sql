@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN fcm_token TEXT;
+
+UPDATE users
+SET fcm_token = latest.fcm_token
+FROM (
+    SELECT DISTINCT ON (subject_id) subject_id, fcm_token
+    FROM sessions
+    WHERE role = 'user' AND fcm_token IS NOT NULL AND revoked_at IS NULL
+    ORDER BY subject_id, last_seen_at DESC
+) AS latest
+WHERE latest.subject_id = users.id;
+
+DROP TABLE IF EXISTS sessions;
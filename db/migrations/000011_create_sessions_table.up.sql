@@ -0,0 +1,24 @@
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY, -- family_id of the refresh tokens issued for this login
+    subject_id UUID NOT NULL, -- users.id or doctors.id depending on role
+    role TEXT NOT NULL,
+    device_name TEXT,
+    platform TEXT, -- e.g. android, ios, web
+    fcm_token TEXT, -- push token of the app signed in with this session
+    ip_address TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_sessions_subject_id ON sessions (subject_id);
+
+-- Push tokens so far were stored on the users row; keep them as a device
+-- session so reminders keep arriving until the app signs in again.
+INSERT INTO sessions (id, subject_id, role, device_name, fcm_token)
+SELECT gen_random_uuid(), id, 'user', 'Unknown device', fcm_token
+FROM users
+WHERE fcm_token IS NOT NULL;
+
+ALTER TABLE users DROP COLUMN fcm_token;
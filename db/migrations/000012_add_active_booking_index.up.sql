@@ -0,0 +1,14 @@
+-- Slots double booked before this constraint keep their earliest booking.
+UPDATE bookings
+SET status = 'canceled', updated_at = NOW()
+WHERE status <> 'canceled'
+  AND EXISTS (
+      SELECT 1
+      FROM bookings earlier
+      WHERE earlier.availability_id = bookings.availability_id
+        AND earlier.status <> 'canceled'
+        AND (earlier.created_at, earlier.id) < (bookings.created_at, bookings.id)
+  );
+
+-- At most one active booking per availability slot.
+CREATE UNIQUE INDEX idx_bookings_active_availability ON bookings (availability_id) WHERE status <> 'canceled';
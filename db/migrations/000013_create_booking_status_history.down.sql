@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS booking_status_history;
+
+DROP INDEX IF EXISTS idx_bookings_active_availability;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+
+UPDATE bookings SET status = 'canceled' WHERE status IN ('cancelled_by_patient', 'cancelled_by_doctor', 'rescheduled');
+
+CREATE UNIQUE INDEX idx_bookings_active_availability ON bookings (availability_id) WHERE status <> 'canceled';
@@ -0,0 +1,36 @@
+-- Statuses so far were free text. Only doctors could set them, so existing
+-- cancellations were made by the doctor.
+UPDATE bookings SET status = 'cancelled_by_doctor' WHERE status IN ('canceled', 'cancelled');
+UPDATE bookings SET status = 'pending'
+WHERE status NOT IN ('pending', 'confirmed', 'completed', 'no_show', 'cancelled_by_patient', 'cancelled_by_doctor', 'rescheduled');
+
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending', 'confirmed', 'completed', 'no_show', 'cancelled_by_patient', 'cancelled_by_doctor', 'rescheduled'));
+
+DROP INDEX IF EXISTS idx_bookings_active_availability;
+CREATE UNIQUE INDEX idx_bookings_active_availability ON bookings (availability_id)
+    WHERE status IN ('pending', 'confirmed', 'completed', 'no_show');
+
+-- Cancelled bookings never released their slot.
+UPDATE doctor_availability
+SET is_booked = FALSE, updated_at = NOW()
+WHERE is_booked
+  AND NOT EXISTS (
+      SELECT 1
+      FROM bookings
+      WHERE bookings.availability_id = doctor_availability.id
+        AND bookings.status IN ('pending', 'confirmed', 'completed', 'no_show')
+  );
+
+CREATE TABLE booking_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    from_status TEXT, -- NULL when the booking was created
+    to_status TEXT NOT NULL,
+    changed_by UUID NOT NULL, -- users.id or doctors.id depending on changed_by_role
+    changed_by_role TEXT NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_booking_status_history_booking_id ON booking_status_history (booking_id);
@@ -0,0 +1,2 @@
+-- Links a booking to the one it replaced when the patient rescheduled.
+ALTER TABLE bookings ADD COLUMN rescheduled_from UUID REFERENCES bookings(id);
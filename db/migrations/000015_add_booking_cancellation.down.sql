@@ -0,0 +1,8 @@
+ALTER TABLE doctors
+    DROP COLUMN IF EXISTS cancellation_notice_minutes,
+    DROP COLUMN IF EXISTS late_cancellation_fee;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancellation_reason,
+    DROP COLUMN IF EXISTS late_cancellation_fee;
@@ -0,0 +1,15 @@
+ALTER TABLE bookings
+    ADD COLUMN cancelled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN cancellation_reason TEXT,
+    ADD COLUMN late_cancellation_fee BOOLEAN NOT NULL DEFAULT FALSE; -- cancelled inside the doctor's notice period, the fee is due
+
+UPDATE bookings
+SET cancelled_at = updated_at
+WHERE status IN ('cancelled_by_patient', 'cancelled_by_doctor');
+
+-- A patient cancelling less than cancellation_notice_minutes before the
+-- appointment is charged the late fee when late_cancellation_fee is set, and
+-- cannot cancel online otherwise.
+ALTER TABLE doctors
+    ADD COLUMN cancellation_notice_minutes INTEGER NOT NULL DEFAULT 0 CHECK (cancellation_notice_minutes >= 0),
+    ADD COLUMN late_cancellation_fee BOOLEAN NOT NULL DEFAULT FALSE;
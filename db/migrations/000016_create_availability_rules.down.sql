@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_doctor_availability_rule_slot;
+
+ALTER TABLE doctor_availability DROP COLUMN IF EXISTS rule_id;
+
+DROP TABLE IF EXISTS availability_exceptions;
+
+DROP TABLE IF EXISTS availability_rules;
@@ -0,0 +1,35 @@
+-- Weekly clinic hours that are turned into doctor_availability slots.
+CREATE TABLE availability_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0 is Sunday, as EXTRACT(DOW)
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    slot_minutes INTEGER NOT NULL CHECK (slot_minutes > 0),
+    buffer_minutes INTEGER NOT NULL DEFAULT 0 CHECK (buffer_minutes >= 0),
+    valid_from DATE NOT NULL,
+    valid_until DATE,
+    materialized_until DATE, -- slots exist up to and including this date
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (end_time > start_time),
+    CHECK (valid_until IS NULL OR valid_until >= valid_from)
+);
+
+CREATE INDEX idx_availability_rules_doctor_id ON availability_rules (doctor_id);
+
+-- Days without slots, such as holidays.
+CREATE TABLE availability_exceptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    exception_date DATE NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (doctor_id, exception_date)
+);
+
+ALTER TABLE doctor_availability ADD COLUMN rule_id UUID REFERENCES availability_rules(id) ON DELETE SET NULL;
+
+-- Makes materializing a rule idempotent.
+CREATE UNIQUE INDEX idx_doctor_availability_rule_slot ON doctor_availability (rule_id, availability_date, start_time)
+    WHERE rule_id IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE doctor_availability DROP CONSTRAINT IF EXISTS doctor_availability_no_overlap;
+ALTER TABLE doctor_availability DROP CONSTRAINT IF EXISTS doctor_availability_time_order;
@@ -0,0 +1,34 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Free slots that cannot satisfy the new constraints are dropped: slots that
+-- end before they start, and slots overlapping a booked or earlier slot of the
+-- same doctor. Slots that were ever booked are kept.
+DELETE FROM doctor_availability
+WHERE end_time <= start_time
+  AND NOT EXISTS (SELECT 1 FROM bookings WHERE bookings.availability_id = doctor_availability.id);
+
+DELETE FROM doctor_availability
+WHERE NOT EXISTS (SELECT 1 FROM bookings WHERE bookings.availability_id = doctor_availability.id)
+  AND EXISTS (
+      SELECT 1
+      FROM doctor_availability other
+      WHERE other.doctor_id = doctor_availability.doctor_id
+        AND other.availability_date = doctor_availability.availability_date
+        AND other.id <> doctor_availability.id
+        AND other.start_time < doctor_availability.end_time
+        AND doctor_availability.start_time < other.end_time
+        AND (
+            EXISTS (SELECT 1 FROM bookings WHERE bookings.availability_id = other.id)
+            OR (other.created_at, other.id) < (doctor_availability.created_at, doctor_availability.id)
+        )
+  );
+
+ALTER TABLE doctor_availability
+    ADD CONSTRAINT doctor_availability_time_order CHECK (end_time > start_time);
+
+-- A doctor cannot have two slots at the same time.
+ALTER TABLE doctor_availability
+    ADD CONSTRAINT doctor_availability_no_overlap EXCLUDE USING gist (
+        doctor_id WITH =,
+        tsrange(availability_date + start_time, availability_date + end_time) WITH &&
+    );
@@ -0,0 +1,24 @@
+DROP INDEX IF EXISTS idx_bookings_queue_position;
+DROP INDEX IF EXISTS idx_bookings_availability_id;
+
+-- Windows shared by several patients keep their earliest booking.
+UPDATE bookings
+SET status = 'cancelled_by_doctor', updated_at = NOW()
+WHERE status IN ('pending', 'confirmed', 'completed', 'no_show')
+  AND EXISTS (
+      SELECT 1
+      FROM bookings earlier
+      WHERE earlier.availability_id = bookings.availability_id
+        AND earlier.status IN ('pending', 'confirmed', 'completed', 'no_show')
+        AND (earlier.created_at, earlier.id) < (bookings.created_at, bookings.id)
+  );
+
+CREATE UNIQUE INDEX idx_bookings_active_availability ON bookings (availability_id)
+    WHERE status IN ('pending', 'confirmed', 'completed', 'no_show');
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS queue_position;
+
+ALTER TABLE doctor_availability
+    DROP COLUMN IF EXISTS booking_mode,
+    DROP COLUMN IF EXISTS capacity,
+    DROP COLUMN IF EXISTS slot_minutes;
@@ -0,0 +1,16 @@
+-- A window is split into slot_minutes long appointments, each of which can
+-- be booked capacity times. Without slot_minutes the whole window is one
+-- appointment, as before. In queue mode patients book a place in a walk-in
+-- queue for the window instead of a time.
+ALTER TABLE doctor_availability
+    ADD COLUMN slot_minutes INTEGER CHECK (slot_minutes > 0),
+    ADD COLUMN capacity INTEGER NOT NULL DEFAULT 1 CHECK (capacity > 0),
+    ADD COLUMN booking_mode TEXT NOT NULL DEFAULT 'slots' CHECK (booking_mode IN ('slots', 'queue'));
+
+ALTER TABLE bookings ADD COLUMN queue_position INTEGER;
+
+-- A window now holds several bookings. Booking locks the window row instead.
+DROP INDEX IF EXISTS idx_bookings_active_availability;
+CREATE INDEX idx_bookings_availability_id ON bookings (availability_id);
+CREATE UNIQUE INDEX idx_bookings_queue_position ON bookings (availability_id, queue_position)
+    WHERE queue_position IS NOT NULL;
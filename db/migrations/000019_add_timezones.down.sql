@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_medications_next_notify_at;
+ALTER TABLE medications DROP COLUMN IF EXISTS next_notify_at;
+UPDATE medications SET time_to_notify = time_to_notify - INTERVAL '5 hours 30 minutes';
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS ends_at,
+    DROP COLUMN IF EXISTS starts_at;
+
+DROP INDEX IF EXISTS idx_doctor_availability_doctor_starts_at;
+ALTER TABLE doctor_availability
+    DROP COLUMN IF EXISTS ends_at,
+    DROP COLUMN IF EXISTS starts_at;
+
+ALTER TABLE doctors DROP COLUMN IF EXISTS timezone;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,73 @@
+-- Times so far were entered in India Standard Time, which stays the default
+-- for accounts that have not picked a timezone.
+ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT 'Asia/Kolkata';
+ALTER TABLE doctors ADD COLUMN timezone TEXT NOT NULL DEFAULT 'Asia/Kolkata';
+
+-- Availability and bookings keep the doctor's wall clock in their DATE and
+-- TIME columns, and the instants it stands for in starts_at and ends_at.
+ALTER TABLE doctor_availability
+    ADD COLUMN starts_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN ends_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE doctor_availability
+SET starts_at = (availability_date + start_time) AT TIME ZONE tz.name,
+    ends_at = (availability_date + end_time) AT TIME ZONE tz.name
+FROM (
+    SELECT doctor_availability.id,
+           COALESCE(doctors.timezone, 'Asia/Kolkata') AS name
+    FROM doctor_availability
+    LEFT JOIN doctors ON doctors.id = doctor_availability.doctor_id
+) tz
+WHERE tz.id = doctor_availability.id;
+
+ALTER TABLE doctor_availability
+    ALTER COLUMN starts_at SET NOT NULL,
+    ALTER COLUMN ends_at SET NOT NULL;
+
+CREATE INDEX idx_doctor_availability_doctor_starts_at ON doctor_availability (doctor_id, starts_at);
+
+ALTER TABLE bookings
+    ADD COLUMN starts_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN ends_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE bookings
+SET starts_at = (booking_date + booking_start_time) AT TIME ZONE tz.name,
+    ends_at = (booking_date + booking_end_time) AT TIME ZONE tz.name
+FROM (
+    SELECT bookings.id,
+           COALESCE(doctors.timezone, 'Asia/Kolkata') AS name
+    FROM bookings
+    LEFT JOIN doctors ON doctors.id = bookings.doctor_id
+) tz
+WHERE tz.id = bookings.id;
+
+ALTER TABLE bookings
+    ALTER COLUMN starts_at SET NOT NULL,
+    ALTER COLUMN ends_at SET NOT NULL;
+
+-- time_to_notify was converted from IST to UTC before it was stored. It now
+-- is the wall clock time in the user's timezone, and next_notify_at is the
+-- instant the next reminder is due.
+UPDATE medications SET time_to_notify = time_to_notify + INTERVAL '5 hours 30 minutes';
+
+ALTER TABLE medications ADD COLUMN next_notify_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE medications
+SET next_notify_at = ((NOW() AT TIME ZONE users.timezone)::date + time_to_notify) AT TIME ZONE users.timezone
+FROM users
+WHERE users.id = medications.user_id;
+
+-- Weekly reminders were sent on the weekday the medication was last updated.
+UPDATE medications
+SET next_notify_at = next_notify_at + INTERVAL '1 day' * ((
+        EXTRACT(DOW FROM updated_at AT TIME ZONE 'Asia/Kolkata')::int
+        - EXTRACT(DOW FROM next_notify_at AT TIME ZONE 'Asia/Kolkata')::int + 7) % 7)
+WHERE frequency = 'weekly';
+
+UPDATE medications
+SET next_notify_at = next_notify_at + CASE frequency WHEN 'weekly' THEN INTERVAL '7 days' ELSE INTERVAL '1 day' END
+WHERE next_notify_at <= NOW();
+
+ALTER TABLE medications ALTER COLUMN next_notify_at SET NOT NULL;
+
+CREATE INDEX idx_medications_next_notify_at ON medications (next_notify_at);
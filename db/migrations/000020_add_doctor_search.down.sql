@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_doctor_availability_free_date;
+DROP INDEX IF EXISTS idx_doctor_availability_free;
+DROP INDEX IF EXISTS idx_doctors_experience;
+DROP INDEX IF EXISTS idx_doctors_consultation_fee;
+DROP INDEX IF EXISTS idx_doctors_hospital_name;
+DROP INDEX IF EXISTS idx_doctors_specialization;
+DROP INDEX IF EXISTS idx_doctors_name_trgm;
+DROP INDEX IF EXISTS idx_doctors_search;
+DROP FUNCTION IF EXISTS doctor_search_document(TEXT, TEXT, TEXT);
@@ -0,0 +1,25 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- The text patients search doctors by. Names and hospitals are matched as
+-- written, specializations with English stemming so that "cardiologists"
+-- finds "Cardiologist".
+CREATE FUNCTION doctor_search_document(name TEXT, specialization TEXT, hospital_name TEXT)
+RETURNS tsvector
+LANGUAGE sql IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(name, '')), 'A')
+        || setweight(to_tsvector('english', coalesce(specialization, '')), 'B')
+        || setweight(to_tsvector('simple', coalesce(hospital_name, '')), 'C')
+$$;
+
+CREATE INDEX idx_doctors_search ON doctors
+    USING GIN (doctor_search_document(name, specialization, hospital_name));
+CREATE INDEX idx_doctors_name_trgm ON doctors USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_doctors_specialization ON doctors (lower(specialization));
+CREATE INDEX idx_doctors_hospital_name ON doctors (lower(hospital_name));
+CREATE INDEX idx_doctors_consultation_fee ON doctors (consultation_fee) WHERE verification_status = 'verified';
+CREATE INDEX idx_doctors_experience ON doctors (experience) WHERE verification_status = 'verified';
+
+-- Free slots, for the next availability of a doctor and "free on date".
+CREATE INDEX idx_doctor_availability_free ON doctor_availability (doctor_id, starts_at) WHERE is_booked IS NOT TRUE;
+CREATE INDEX idx_doctor_availability_free_date ON doctor_availability (availability_date, doctor_id) WHERE is_booked IS NOT TRUE;
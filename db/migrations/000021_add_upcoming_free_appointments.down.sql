@@ -0,0 +1 @@
+DROP VIEW IF EXISTS upcoming_free_appointments;
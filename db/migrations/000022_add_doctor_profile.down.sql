@@ -0,0 +1,47 @@
+-- Restore the view of 000021 first, it depends on deactivated_at.
+CREATE OR REPLACE VIEW upcoming_free_appointments AS
+SELECT a.id AS availability_id,
+       a.doctor_id,
+       a.availability_date,
+       slot.local_start::time AS start_time,
+       (slot.local_start + slot_length.value)::time AS end_time,
+       slot.local_start AT TIME ZONE d.timezone AS starts_at,
+       (slot.local_start + slot_length.value) AT TIME ZONE d.timezone AS ends_at,
+       a.booking_mode,
+       a.capacity,
+       (a.capacity - taken.count)::int AS remaining
+FROM doctor_availability a
+JOIN doctors d ON d.id = a.doctor_id
+CROSS JOIN LATERAL (
+    SELECT CASE
+               WHEN a.booking_mode = 'queue' OR a.slot_minutes IS NULL THEN a.end_time - a.start_time
+               ELSE make_interval(mins => a.slot_minutes)
+           END AS value
+) slot_length
+CROSS JOIN LATERAL generate_series(
+    a.availability_date + a.start_time,
+    a.availability_date + a.end_time - slot_length.value,
+    slot_length.value
+) AS slot(local_start)
+CROSS JOIN LATERAL (
+    SELECT COUNT(*) AS count
+    FROM bookings b
+    WHERE b.availability_id = a.id
+      AND b.booking_start_time = slot.local_start::time
+      AND b.status IN ('pending', 'confirmed', 'completed', 'no_show')
+) taken
+WHERE a.is_booked IS NOT TRUE
+  AND a.ends_at > NOW()
+  AND slot.local_start AT TIME ZONE d.timezone > NOW()
+  AND taken.count < a.capacity;
+
+DROP TABLE IF EXISTS doctor_profile_photos;
+
+ALTER TABLE doctors
+    DROP CONSTRAINT IF EXISTS doctors_clinic_coordinates,
+    DROP COLUMN IF EXISTS deactivated_at,
+    DROP COLUMN IF EXISTS clinic_longitude,
+    DROP COLUMN IF EXISTS clinic_latitude,
+    DROP COLUMN IF EXISTS clinic_address,
+    DROP COLUMN IF EXISTS languages,
+    DROP COLUMN IF EXISTS bio;
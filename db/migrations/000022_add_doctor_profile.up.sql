@@ -0,0 +1,54 @@
+ALTER TABLE doctors
+    ADD COLUMN bio TEXT,
+    ADD COLUMN languages TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN clinic_address TEXT,
+    ADD COLUMN clinic_latitude DOUBLE PRECISION CHECK (clinic_latitude BETWEEN -90 AND 90),
+    ADD COLUMN clinic_longitude DOUBLE PRECISION CHECK (clinic_longitude BETWEEN -180 AND 180),
+    -- Deactivated doctors are hidden from patients. Their bookings stay.
+    ADD COLUMN deactivated_at TIMESTAMPTZ,
+    ADD CONSTRAINT doctors_clinic_coordinates CHECK ((clinic_latitude IS NULL) = (clinic_longitude IS NULL));
+
+CREATE TABLE doctor_profile_photos (
+    doctor_id UUID PRIMARY KEY REFERENCES doctors(id) ON DELETE CASCADE,
+    content_type TEXT NOT NULL,
+    data BYTEA NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Same view as in 000021, without the appointments of deactivated doctors.
+CREATE OR REPLACE VIEW upcoming_free_appointments AS
+SELECT a.id AS availability_id,
+       a.doctor_id,
+       a.availability_date,
+       slot.local_start::time AS start_time,
+       (slot.local_start + slot_length.value)::time AS end_time,
+       slot.local_start AT TIME ZONE d.timezone AS starts_at,
+       (slot.local_start + slot_length.value) AT TIME ZONE d.timezone AS ends_at,
+       a.booking_mode,
+       a.capacity,
+       (a.capacity - taken.count)::int AS remaining
+FROM doctor_availability a
+JOIN doctors d ON d.id = a.doctor_id
+CROSS JOIN LATERAL (
+    SELECT CASE
+               WHEN a.booking_mode = 'queue' OR a.slot_minutes IS NULL THEN a.end_time - a.start_time
+               ELSE make_interval(mins => a.slot_minutes)
+           END AS value
+) slot_length
+CROSS JOIN LATERAL generate_series(
+    a.availability_date + a.start_time,
+    a.availability_date + a.end_time - slot_length.value,
+    slot_length.value
+) AS slot(local_start)
+CROSS JOIN LATERAL (
+    SELECT COUNT(*) AS count
+    FROM bookings b
+    WHERE b.availability_id = a.id
+      AND b.booking_start_time = slot.local_start::time
+      AND b.status IN ('pending', 'confirmed', 'completed', 'no_show')
+) taken
+WHERE a.is_booked IS NOT TRUE
+  AND d.deactivated_at IS NULL
+  AND a.ends_at > NOW()
+  AND slot.local_start AT TIME ZONE d.timezone > NOW()
+  AND taken.count < a.capacity;
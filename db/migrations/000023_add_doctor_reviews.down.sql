@@ -0,0 +1,5 @@
+ALTER TABLE doctors
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating_average;
+
+DROP TABLE IF EXISTS doctor_reviews;
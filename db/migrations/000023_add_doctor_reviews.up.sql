@@ -0,0 +1,30 @@
+-- A patient's rating of a completed booking. Flagged reviews stay visible
+-- until an admin hides them.
+CREATE TABLE doctor_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL UNIQUE REFERENCES bookings(id),
+    doctor_id UUID NOT NULL REFERENCES doctors(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    review TEXT,
+    doctor_reply TEXT,
+    replied_at TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'flagged', 'hidden')),
+    flag_reason TEXT,
+    flagged_by UUID,
+    flagged_at TIMESTAMPTZ,
+    moderation_notes TEXT,
+    moderated_by UUID REFERENCES admins(id),
+    moderated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_doctor_reviews_doctor_id ON doctor_reviews (doctor_id, created_at DESC);
+CREATE INDEX idx_doctor_reviews_flagged ON doctor_reviews (flagged_at) WHERE status = 'flagged';
+
+-- The visible reviews of a doctor, kept up to date with them for the
+-- directory.
+ALTER TABLE doctors
+    ADD COLUMN rating_average NUMERIC(3, 2),
+    ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS bookings_within_capacity ON bookings;
+DROP FUNCTION IF EXISTS bookings_within_capacity();
+DROP INDEX IF EXISTS idx_bookings_active_patient;
@@ -0,0 +1,66 @@
+-- Patients who booked the same appointment more than once keep their
+-- earliest booking.
+UPDATE bookings
+SET status = 'cancelled_by_patient', updated_at = NOW()
+WHERE status IN ('pending', 'confirmed', 'completed', 'no_show')
+  AND EXISTS (
+      SELECT 1
+      FROM bookings earlier
+      WHERE earlier.availability_id = bookings.availability_id
+        AND earlier.booking_start_time = bookings.booking_start_time
+        AND earlier.user_id = bookings.user_id
+        AND earlier.status IN ('pending', 'confirmed', 'completed', 'no_show')
+        AND (earlier.created_at, earlier.id) < (bookings.created_at, bookings.id)
+  );
+
+-- One place per patient in each appointment.
+CREATE UNIQUE INDEX idx_bookings_active_patient ON bookings (availability_id, booking_start_time, user_id)
+    WHERE status IN ('pending', 'confirmed', 'completed', 'no_show');
+
+-- An appointment holds at most capacity active bookings. The check locks
+-- the availability row, the same lock booking takes, so concurrent checks
+-- of one window run one after the other. Booking still locks the row first
+-- itself: it has to see the bookings that are already there to pick a free
+-- appointment, number the queue and keep is_booked up to date, and it
+-- answers a full appointment before trying to insert. A write that would
+-- overfill an appointment fails like a unique index would.
+CREATE FUNCTION bookings_within_capacity() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    window_capacity INTEGER;
+    taken INTEGER;
+BEGIN
+    IF NEW.availability_id IS NULL
+       OR NEW.status NOT IN ('pending', 'confirmed', 'completed', 'no_show') THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE'
+       AND OLD.status IN ('pending', 'confirmed', 'completed', 'no_show')
+       AND OLD.availability_id = NEW.availability_id
+       AND OLD.booking_start_time = NEW.booking_start_time THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT capacity INTO window_capacity
+    FROM doctor_availability
+    WHERE id = NEW.availability_id
+    FOR UPDATE;
+
+    SELECT COUNT(*) INTO taken
+    FROM bookings
+    WHERE availability_id = NEW.availability_id
+      AND booking_start_time = NEW.booking_start_time
+      AND status IN ('pending', 'confirmed', 'completed', 'no_show')
+      AND id <> NEW.id;
+
+    IF taken >= window_capacity THEN
+        RAISE EXCEPTION 'appointment at % of availability % is fully booked', NEW.booking_start_time, NEW.availability_id
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'bookings_within_capacity';
+    END IF;
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER bookings_within_capacity
+    BEFORE INSERT OR UPDATE OF status, availability_id, booking_start_time ON bookings
+    FOR EACH ROW EXECUTE FUNCTION bookings_within_capacity();
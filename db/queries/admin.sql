@@ -0,0 +1,126 @@
+-- name: CreateAdmin :one
+INSERT INTO admins (email, password_hash, name)
+VALUES ($1, $2, $3)
+RETURNING id, email, name, created_at;
+
+-- name: GetAdminByEmail :one
+SELECT *
+FROM admins
+WHERE email = $1;
+
+-- name: UpdateDoctorVerificationStatus :execrows
+UPDATE doctors
+SET verification_status = $2, verification_notes = $3, reviewed_at = NOW(), reviewed_by = $4, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ListUsers :many
+SELECT id, email, name, age, gender, blood_group, email_verified_at, suspended_at, created_at
+FROM users
+WHERE sqlc.narg(search)::text IS NULL
+   OR email ILIKE '%' || sqlc.narg(search)::text || '%'
+   OR name ILIKE '%' || sqlc.narg(search)::text || '%'
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountUsers :one
+SELECT COUNT(*)
+FROM users
+WHERE sqlc.narg(search)::text IS NULL
+   OR email ILIKE '%' || sqlc.narg(search)::text || '%'
+   OR name ILIKE '%' || sqlc.narg(search)::text || '%';
+
+-- name: ListAllDoctors :many
+SELECT *
+FROM doctors
+WHERE (sqlc.narg(search)::text IS NULL
+       OR name ILIKE '%' || sqlc.narg(search)::text || '%'
+       OR email ILIKE '%' || sqlc.narg(search)::text || '%'
+       OR specialization ILIKE '%' || sqlc.narg(search)::text || '%')
+  AND (sqlc.narg(verification_status)::text IS NULL OR verification_status = sqlc.narg(verification_status)::text)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountAllDoctors :one
+SELECT COUNT(*)
+FROM doctors
+WHERE (sqlc.narg(search)::text IS NULL
+       OR name ILIKE '%' || sqlc.narg(search)::text || '%'
+       OR email ILIKE '%' || sqlc.narg(search)::text || '%'
+       OR specialization ILIKE '%' || sqlc.narg(search)::text || '%')
+  AND (sqlc.narg(verification_status)::text IS NULL OR verification_status = sqlc.narg(verification_status)::text);
+
+-- name: ListAllBookings :many
+SELECT *
+FROM bookings
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id)::uuid)
+  AND (sqlc.narg(doctor_id)::uuid IS NULL OR doctor_id = sqlc.narg(doctor_id)::uuid)
+  AND (sqlc.narg(from_date)::date IS NULL OR booking_date >= sqlc.narg(from_date)::date)
+  AND (sqlc.narg(to_date)::date IS NULL OR booking_date <= sqlc.narg(to_date)::date)
+ORDER BY booking_date DESC, booking_start_time DESC, id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountAllBookings :one
+SELECT COUNT(*)
+FROM bookings
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id)::uuid)
+  AND (sqlc.narg(doctor_id)::uuid IS NULL OR doctor_id = sqlc.narg(doctor_id)::uuid)
+  AND (sqlc.narg(from_date)::date IS NULL OR booking_date >= sqlc.narg(from_date)::date)
+  AND (sqlc.narg(to_date)::date IS NULL OR booking_date <= sqlc.narg(to_date)::date);
+
+-- name: ListAllMedications :many
+SELECT *
+FROM medications
+WHERE (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id)::uuid)
+  AND (sqlc.narg(search)::text IS NULL OR medication_name ILIKE '%' || sqlc.narg(search)::text || '%')
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountAllMedications :one
+SELECT COUNT(*)
+FROM medications
+WHERE (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id)::uuid)
+  AND (sqlc.narg(search)::text IS NULL OR medication_name ILIKE '%' || sqlc.narg(search)::text || '%');
+
+-- name: ListAllAvailability :many
+SELECT *
+FROM doctor_availability
+WHERE (sqlc.narg(doctor_id)::uuid IS NULL OR doctor_id = sqlc.narg(doctor_id)::uuid)
+  AND (sqlc.narg(is_booked)::boolean IS NULL OR is_booked = sqlc.narg(is_booked)::boolean)
+  AND (sqlc.narg(from_date)::date IS NULL OR availability_date >= sqlc.narg(from_date)::date)
+  AND (sqlc.narg(to_date)::date IS NULL OR availability_date <= sqlc.narg(to_date)::date)
+ORDER BY availability_date DESC, start_time, id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountAllAvailability :one
+SELECT COUNT(*)
+FROM doctor_availability
+WHERE (sqlc.narg(doctor_id)::uuid IS NULL OR doctor_id = sqlc.narg(doctor_id)::uuid)
+  AND (sqlc.narg(is_booked)::boolean IS NULL OR is_booked = sqlc.narg(is_booked)::boolean)
+  AND (sqlc.narg(from_date)::date IS NULL OR availability_date >= sqlc.narg(from_date)::date)
+  AND (sqlc.narg(to_date)::date IS NULL OR availability_date <= sqlc.narg(to_date)::date);
+
+-- name: DeleteAvailabilityByID :execrows
+DELETE FROM doctor_availability
+WHERE id = $1;
+
+-- name: DeleteStaleAvailability :execrows
+DELETE FROM doctor_availability
+WHERE availability_date < CURRENT_DATE
+  AND is_booked IS NOT TRUE
+  AND NOT EXISTS (
+      SELECT 1
+      FROM bookings
+      WHERE bookings.availability_id = doctor_availability.id
+  );
+
+-- name: SetUserSuspended :execrows
+UPDATE users
+SET suspended_at = sqlc.narg(suspended_at), updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: SetDoctorSuspended :execrows
+UPDATE doctors
+SET suspended_at = sqlc.narg(suspended_at), updated_at = NOW()
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,145 @@
+-- name: CreateAvailabilityRule :one
+INSERT INTO availability_rules (doctor_id, weekday, start_time, end_time, slot_minutes, buffer_minutes, valid_from, valid_until)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetAvailabilityRule :one
+SELECT *
+FROM availability_rules
+WHERE id = $1 AND doctor_id = $2;
+
+-- name: ListAvailabilityRulesByDoctor :many
+SELECT *
+FROM availability_rules
+WHERE doctor_id = $1
+ORDER BY weekday, start_time, id;
+
+-- name: ListAvailabilityRulesToMaterialize :many
+SELECT *
+FROM availability_rules
+WHERE (materialized_until IS NULL OR materialized_until < sqlc.arg(horizon)::date)
+  AND (valid_until IS NULL OR materialized_until IS NULL OR materialized_until < valid_until)
+  AND NOT EXISTS (
+      SELECT 1
+      FROM doctors
+      WHERE doctors.id = availability_rules.doctor_id
+        AND doctors.deactivated_at IS NOT NULL
+  )
+ORDER BY doctor_id, id;
+
+-- name: SetAvailabilityRuleMaterializedUntil :exec
+UPDATE availability_rules
+SET materialized_until = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ResetAvailabilityRulesMaterialization :exec
+-- Has the job lay out the doctor's rules again from today, after slots
+-- were removed on deactivation.
+UPDATE availability_rules
+SET materialized_until = NULL, updated_at = NOW()
+WHERE doctor_id = $1;
+
+-- name: DeleteAvailabilityRule :execrows
+DELETE FROM availability_rules
+WHERE id = $1 AND doctor_id = $2;
+
+-- name: CreateRuleAvailability :execrows
+INSERT INTO doctor_availability (doctor_id, availability_date, start_time, end_time, rule_id, starts_at, ends_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFreeRuleAvailability :execrows
+DELETE FROM doctor_availability
+WHERE rule_id = $1
+  AND availability_date >= CURRENT_DATE
+  AND is_booked IS NOT TRUE
+  AND NOT EXISTS (
+      SELECT 1
+      FROM bookings
+      WHERE bookings.availability_id = doctor_availability.id
+  );
+
+-- name: CreateAvailabilityException :one
+INSERT INTO availability_exceptions (doctor_id, exception_date, reason)
+VALUES ($1, $2, $3)
+ON CONFLICT (doctor_id, exception_date) DO UPDATE SET reason = EXCLUDED.reason
+RETURNING *;
+
+-- name: ListAvailabilityExceptionsByDoctor :many
+SELECT *
+FROM availability_exceptions
+WHERE doctor_id = $1 AND exception_date >= sqlc.arg(from_date)::date
+ORDER BY exception_date;
+
+-- name: DeleteAvailabilityException :one
+DELETE FROM availability_exceptions
+WHERE id = $1 AND doctor_id = $2
+RETURNING *;
+
+-- name: DeleteFreeAvailabilityOnDate :execrows
+DELETE FROM doctor_availability
+WHERE doctor_id = $1
+  AND availability_date = $2
+  AND is_booked IS NOT TRUE
+  AND NOT EXISTS (
+      SELECT 1
+      FROM bookings
+      WHERE bookings.availability_id = doctor_availability.id
+  );
+
+-- name: CountBookedAvailabilityOnDate :one
+SELECT COUNT(*)
+FROM doctor_availability
+WHERE doctor_id = $1
+  AND availability_date = $2
+  AND EXISTS (
+      SELECT 1
+      FROM bookings
+      WHERE bookings.availability_id = doctor_availability.id
+        AND bookings.status IN ('pending', 'confirmed', 'completed', 'no_show')
+  );
+
+-- name: ListOverlappingAvailability :many
+SELECT id
+FROM doctor_availability
+WHERE doctor_id = sqlc.arg(doctor_id)
+  AND availability_date = sqlc.arg(availability_date)
+  AND start_time < sqlc.arg(end_time)
+  AND sqlc.arg(start_time) < end_time
+  AND id IS DISTINCT FROM sqlc.narg(exclude_id)::uuid
+ORDER BY start_time;
+
+-- name: GetActiveBookingByAvailability :one
+SELECT *
+FROM bookings
+WHERE availability_id = $1
+  AND status IN ('pending', 'confirmed', 'completed', 'no_show')
+LIMIT 1;
+
+-- name: ListNextFreeAppointmentsByDoctor :many
+SELECT *
+FROM upcoming_free_appointments
+WHERE doctor_id = sqlc.arg(doctor_id)
+ORDER BY starts_at, availability_id
+LIMIT sqlc.arg(max_results);
+
+-- name: ListNextFreeAppointmentsBySpecialization :many
+SELECT fa.*, d.name AS doctor_name, d.hospital_name, d.consultation_fee, d.timezone
+FROM upcoming_free_appointments fa
+JOIN doctors d ON d.id = fa.doctor_id
+WHERE lower(d.specialization) = lower(sqlc.arg(specialization)::text)
+ORDER BY fa.starts_at, fa.availability_id
+LIMIT sqlc.arg(max_results);
+
+-- name: GetFreeAppointmentCalendar :many
+-- One row per day from from_date to to_date, with the doctor's free
+-- appointments and the places left in them that day.
+SELECT day::date AS date,
+       COUNT(fa.availability_id) AS free_slots,
+       COALESCE(SUM(fa.remaining), 0)::int AS free_places
+FROM generate_series(sqlc.arg(from_date)::date, sqlc.arg(to_date)::date, interval '1 day') AS day
+LEFT JOIN upcoming_free_appointments fa
+       ON fa.availability_date = day::date
+      AND fa.doctor_id = sqlc.arg(doctor_id)
+GROUP BY day
+ORDER BY day;
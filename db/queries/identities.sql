@@ -0,0 +1,29 @@
+-- name: GetUserIdentity :one
+SELECT *
+FROM user_identities
+WHERE provider = $1 AND subject = $2;
+
+-- name: GetUserIdentityByAccount :one
+SELECT *
+FROM user_identities
+WHERE account_id = $1 AND provider = $2;
+
+-- name: CreateUserIdentity :execrows
+INSERT INTO user_identities (provider, subject, account_id, role, email)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (provider, subject) DO NOTHING;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET last_login_at = NOW()
+WHERE id = $1;
+
+-- name: ListUserIdentitiesByAccount :many
+SELECT *
+FROM user_identities
+WHERE account_id = $1
+ORDER BY created_at;
+
+-- name: DeleteUserIdentity :execrows
+DELETE FROM user_identities
+WHERE id = $1 AND account_id = $2;
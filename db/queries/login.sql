@@ -0,0 +1,40 @@
+-- name: GetLoginAttempt :one
+SELECT *
+FROM login_attempts
+WHERE attempt_key = $1;
+
+-- name: IncrementLoginAttempt :one
+INSERT INTO login_attempts (attempt_key, failures, last_failure_at)
+VALUES (sqlc.arg(attempt_key), 1, NOW())
+ON CONFLICT (attempt_key) DO UPDATE SET
+    failures = CASE
+        WHEN login_attempts.last_failure_at < NOW() - make_interval(secs => sqlc.arg(window_seconds)::int) THEN 1
+        ELSE login_attempts.failures + 1
+    END,
+    last_failure_at = NOW()
+RETURNING *;
+
+-- name: BlockLoginAttempt :exec
+UPDATE login_attempts
+SET blocked_until = $2
+WHERE attempt_key = $1;
+
+-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempts
+WHERE attempt_key = $1;
+
+-- name: CreateLoginAudit :exec
+INSERT INTO login_audit (email, role, ip_address, user_agent, reason)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListLoginAudit :many
+SELECT *
+FROM login_audit
+WHERE sqlc.narg(email)::text IS NULL OR email = sqlc.narg(email)::text
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountLoginAudit :one
+SELECT COUNT(*)
+FROM login_audit
+WHERE sqlc.narg(email)::text IS NULL OR email = sqlc.narg(email)::text;
@@ -0,0 +1,71 @@
+-- name: GetDoctorMfa :one
+SELECT id, email, mfa_secret, mfa_enabled_at, mfa_required, mfa_last_used_step
+FROM doctors
+WHERE id = $1;
+
+-- name: SetDoctorMfaSecret :exec
+UPDATE doctors
+SET mfa_secret = $2, mfa_enabled_at = NULL, mfa_last_used_step = NULL
+WHERE id = $1;
+
+-- name: EnableDoctorMfa :exec
+UPDATE doctors
+SET mfa_enabled_at = NOW(), mfa_last_used_step = $2
+WHERE id = $1;
+
+-- name: DisableDoctorMfa :exec
+UPDATE doctors
+SET mfa_secret = NULL, mfa_enabled_at = NULL, mfa_last_used_step = NULL
+WHERE id = $1;
+
+-- name: UseDoctorMfaStep :execrows
+UPDATE doctors
+SET mfa_last_used_step = sqlc.arg(step)
+WHERE id = sqlc.arg(id) AND (mfa_last_used_step IS NULL OR mfa_last_used_step < sqlc.arg(step));
+
+-- name: SetDoctorMfaRequired :execrows
+UPDATE doctors
+SET mfa_required = $2
+WHERE id = $1;
+
+-- name: CreateDoctorMfaRecoveryCode :exec
+INSERT INTO doctor_mfa_recovery_codes (doctor_id, code_hash)
+VALUES ($1, $2);
+
+-- name: DeleteDoctorMfaRecoveryCodes :exec
+DELETE FROM doctor_mfa_recovery_codes
+WHERE doctor_id = $1;
+
+-- name: UseDoctorMfaRecoveryCode :execrows
+UPDATE doctor_mfa_recovery_codes
+SET used_at = NOW()
+WHERE doctor_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedDoctorMfaRecoveryCodes :one
+SELECT COUNT(*)
+FROM doctor_mfa_recovery_codes
+WHERE doctor_id = $1 AND used_at IS NULL;
+
+-- name: CreateMfaChallenge :exec
+INSERT INTO mfa_challenges (doctor_id, token_hash, expires_at)
+VALUES ($1, $2, $3);
+
+-- name: GetMfaChallengeByHash :one
+SELECT *
+FROM mfa_challenges
+WHERE token_hash = $1;
+
+-- name: IncrementMfaChallengeAttempts :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING attempts;
+
+-- name: MarkMfaChallengeUsed :execrows
+UPDATE mfa_challenges
+SET used_at = NOW()
+WHERE id = $1 AND used_at IS NULL;
+
+-- name: DeleteExpiredMfaChallenges :exec
+DELETE FROM mfa_challenges
+WHERE expires_at < NOW();
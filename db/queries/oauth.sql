@@ -0,0 +1,12 @@
+-- name: CreateOAuthState :exec
+INSERT INTO oauth_states (provider, role, state_hash, code_verifier, nonce, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ConsumeOAuthState :one
+DELETE FROM oauth_states
+WHERE state_hash = $1
+RETURNING *;
+
+-- name: DeleteExpiredOAuthStates :exec
+DELETE FROM oauth_states
+WHERE expires_at < NOW();
@@ -0,0 +1,79 @@
+-- name: CreateDoctorReview :one
+INSERT INTO doctor_reviews (booking_id, doctor_id, user_id, rating, review)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetDoctorReview :one
+SELECT *
+FROM doctor_reviews
+WHERE id = $1;
+
+-- name: ListVisibleDoctorReviews :many
+SELECT r.*, u.name AS patient_name
+FROM doctor_reviews r
+JOIN users u ON u.id = r.user_id
+WHERE r.doctor_id = sqlc.arg(doctor_id)
+  AND r.status <> 'hidden'
+ORDER BY r.created_at DESC, r.id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountVisibleDoctorReviews :one
+SELECT COUNT(*)
+FROM doctor_reviews
+WHERE doctor_id = $1
+  AND status <> 'hidden';
+
+-- name: SetDoctorReviewReply :one
+UPDATE doctor_reviews
+SET doctor_reply = sqlc.narg(doctor_reply),
+    replied_at = CASE WHEN sqlc.narg(doctor_reply)::text IS NULL THEN NULL ELSE NOW() END,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND doctor_id = sqlc.arg(doctor_id)
+RETURNING *;
+
+-- name: FlagDoctorReview :execrows
+-- Only a published review that no admin has looked at yet can be flagged.
+UPDATE doctor_reviews
+SET status = 'flagged',
+    flag_reason = sqlc.arg(flag_reason),
+    flagged_by = sqlc.arg(flagged_by),
+    flagged_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND status = 'published'
+  AND moderated_at IS NULL;
+
+-- name: ListFlaggedDoctorReviews :many
+SELECT *
+FROM doctor_reviews
+WHERE status = 'flagged'
+ORDER BY flagged_at, id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountFlaggedDoctorReviews :one
+SELECT COUNT(*)
+FROM doctor_reviews
+WHERE status = 'flagged';
+
+-- name: ModerateDoctorReview :one
+UPDATE doctor_reviews
+SET status = sqlc.arg(status),
+    moderation_notes = sqlc.narg(moderation_notes),
+    moderated_by = sqlc.arg(moderated_by),
+    moderated_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: RefreshDoctorRating :exec
+-- Recomputes the directory rating from the doctor's visible reviews.
+UPDATE doctors
+SET rating_average = stats.average,
+    rating_count = stats.count
+FROM (
+    SELECT ROUND(AVG(rating), 2) AS average, COUNT(*) AS count
+    FROM doctor_reviews
+    WHERE doctor_id = sqlc.arg(doctor_id)
+      AND status <> 'hidden'
+) stats
+WHERE doctors.id = sqlc.arg(doctor_id);
@@ -0,0 +1,59 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (id, subject_id, role, device_name, platform, ip_address, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetSession :one
+SELECT *
+FROM sessions
+WHERE id = $1;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_seen_at = NOW(), ip_address = $2
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: ListActiveSessionsBySubject :many
+SELECT *
+FROM sessions
+WHERE subject_id = $1
+  AND revoked_at IS NULL
+  AND last_seen_at > NOW() - INTERVAL '30 days'
+ORDER BY last_seen_at DESC;
+
+-- name: UpdateSessionDevice :execrows
+UPDATE sessions
+SET device_name = COALESCE(sqlc.narg(device_name), device_name),
+    platform = COALESCE(sqlc.narg(platform), platform),
+    fcm_token = COALESCE(sqlc.narg(fcm_token), fcm_token),
+    last_seen_at = NOW()
+WHERE id = sqlc.arg(id) AND revoked_at IS NULL;
+
+-- name: ReleaseFCMToken :exec
+UPDATE sessions
+SET fcm_token = NULL
+WHERE fcm_token = sqlc.arg(fcm_token) AND id <> sqlc.arg(keep_session_id);
+
+-- name: ClearFCMToken :exec
+UPDATE sessions
+SET fcm_token = NULL
+WHERE fcm_token = $1;
+
+-- name: RevokeSession :execrows
+UPDATE sessions
+SET revoked_at = NOW(), fcm_token = NULL
+WHERE id = $1 AND subject_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeSessionsBySubject :exec
+UPDATE sessions
+SET revoked_at = NOW(), fcm_token = NULL
+WHERE subject_id = $1 AND revoked_at IS NULL;
+
+-- name: GetActiveFCMTokensByUser :many
+SELECT fcm_token
+FROM sessions
+WHERE subject_id = $1
+  AND role = 'user'
+  AND revoked_at IS NULL
+  AND fcm_token IS NOT NULL
+  AND last_seen_at > NOW() - INTERVAL '30 days'
+ORDER BY last_seen_at DESC;
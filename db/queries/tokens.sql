@@ -0,0 +1,96 @@
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (family_id, subject_id, role, email, token_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetRefreshTokenByHash :one
+SELECT *
+FROM refresh_tokens
+WHERE token_hash = $1;
+
+-- name: RevokeRefreshToken :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeAccessToken :exec
+INSERT INTO revoked_access_tokens (jti, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsAccessTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1
+    FROM revoked_access_tokens
+    WHERE jti = sqlc.arg(jti)
+) OR EXISTS (
+    SELECT 1
+    FROM subject_token_revocations
+    WHERE subject_id = sqlc.arg(subject_id) AND revoked_before > sqlc.arg(issued_at)::timestamptz
+) OR EXISTS (
+    SELECT 1
+    FROM sessions
+    WHERE id = sqlc.narg(session_id) AND revoked_at IS NOT NULL
+) AS revoked;
+
+-- name: DeleteExpiredRevokedAccessTokens :exec
+DELETE FROM revoked_access_tokens
+WHERE expires_at < NOW();
+
+-- name: RevokeSubjectAccessTokens :exec
+-- Tokens carry their issue time in whole seconds, so the cut-off is
+-- truncated too; a token issued in the same second as the revocation is
+-- kept, so that signing in again right away works.
+INSERT INTO subject_token_revocations (subject_id, revoked_before)
+VALUES ($1, date_trunc('second', NOW()))
+ON CONFLICT (subject_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before;
+
+-- name: RevokeRefreshTokensBySubject :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE subject_id = $1 AND revoked_at IS NULL;
+
+-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens (account_id, role, token_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetPasswordResetTokenByHash :one
+SELECT *
+FROM password_reset_tokens
+WHERE token_hash = $1;
+
+-- name: MarkPasswordResetTokenUsed :execrows
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE id = $1 AND used_at IS NULL;
+
+-- name: InvalidatePasswordResetTokens :exec
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE account_id = $1 AND used_at IS NULL;
+
+-- name: CreateEmailVerificationToken :one
+INSERT INTO email_verification_tokens (account_id, role, token_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetEmailVerificationTokenByHash :one
+SELECT *
+FROM email_verification_tokens
+WHERE token_hash = $1;
+
+-- name: MarkEmailVerificationTokenUsed :execrows
+UPDATE email_verification_tokens
+SET used_at = NOW()
+WHERE id = $1 AND used_at IS NULL;
+
+-- name: InvalidateEmailVerificationTokens :exec
+UPDATE email_verification_tokens
+SET used_at = NOW()
+WHERE account_id = $1 AND used_at IS NULL;
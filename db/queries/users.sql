@@ -4,22 +4,17 @@ VALUES ($1, $2, $3)
 RETURNING id, email, name, created_at, updated_at;
 
 
--- name: CreateUserWithGoogle :one
-INSERT INTO users (google_id, email, name)
-VALUES ($1, $2, $3)
-RETURNING id, email, google_id, name;
+-- name: CreateUserWithIdentity :one
+INSERT INTO users (email, name, email_verified_at)
+VALUES ($1, $2, NOW())
+RETURNING id, email, name;
 
 -- name: GetUserByEmail :one
-SELECT id, email, password_hash, google_id, name, created_at, updated_at
+SELECT id, email, password_hash, name, created_at, updated_at, suspended_at
 FROM users
 WHERE email = $1;
 
 
--- name: GetUserByGoogleID :one
-SELECT id, email, google_id, name, created_at, updated_at
-FROM users
-WHERE google_id = $1;
-
 
 -- name: UpdateUserPassword :exec
 UPDATE users
@@ -35,12 +30,13 @@ SET
     blood_group = COALESCE($4, blood_group),
     emergency_contact_number = COALESCE($5, emergency_contact_number),
     emergency_contact_relationship = COALESCE($6, emergency_contact_relationship),
+    timezone = COALESCE(sqlc.narg(timezone), timezone),
     updated_at = NOW()
 WHERE id = $7;
 
 
 -- name: GetUserByID :one
-select id, email, name, age, gender, blood_group, emergency_contact_number, emergency_contact_relationship, updated_at 
+select id, email, name, age, gender, blood_group, emergency_contact_number, emergency_contact_relationship, timezone, updated_at 
 FROM users 
 WHERE id = $1;
 
@@ -49,6 +45,16 @@ SELECT *
 FROM users
 WHERE id = $1;
 
+-- name: GetUserEmailVerifiedAt :one
+SELECT email_verified_at
+FROM users
+WHERE id = $1;
+
+-- name: MarkUserEmailVerified :exec
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+WHERE id = $1;
+
 -- name: CreateDoctor :one
 INSERT INTO doctors (
     name,
@@ -65,8 +71,13 @@ INSERT INTO doctors (
 )
 RETURNING id, name, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, created_at, updated_at;
 
+-- name: UpdateDoctorPassword :exec
+UPDATE doctors
+SET password_hash = $2, updated_at = NOW()
+WHERE email = $1;
+
 -- name: GetDoctorByEmail :one
-SELECT id, name, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, password_hash, created_at, updated_at
+SELECT id, name, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, password_hash, created_at, updated_at, verification_status, suspended_at, mfa_enabled_at, mfa_required
 FROM doctors
 WHERE email = $1;
 
@@ -75,13 +86,128 @@ SELECT *
 FROM doctors
 WHERE id = $1;
 
--- name: ListDoctors :many
+-- name: GetDoctorEmailVerifiedAt :one
+SELECT email_verified_at
+FROM doctors
+WHERE id = $1;
+
+-- name: MarkDoctorEmailVerified :exec
+UPDATE doctors
+SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+WHERE id = $1;
+
+-- name: SearchDoctors :many
+-- Verified doctors matching the filters, ordered by sort_key and id. The
+-- page starts after the (after_key, after_id) cursor when one is given.
+WITH candidates AS (
+  SELECT d.id, d.name, d.specialization, d.experience, d.qualification, d.hospital_name,
+         d.consultation_fee, d.timezone, d.rating_average, d.rating_count,
+         next_free.starts_at AS next_available_at,
+         CASE sqlc.arg(sort_by)::text
+           WHEN 'fee' THEN d.consultation_fee::float8
+           WHEN '-fee' THEN -d.consultation_fee::float8
+           WHEN 'experience' THEN d.experience::float8
+           WHEN '-experience' THEN -d.experience::float8
+           WHEN '-rating' THEN -coalesce(d.rating_average, 0)::float8
+           WHEN 'relevance' THEN -ts_rank(doctor_search_document(d.name, d.specialization, d.hospital_name),
+                                          websearch_to_tsquery('simple', coalesce(sqlc.narg(query)::text, ''))
+                                          || websearch_to_tsquery('english', coalesce(sqlc.narg(query)::text, '')))::float8
+           ELSE coalesce(extract(epoch FROM next_free.starts_at)::float8, 'Infinity'::float8)
+         END AS sort_key
+  FROM doctors d
+  LEFT JOIN LATERAL (
+    SELECT min(a.starts_at) AS starts_at
+    FROM doctor_availability a
+    WHERE a.doctor_id = d.id
+      AND a.is_booked IS NOT TRUE
+      AND a.starts_at > NOW()
+  ) next_free ON TRUE
+  WHERE d.verification_status = 'verified'
+    AND d.suspended_at IS NULL
+    AND d.deactivated_at IS NULL
+    AND (sqlc.narg(query)::text IS NULL
+         OR doctor_search_document(d.name, d.specialization, d.hospital_name) @@ websearch_to_tsquery('simple', sqlc.narg(query)::text)
+         OR doctor_search_document(d.name, d.specialization, d.hospital_name) @@ websearch_to_tsquery('english', sqlc.narg(query)::text))
+    AND (sqlc.narg(name)::text IS NULL OR d.name ILIKE '%' || sqlc.narg(name)::text || '%' ESCAPE '\')
+    AND (sqlc.narg(specialization)::text IS NULL OR lower(d.specialization) = lower(sqlc.narg(specialization)::text))
+    AND (sqlc.narg(hospital_name)::text IS NULL OR lower(d.hospital_name) = lower(sqlc.narg(hospital_name)::text))
+    AND (sqlc.narg(min_experience)::int IS NULL OR d.experience >= sqlc.narg(min_experience)::int)
+    AND (sqlc.narg(min_fee)::float8 IS NULL OR d.consultation_fee >= sqlc.narg(min_fee)::float8::numeric)
+    AND (sqlc.narg(max_fee)::float8 IS NULL OR d.consultation_fee <= sqlc.narg(max_fee)::float8::numeric)
+    AND (sqlc.narg(available_on)::date IS NULL OR EXISTS (
+        SELECT 1
+        FROM doctor_availability a
+        WHERE a.doctor_id = d.id
+          AND a.availability_date = sqlc.narg(available_on)::date
+          AND a.is_booked IS NOT TRUE
+          AND a.ends_at > NOW()
+    ))
+)
+SELECT id::uuid AS id, name::text AS name, specialization::text AS specialization, experience::int AS experience,
+       qualification::text AS qualification, hospital_name::text AS hospital_name,
+       consultation_fee::numeric AS consultation_fee, timezone::text AS timezone,
+       rating_average::numeric AS rating_average, rating_count::int AS rating_count,
+       next_available_at::timestamptz AS next_available_at, sort_key::float8 AS sort_key
+FROM candidates
+WHERE sqlc.narg(after_id)::uuid IS NULL
+   OR (sort_key, id) > (sqlc.narg(after_key)::float8, sqlc.narg(after_id)::uuid)
+ORDER BY sort_key, id
+LIMIT sqlc.arg(page_size);
+
+-- name: CountSearchDoctors :one
+SELECT COUNT(*)
+FROM doctors d
+WHERE d.verification_status = 'verified'
+  AND d.suspended_at IS NULL
+  AND d.deactivated_at IS NULL
+  AND (sqlc.narg(query)::text IS NULL
+       OR doctor_search_document(d.name, d.specialization, d.hospital_name) @@ websearch_to_tsquery('simple', sqlc.narg(query)::text)
+       OR doctor_search_document(d.name, d.specialization, d.hospital_name) @@ websearch_to_tsquery('english', sqlc.narg(query)::text))
+  AND (sqlc.narg(name)::text IS NULL OR d.name ILIKE '%' || sqlc.narg(name)::text || '%' ESCAPE '\')
+  AND (sqlc.narg(specialization)::text IS NULL OR lower(d.specialization) = lower(sqlc.narg(specialization)::text))
+  AND (sqlc.narg(hospital_name)::text IS NULL OR lower(d.hospital_name) = lower(sqlc.narg(hospital_name)::text))
+  AND (sqlc.narg(min_experience)::int IS NULL OR d.experience >= sqlc.narg(min_experience)::int)
+  AND (sqlc.narg(min_fee)::float8 IS NULL OR d.consultation_fee >= sqlc.narg(min_fee)::float8::numeric)
+  AND (sqlc.narg(max_fee)::float8 IS NULL OR d.consultation_fee <= sqlc.narg(max_fee)::float8::numeric)
+  AND (sqlc.narg(available_on)::date IS NULL OR EXISTS (
+      SELECT 1
+      FROM doctor_availability a
+      WHERE a.doctor_id = d.id
+        AND a.availability_date = sqlc.narg(available_on)::date
+        AND a.is_booked IS NOT TRUE
+        AND a.ends_at > NOW()
+  ));
+
+-- name: GetDoctorVerificationStatus :one
+SELECT verification_status
+FROM doctors
+WHERE id = $1;
+
+-- name: CreateDoctorLicenseDocument :one
+INSERT INTO doctor_license_documents (doctor_id, file_name, file_data)
+VALUES ($1, $2, $3)
+RETURNING id, file_name, created_at;
+
+-- name: ResubmitRejectedDoctor :execrows
+-- Puts a rejected doctor back in the review queue.
+UPDATE doctors
+SET verification_status = 'pending_verification', updated_at = NOW()
+WHERE id = $1 AND verification_status = 'rejected';
+
+-- name: ListDoctorLicenseDocuments :many
+SELECT id, file_name, created_at
+FROM doctor_license_documents
+WHERE doctor_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetDoctorLicenseDocument :one
 SELECT *
-FROM doctors;
+FROM doctor_license_documents
+WHERE id = $1 AND doctor_id = $2;
 
 -- name: CreateDoctorAvailability :one
-INSERT INTO doctor_availability (doctor_id, availability_date, start_time, end_time)
-VALUES ($1, $2, $3, $4)
+INSERT INTO doctor_availability (doctor_id, availability_date, start_time, end_time, slot_minutes, capacity, booking_mode, starts_at, ends_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 
 -- name: GetDoctorAvailabilityByID :one
@@ -105,6 +231,8 @@ SET
     start_time = COALESCE($1, start_time),
     end_time = COALESCE($2, end_time),
     is_booked = COALESCE($3, is_booked),
+    starts_at = COALESCE(sqlc.narg(starts_at), starts_at),
+    ends_at = COALESCE(sqlc.narg(ends_at), ends_at),
     updated_at = NOW()
 WHERE id = $4 AND doctor_id = $5;
 
@@ -119,11 +247,35 @@ SET
     updated_at = NOW()
 WHERE id = $2;
 
+-- name: LockAvailability :one
+SELECT *
+FROM doctor_availability
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListActiveBookingStarts :many
+SELECT booking_start_time
+FROM bookings
+WHERE availability_id = $1
+  AND status IN ('pending', 'confirmed', 'completed', 'no_show');
+
+-- name: ListActiveBookingStartsByDoctorAndDate :many
+SELECT availability_id, booking_start_time
+FROM bookings
+WHERE doctor_id = $1
+  AND booking_date = $2
+  AND status IN ('pending', 'confirmed', 'completed', 'no_show');
+
+-- name: NextQueuePosition :one
+SELECT (COALESCE(MAX(queue_position), 0) + 1)::integer AS next_position
+FROM bookings
+WHERE availability_id = $1;
+
 
 
 -- name: CreateBooking :one
-INSERT INTO bookings (user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO bookings (user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, rescheduled_from, queue_position, starts_at, ends_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, sqlc.narg(rescheduled_from), sqlc.narg(queue_position), sqlc.arg(starts_at), sqlc.arg(ends_at))
 RETURNING *;
 
 -- name: GetBookingByID :one
@@ -146,9 +298,18 @@ SELECT *
 FROM bookings
 WHERE availability_id = $1;
 
--- name: DeleteBooking :exec
-DELETE FROM bookings
-WHERE id = $1;
+-- name: DoctorHasBookingWithUser :one
+SELECT EXISTS (
+    SELECT 1
+    FROM bookings
+    WHERE doctor_id = $1 AND user_id = $2 AND status IN ('pending', 'confirmed', 'completed', 'no_show')
+);
+
+-- name: GetBookingByIDForUpdate :one
+SELECT *
+FROM bookings
+WHERE id = $1
+FOR UPDATE;
 
 -- name: UpdateBookingStatus :exec
 UPDATE bookings
@@ -157,27 +318,63 @@ SET
     updated_at = NOW()
 WHERE id = $2;
 
+-- name: MarkBookingCancelled :exec
+UPDATE bookings
+SET
+    cancelled_at = NOW(),
+    cancellation_reason = $2,
+    late_cancellation_fee = $3
+WHERE id = $1;
+
+-- name: GetDoctorCancellationPolicy :one
+SELECT cancellation_notice_minutes, late_cancellation_fee
+FROM doctors
+WHERE id = $1;
+
+-- name: UpdateDoctorCancellationPolicy :exec
+UPDATE doctors
+SET
+    cancellation_notice_minutes = $2,
+    late_cancellation_fee = $3,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: CreateBookingStatusHistory :exec
+INSERT INTO booking_status_history (booking_id, from_status, to_status, changed_by, changed_by_role, reason)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListBookingStatusHistory :many
+SELECT *
+FROM booking_status_history
+WHERE booking_id = $1
+ORDER BY created_at, id;
+
 -- name: CreateMedication :one
-INSERT INTO medications (user_id, medication_name, dosage, time_to_notify, frequency)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO medications (user_id, medication_name, dosage, time_to_notify, frequency, next_notify_at)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
--- name: GetMedicationsToNotify :many
+-- name: GetMedicationByID :one
 SELECT *
 FROM medications
-WHERE time_to_notify = $1
-  AND (frequency = 'daily' OR (frequency = 'weekly' AND EXTRACT(DOW FROM NOW()) = EXTRACT(DOW FROM updated_at)));
+WHERE id = $1;
+
+-- name: GetMedicationsToNotify :many
+SELECT m.*, u.timezone
+FROM medications m
+JOIN users u ON u.id = m.user_id
+WHERE m.next_notify_at <= $1;
+
+-- name: SetMedicationNextNotifyAt :exec
+UPDATE medications
+SET next_notify_at = $1
+WHERE id = $2;
 
 -- name: UpdateMedicationReadStatus :exec
 UPDATE medications
 SET is_readbyuser = TRUE
 WHERE id = $1;
 
--- name: GetUserFCMToken :one
-SELECT fcm_token
-FROM users
-WHERE id = $1;
-
 -- name: GetMedicationsByUserID :many
 SELECT
     id,
@@ -188,7 +385,8 @@ SELECT
     frequency,
     is_readbyuser,
     created_at,
-    updated_at
+    updated_at,
+    next_notify_at
 FROM
     medications
 WHERE
@@ -206,4 +404,112 @@ SELECT file_name, file_data FROM encrypted_files WHERE user_id = $1 AND id = $2;
 SELECT *
 FROM encrypted_files 
 WHERE user_id = $1 
-ORDER BY created_at DESC;
\ No newline at end of file
+ORDER BY created_at DESC;
+-- name: GetUserTimezone :one
+SELECT timezone
+FROM users
+WHERE id = $1;
+
+-- name: UpdateUserTimezone :exec
+UPDATE users
+SET timezone = $1, updated_at = NOW()
+WHERE id = $2;
+
+-- name: GetDoctorTimezone :one
+SELECT timezone
+FROM doctors
+WHERE id = $1;
+
+-- name: UpdateDoctorTimezone :exec
+UPDATE doctors
+SET timezone = $1, updated_at = NOW()
+WHERE id = $2;
+
+-- name: CountUpcomingActiveBookingsByDoctor :one
+SELECT COUNT(*)
+FROM bookings
+WHERE doctor_id = $1
+  AND ends_at > NOW()
+  AND status IN ('pending', 'confirmed', 'completed', 'no_show');
+
+-- name: ListUpcomingAvailabilityByDoctor :many
+SELECT *
+FROM doctor_availability
+WHERE doctor_id = $1
+  AND ends_at > NOW();
+
+-- name: SetAvailabilityInstants :exec
+UPDATE doctor_availability
+SET starts_at = $1, ends_at = $2, updated_at = NOW()
+WHERE id = $3;
+
+-- name: UpdateDoctorProfile :one
+-- Changing a credential an admin verified sends the doctor back to review.
+UPDATE doctors
+SET name = COALESCE(sqlc.narg(name), name),
+    specialization = COALESCE(sqlc.narg(specialization), specialization),
+    experience = COALESCE(sqlc.narg(experience), experience),
+    qualification = COALESCE(sqlc.narg(qualification), qualification),
+    hospital_name = COALESCE(sqlc.narg(hospital_name), hospital_name),
+    consultation_fee = COALESCE(sqlc.narg(consultation_fee), consultation_fee),
+    contact_number = COALESCE(sqlc.narg(contact_number), contact_number),
+    bio = COALESCE(sqlc.narg(bio), bio),
+    languages = COALESCE(sqlc.narg(languages), languages),
+    clinic_address = COALESCE(sqlc.narg(clinic_address), clinic_address),
+    clinic_latitude = COALESCE(sqlc.narg(clinic_latitude), clinic_latitude),
+    clinic_longitude = COALESCE(sqlc.narg(clinic_longitude), clinic_longitude),
+    verification_status = CASE
+        WHEN (COALESCE(sqlc.narg(name), name),
+              COALESCE(sqlc.narg(specialization), specialization),
+              COALESCE(sqlc.narg(experience), experience),
+              COALESCE(sqlc.narg(qualification), qualification),
+              COALESCE(sqlc.narg(hospital_name), hospital_name))
+             IS DISTINCT FROM (name, specialization, experience, qualification, hospital_name)
+        THEN 'pending_verification'
+        ELSE verification_status
+    END,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DoctorHasProfilePhoto :one
+SELECT EXISTS (
+    SELECT 1
+    FROM doctor_profile_photos
+    WHERE doctor_id = $1
+);
+
+-- name: GetDoctorProfilePhoto :one
+SELECT *
+FROM doctor_profile_photos
+WHERE doctor_id = $1;
+
+-- name: UpsertDoctorProfilePhoto :exec
+INSERT INTO doctor_profile_photos (doctor_id, content_type, data)
+VALUES ($1, $2, $3)
+ON CONFLICT (doctor_id) DO UPDATE
+SET content_type = EXCLUDED.content_type, data = EXCLUDED.data, updated_at = NOW();
+
+-- name: DeleteDoctorProfilePhoto :execrows
+DELETE FROM doctor_profile_photos
+WHERE doctor_id = $1;
+
+-- name: SetDoctorDeactivated :exec
+UPDATE doctors
+SET deactivated_at = sqlc.narg(deactivated_at), updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: GetDoctorBookingState :one
+SELECT verification_status, suspended_at, deactivated_at
+FROM doctors
+WHERE id = $1;
+
+-- name: DeleteFreeUpcomingAvailabilityByDoctor :execrows
+DELETE FROM doctor_availability
+WHERE doctor_id = $1
+  AND ends_at > NOW()
+  AND NOT EXISTS (
+      SELECT 1
+      FROM bookings
+      WHERE bookings.availability_id = doctor_availability.id
+  );
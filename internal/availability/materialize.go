@@ -0,0 +1,193 @@
+package availability
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const defaultHorizonDays = 28
+
+// HorizonDays is how many days ahead rules are turned into slots, read from
+// AVAILABILITY_HORIZON_DAYS.
+func HorizonDays() int {
+	value := os.Getenv("AVAILABILITY_HORIZON_DAYS")
+	if value == "" {
+		return defaultHorizonDays
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		log.Printf("availability: invalid AVAILABILITY_HORIZON_DAYS %q, using %d", value, defaultHorizonDays)
+		return defaultHorizonDays
+	}
+	return days
+}
+
+// Horizon is the last date slots are created for, counted from the date now
+// shows.
+func Horizon(now time.Time) time.Time {
+	return Date(now).AddDate(0, 0, HorizonDays())
+}
+
+// Exceptions returns the doctor's exception dates from the date now shows
+// on, keyed by DateKey.
+func Exceptions(ctx context.Context, queries *repository.Queries, doctorID pgtype.UUID, now time.Time) (map[string]bool, error) {
+	exceptions, err := queries.ListAvailabilityExceptionsByDoctor(ctx, repository.ListAvailabilityExceptionsByDoctorParams{
+		DoctorID: doctorID,
+		FromDate: pgtype.Date{Time: Date(now), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(exceptions))
+	for _, exception := range exceptions {
+		skip[DateKey(exception.ExceptionDate.Time)] = true
+	}
+	return skip, nil
+}
+
+// MaterializeRule creates the rule's slots from the day after it was last
+// materialized up to the horizon. Dates already covered are left alone, so a
+// slot the doctor deleted is not created again. now is on the doctor's
+// clock, loc.
+func MaterializeRule(ctx context.Context, queries *repository.Queries, row repository.AvailabilityRule, skip map[string]bool, loc *time.Location, now time.Time) (int64, error) {
+	now = now.In(loc)
+	from := Date(now)
+	if row.MaterializedUntil.Valid && !row.MaterializedUntil.Time.Before(from) {
+		from = row.MaterializedUntil.Time.AddDate(0, 0, 1)
+	}
+	to := Horizon(now)
+	if row.ValidUntil.Valid && row.ValidUntil.Time.Before(to) {
+		to = row.ValidUntil.Time
+	}
+	if from.After(to) {
+		return 0, nil
+	}
+
+	created, err := createSlots(ctx, queries, row, FromRow(row).Slots(from, to, skip), loc)
+	if err != nil {
+		return created, err
+	}
+
+	err = queries.SetAvailabilityRuleMaterializedUntil(ctx, repository.SetAvailabilityRuleMaterializedUntilParams{
+		ID:                row.ID,
+		MaterializedUntil: pgtype.Date{Time: to, Valid: true},
+	})
+	return created, err
+}
+
+// RefillDate creates the slots of the doctor's rules on one already
+// materialized date, for when an exception on that date is removed.
+func RefillDate(ctx context.Context, queries *repository.Queries, doctorID pgtype.UUID, date time.Time) (int64, error) {
+	rules, err := queries.ListAvailabilityRulesByDoctor(ctx, doctorID)
+	if err != nil {
+		return 0, err
+	}
+	loc, err := timezone.Doctor(ctx, queries, doctorID)
+	if err != nil {
+		return 0, err
+	}
+
+	var created int64
+	for _, row := range rules {
+		if !row.MaterializedUntil.Valid || date.After(row.MaterializedUntil.Time) {
+			continue // filled in by the next run
+		}
+		n, err := createSlots(ctx, queries, row, FromRow(row).Slots(date, date, nil), loc)
+		created += n
+		if err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// createSlots inserts the slots and returns how many were created. Slots the
+// rule already has, or that overlap another slot of the doctor, are skipped.
+func createSlots(ctx context.Context, queries *repository.Queries, row repository.AvailabilityRule, slots []Slot, loc *time.Location) (int64, error) {
+	var created int64
+	for _, slot := range slots {
+		startsAt, endsAt := slot.Instants(loc)
+		n, err := queries.CreateRuleAvailability(ctx, repository.CreateRuleAvailabilityParams{
+			DoctorID:         row.DoctorID,
+			AvailabilityDate: pgtype.Date{Time: slot.Date, Valid: true},
+			StartTime:        PgTime(slot.Start),
+			EndTime:          PgTime(slot.End),
+			RuleID:           row.ID,
+			StartsAt:         timezone.Timestamptz(startsAt),
+			EndsAt:           timezone.Timestamptz(endsAt),
+		})
+		if err != nil {
+			return created, err
+		}
+		created += n
+	}
+	return created, nil
+}
+
+// Materialize fills the horizon for every rule that is behind.
+func Materialize(ctx context.Context, queries *repository.Queries, now time.Time) (int64, error) {
+	rules, err := queries.ListAvailabilityRulesToMaterialize(ctx, pgtype.Date{Time: Horizon(now), Valid: true})
+	if err != nil {
+		return 0, err
+	}
+
+	var created int64
+	exceptions := map[pgtype.UUID]map[string]bool{}
+	locations := map[pgtype.UUID]*time.Location{}
+	for _, row := range rules {
+		loc, ok := locations[row.DoctorID]
+		if !ok {
+			if loc, err = timezone.Doctor(ctx, queries, row.DoctorID); err != nil {
+				return created, err
+			}
+			locations[row.DoctorID] = loc
+		}
+		skip, ok := exceptions[row.DoctorID]
+		if !ok {
+			if skip, err = Exceptions(ctx, queries, row.DoctorID, now.In(loc)); err != nil {
+				return created, err
+			}
+			exceptions[row.DoctorID] = skip
+		}
+
+		n, err := MaterializeRule(ctx, queries, row, skip, loc, now)
+		created += n
+		if err != nil {
+			log.Printf("availability: failed to materialize rule %s: %v", row.ID.String(), err)
+		}
+	}
+	return created, nil
+}
+
+// StartMaterializer keeps the horizon filled, once at start and then every
+// interval, until ctx is cancelled.
+func StartMaterializer(ctx context.Context, queries *repository.Queries, interval time.Duration) {
+	log.Println("Starting availability materializer...")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := Materialize(ctx, queries, time.Now())
+		if err != nil {
+			log.Printf("availability: failed to materialize rules: %v", err)
+		} else if created > 0 {
+			log.Printf("availability: created %d slots", created)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("Availability materializer stopped.")
+			return
+		}
+	}
+}
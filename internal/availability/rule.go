@@ -0,0 +1,117 @@
+// Package availability turns a doctor's recurring availability rules into
+// the bookable doctor_availability slots and keeps them filled over a
+// rolling horizon.
+package availability
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const day = 24 * time.Hour
+
+// Rule is a weekly time window split into slots of equal length with an
+// optional buffer between them.
+type Rule struct {
+	Weekday    time.Weekday
+	Start      time.Duration // since midnight
+	End        time.Duration // since midnight
+	SlotLength time.Duration
+	Buffer     time.Duration
+	ValidFrom  time.Time
+	ValidUntil time.Time // zero when the rule does not end
+}
+
+// Slot is one bookable appointment.
+type Slot struct {
+	Date  time.Time
+	Start time.Duration
+	End   time.Duration
+}
+
+// Instants is when the slot starts and ends on the clock of loc.
+func (s Slot) Instants(loc *time.Location) (time.Time, time.Time) {
+	date := pgtype.Date{Time: s.Date, Valid: true}
+	return timezone.At(date, PgTime(s.Start), loc), timezone.At(date, PgTime(s.End), loc)
+}
+
+// FromRow converts a stored rule.
+func FromRow(row repository.AvailabilityRule) Rule {
+	rule := Rule{
+		Weekday:    time.Weekday(row.Weekday),
+		Start:      time.Duration(row.StartTime.Microseconds) * time.Microsecond,
+		End:        time.Duration(row.EndTime.Microseconds) * time.Microsecond,
+		SlotLength: time.Duration(row.SlotMinutes) * time.Minute,
+		Buffer:     time.Duration(row.BufferMinutes) * time.Minute,
+		ValidFrom:  row.ValidFrom.Time,
+	}
+	if row.ValidUntil.Valid {
+		rule.ValidUntil = row.ValidUntil.Time
+	}
+	return rule
+}
+
+// Validate checks that the rule describes at least one slot.
+func (r Rule) Validate() error {
+	switch {
+	case r.Weekday < time.Sunday || r.Weekday > time.Saturday:
+		return errors.New("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	case r.Start < 0 || r.End > day || r.End <= r.Start:
+		return errors.New("end_time must be after start_time")
+	case r.SlotLength <= 0:
+		return errors.New("slot_minutes must be positive")
+	case r.Buffer < 0:
+		return errors.New("buffer_minutes cannot be negative")
+	case r.Start+r.SlotLength > r.End:
+		return errors.New("the time window is shorter than one slot")
+	case !r.ValidUntil.IsZero() && r.ValidUntil.Before(r.ValidFrom):
+		return errors.New("valid_until must not be before valid_from")
+	}
+	return nil
+}
+
+// Slots lists the rule's slots on the dates from through to. Dates in skip,
+// keyed by DateKey, get no slots.
+func (r Rule) Slots(from, to time.Time, skip map[string]bool) []Slot {
+	from, to = Date(from), Date(to)
+	if from.Before(r.ValidFrom) {
+		from = Date(r.ValidFrom)
+	}
+	if !r.ValidUntil.IsZero() && to.After(r.ValidUntil) {
+		to = Date(r.ValidUntil)
+	}
+
+	// Move to the first matching weekday.
+	offset := (int(r.Weekday) - int(from.Weekday()) + 7) % 7
+	var slots []Slot
+	for date := from.AddDate(0, 0, offset); !date.After(to); date = date.AddDate(0, 0, 7) {
+		if skip[DateKey(date)] {
+			continue
+		}
+		for start := r.Start; start+r.SlotLength <= r.End; start += r.SlotLength + r.Buffer {
+			slots = append(slots, Slot{Date: date, Start: start, End: start + r.SlotLength})
+		}
+	}
+	return slots
+}
+
+// Date is the date t shows in its own location, as midnight UTC, the form
+// dates are stored in. Pass time.Now().In(loc) for today on a doctor's clock.
+func Date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// DateKey formats a date as YYYY-MM-DD.
+func DateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// PgTime converts a duration since midnight to a TIME value.
+func PgTime(d time.Duration) pgtype.Time {
+	return pgtype.Time{Microseconds: d.Microseconds(), Valid: true}
+}
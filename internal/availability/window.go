@@ -0,0 +1,133 @@
+package availability
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Booking modes of an availability window.
+const (
+	ModeSlots = "slots" // patients book one of the window's appointments
+	ModeQueue = "queue" // patients take a place in a walk-in queue
+)
+
+var (
+	// ErrNoSuchSlot is returned for a start time that is not one of the
+	// window's appointments.
+	ErrNoSuchSlot = errors.New("start_time is not an appointment of this availability window")
+	// ErrFull is returned when the appointment or queue has no room left.
+	ErrFull = errors.New("availability slot is fully booked")
+)
+
+// Window is a doctor_availability row as patients book it.
+type Window struct {
+	Date       time.Time
+	Start      time.Duration // since midnight
+	End        time.Duration // since midnight
+	SlotLength time.Duration // zero when the window is one appointment
+	Capacity   int
+	Mode       string
+}
+
+// FreeSlot is an appointment with room left.
+type FreeSlot struct {
+	Slot
+	Remaining int
+}
+
+// WindowFromRow converts a stored availability window.
+func WindowFromRow(row repository.DoctorAvailability) Window {
+	window := Window{
+		Date:     row.AvailabilityDate.Time,
+		Start:    time.Duration(row.StartTime.Microseconds) * time.Microsecond,
+		End:      time.Duration(row.EndTime.Microseconds) * time.Microsecond,
+		Capacity: int(row.Capacity),
+		Mode:     row.BookingMode,
+	}
+	if row.SlotMinutes != nil {
+		window.SlotLength = time.Duration(*row.SlotMinutes) * time.Minute
+	}
+	if window.Capacity < 1 {
+		window.Capacity = 1
+	}
+	return window
+}
+
+// Validate checks the window's booking settings.
+func (w Window) Validate() error {
+	switch {
+	case w.Mode != ModeSlots && w.Mode != ModeQueue:
+		return errors.New("booking_mode must be slots or queue")
+	case w.Capacity < 1:
+		return errors.New("capacity must be positive")
+	case w.Mode == ModeQueue && w.SlotLength != 0:
+		return errors.New("slot_minutes cannot be used with the queue booking mode")
+	case w.SlotLength < 0:
+		return errors.New("slot_minutes must be positive")
+	case w.SlotLength > w.End-w.Start:
+		return errors.New("the time window is shorter than one slot")
+	}
+	return nil
+}
+
+// Slots lists the window's appointments. A queue, or a window without a
+// slot length, is a single appointment spanning the window.
+func (w Window) Slots() []Slot {
+	if w.Mode == ModeQueue || w.SlotLength <= 0 {
+		return []Slot{{Date: w.Date, Start: w.Start, End: w.End}}
+	}
+	var slots []Slot
+	for start := w.Start; start+w.SlotLength <= w.End; start += w.SlotLength {
+		slots = append(slots, Slot{Date: w.Date, Start: start, End: start + w.SlotLength})
+	}
+	return slots
+}
+
+// Free lists the appointments with room left, given the start times of the
+// window's active bookings.
+func (w Window) Free(booked []pgtype.Time) []FreeSlot {
+	taken := make(map[time.Duration]int, len(booked))
+	for _, start := range booked {
+		taken[time.Duration(start.Microseconds)*time.Microsecond]++
+	}
+
+	var free []FreeSlot
+	for _, slot := range w.Slots() {
+		if remaining := w.Capacity - taken[slot.Start]; remaining > 0 {
+			free = append(free, FreeSlot{Slot: slot, Remaining: remaining})
+		}
+	}
+	return free
+}
+
+// Reserve picks the appointment to book: the one starting at start, or the
+// earliest with room when start is nil.
+func (w Window) Reserve(booked []pgtype.Time, start *time.Duration) (Slot, error) {
+	free := w.Free(booked)
+	if start == nil {
+		if len(free) == 0 {
+			return Slot{}, ErrFull
+		}
+		return free[0].Slot, nil
+	}
+
+	for _, slot := range free {
+		if slot.Start == *start {
+			return slot.Slot, nil
+		}
+	}
+	for _, slot := range w.Slots() {
+		if slot.Start == *start {
+			return Slot{}, ErrFull
+		}
+	}
+	return Slot{}, ErrNoSuchSlot
+}
+
+// Full reports whether every appointment of the window is booked up.
+func (w Window) Full(booked []pgtype.Time) bool {
+	return len(w.Free(booked)) == 0
+}
@@ -0,0 +1,82 @@
+// Package bookingstatus defines the lifecycle of a booking and which account
+// role may move a booking from one status to another.
+package bookingstatus
+
+const (
+	Pending            = "pending"
+	Confirmed          = "confirmed"
+	Completed          = "completed"
+	NoShow             = "no_show"
+	CancelledByPatient = "cancelled_by_patient"
+	CancelledByDoctor  = "cancelled_by_doctor"
+	Rescheduled        = "rescheduled" // replaced by a booking for another slot
+)
+
+// Roles that change booking statuses, matching the access token roles.
+const (
+	RoleUser   = "user"
+	RoleDoctor = "doctor"
+)
+
+type transition struct {
+	from, to string
+}
+
+// transitions maps every allowed status change to the roles that may make it.
+var transitions = map[transition][]string{
+	{Pending, Confirmed}:            {RoleDoctor},
+	{Confirmed, Completed}:          {RoleDoctor},
+	{Confirmed, NoShow}:             {RoleDoctor},
+	{Pending, CancelledByPatient}:   {RoleUser},
+	{Confirmed, CancelledByPatient}: {RoleUser},
+	{Pending, CancelledByDoctor}:    {RoleDoctor},
+	{Confirmed, CancelledByDoctor}:  {RoleDoctor},
+	{Pending, Rescheduled}:          {RoleUser},
+	{Confirmed, Rescheduled}:        {RoleUser},
+}
+
+// Valid reports whether status is part of the lifecycle.
+func Valid(status string) bool {
+	switch status {
+	case Pending, Confirmed, Completed, NoShow, CancelledByPatient, CancelledByDoctor, Rescheduled:
+		return true
+	}
+	return false
+}
+
+// Allowed reports whether an account with the given role may move a booking
+// from one status to the other.
+func Allowed(role, from, to string) bool {
+	for _, allowed := range transitions[transition{from, to}] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Next lists the statuses the role may move a booking to from status.
+func Next(role, status string) []string {
+	next := []string{}
+	for _, to := range []string{Confirmed, Completed, NoShow, CancelledByPatient, CancelledByDoctor, Rescheduled} {
+		if Allowed(role, status, to) {
+			next = append(next, to)
+		}
+	}
+	return next
+}
+
+// HoldsSlot reports whether a booking in this status keeps its availability
+// slot booked. Cancelled and rescheduled bookings give the slot back.
+func HoldsSlot(status string) bool {
+	switch status {
+	case Pending, Confirmed, Completed, NoShow:
+		return true
+	}
+	return false
+}
+
+// Cancelled reports whether status is one of the cancellations.
+func Cancelled(status string) bool {
+	return status == CancelledByPatient || status == CancelledByDoctor
+}
@@ -0,0 +1,237 @@
+package admin
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ListUsersHandler lists patients, optionally filtered by ?search= on email
+// and name.
+func ListUsersHandler(ctx *gin.Context, queries *repository.Queries) {
+	p := parsePage(ctx)
+	search := optionalString(ctx, "search")
+
+	users, err := queries.ListUsers(ctx, repository.ListUsersParams{
+		Search:     search,
+		PageSize:   p.Size,
+		PageOffset: p.Offset(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		log.Printf("ListUsersHandler: failed to list users: %v", err)
+		return
+	}
+
+	total, err := queries.CountUsers(ctx, search)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		log.Printf("ListUsersHandler: failed to count users: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(users))
+	for i, u := range users {
+		items[i] = gin.H{
+			"id":                u.ID,
+			"email":             u.Email,
+			"name":              u.Name,
+			"age":               u.Age,
+			"gender":            u.Gender,
+			"blood_group":       u.BloodGroup,
+			"email_verified_at": u.EmailVerifiedAt,
+			"suspended_at":      u.SuspendedAt,
+			"created_at":        u.CreatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, pagedResponse(p, total, items))
+}
+
+// ListDoctorsHandler lists every doctor regardless of verification state,
+// optionally filtered by ?search= and ?verification_status=.
+func ListDoctorsHandler(ctx *gin.Context, queries *repository.Queries) {
+	p := parsePage(ctx)
+	search := optionalString(ctx, "search")
+	status := optionalString(ctx, "verification_status")
+	if status != nil && *status != middleware.DoctorPendingVerification && *status != middleware.DoctorVerified && *status != middleware.DoctorRejected {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification_status"})
+		return
+	}
+
+	doctors, err := queries.ListAllDoctors(ctx, repository.ListAllDoctorsParams{
+		Search:             search,
+		VerificationStatus: status,
+		PageSize:           p.Size,
+		PageOffset:         p.Offset(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve doctors"})
+		log.Printf("ListDoctorsHandler: failed to list doctors: %v", err)
+		return
+	}
+
+	total, err := queries.CountAllDoctors(ctx, repository.CountAllDoctorsParams{
+		Search:             search,
+		VerificationStatus: status,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve doctors"})
+		log.Printf("ListDoctorsHandler: failed to count doctors: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(doctors))
+	for i, d := range doctors {
+		items[i] = doctorReviewResponse(d)
+	}
+
+	ctx.JSON(http.StatusOK, pagedResponse(p, total, items))
+}
+
+func SuspendUserHandler(ctx *gin.Context, queries *repository.Queries) {
+	setSuspended(ctx, queries, middleware.RoleUser, true)
+}
+
+func UnsuspendUserHandler(ctx *gin.Context, queries *repository.Queries) {
+	setSuspended(ctx, queries, middleware.RoleUser, false)
+}
+
+func SuspendDoctorHandler(ctx *gin.Context, queries *repository.Queries) {
+	setSuspended(ctx, queries, middleware.RoleDoctor, true)
+}
+
+func UnsuspendDoctorHandler(ctx *gin.Context, queries *repository.Queries) {
+	setSuspended(ctx, queries, middleware.RoleDoctor, false)
+}
+
+// ForceLogoutUserHandler ends every session of a patient without suspending
+// the account.
+func ForceLogoutUserHandler(ctx *gin.Context, queries *repository.Queries) {
+	forceLogout(ctx, queries, "userId")
+}
+
+func ForceLogoutDoctorHandler(ctx *gin.Context, queries *repository.Queries) {
+	forceLogout(ctx, queries, "doctorId")
+}
+
+func setSuspended(ctx *gin.Context, queries *repository.Queries, role string, suspend bool) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	param := "userId"
+	if role == middleware.RoleDoctor {
+		param = "doctorId"
+	}
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+		return
+	}
+	accountID := pgtype.UUID{Bytes: id, Valid: true}
+
+	var suspendedAt pgtype.Timestamptz
+	if suspend {
+		suspendedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("setSuspended: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	var updated int64
+	if role == middleware.RoleDoctor {
+		updated, err = qtx.SetDoctorSuspended(dbCtx, repository.SetDoctorSuspendedParams{SuspendedAt: suspendedAt, ID: accountID})
+	} else {
+		updated, err = qtx.SetUserSuspended(dbCtx, repository.SetUserSuspendedParams{SuspendedAt: suspendedAt, ID: accountID})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account"})
+		log.Printf("setSuspended: failed to update account: %v", err)
+		return
+	}
+	if updated == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	// A suspended account must not keep working on tokens issued before the
+	// suspension.
+	if suspend {
+		if err := revokeSessions(dbCtx, qtx, accountID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+			log.Printf("setSuspended: failed to revoke sessions: %v", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("setSuspended: failed to commit: %v", err)
+		return
+	}
+
+	if suspend {
+		ctx.JSON(http.StatusOK, gin.H{"message": "Account suspended", "suspended_at": suspendedAt})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Account reactivated"})
+}
+
+func forceLogout(ctx *gin.Context, queries *repository.Queries, param string) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("forceLogout: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+
+	if err := revokeSessions(dbCtx, queries.WithTx(tx), pgtype.UUID{Bytes: id, Valid: true}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		log.Printf("forceLogout: failed to revoke sessions: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("forceLogout: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "All sessions have been logged out"})
+}
+
+// revokeSessions signs out every device of the subject: its refresh tokens
+// are revoked and access tokens issued until now are rejected.
+func revokeSessions(ctx context.Context, queries *repository.Queries, subjectID pgtype.UUID) error {
+	if err := queries.RevokeRefreshTokensBySubject(ctx, subjectID); err != nil {
+		return err
+	}
+	if err := queries.RevokeSessionsBySubject(ctx, subjectID); err != nil {
+		return err
+	}
+	return queries.RevokeSubjectAccessTokens(ctx, subjectID)
+}
@@ -0,0 +1,176 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/doctor"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type RejectDoctorRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type ApproveDoctorRequest struct {
+	Notes string `json:"notes"`
+}
+
+func ListDoctorLicenseDocumentsHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctor.ListLicenseDocumentsHandler(ctx, queries)
+}
+
+func DownloadDoctorLicenseDocumentHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	documentID, err := uuid.Parse(ctx.Param("documentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	document, err := queries.GetDoctorLicenseDocument(ctx, repository.GetDoctorLicenseDocumentParams{
+		ID:       pgtype.UUID{Bytes: documentID, Valid: true},
+		DoctorID: pgtype.UUID{Bytes: doctorID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	decryptedData, err := utils.DecryptData(document.FileData, []byte(os.Getenv("ENCRYPTION_KEY")))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Decryption failed"})
+		log.Printf("DownloadDoctorLicenseDocumentHandler: decryption failed: %v", err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename="+document.FileName)
+	ctx.Data(http.StatusOK, http.DetectContentType(decryptedData), decryptedData)
+}
+
+func ApproveDoctorHandler(ctx *gin.Context, queries *repository.Queries, mail mailer.Mailer) {
+	var req ApproveDoctorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && ctx.Request.ContentLength > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var notes *string
+	if req.Notes != "" {
+		notes = &req.Notes
+	}
+	reviewDoctor(ctx, queries, mail, middleware.DoctorVerified, notes)
+}
+
+func RejectDoctorHandler(ctx *gin.Context, queries *repository.Queries, mail mailer.Mailer) {
+	var req RejectDoctorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reviewDoctor(ctx, queries, mail, middleware.DoctorRejected, &req.Reason)
+}
+
+func reviewDoctor(ctx *gin.Context, queries *repository.Queries, mail mailer.Mailer, status string, notes *string) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+	pgDoctorID := pgtype.UUID{Bytes: doctorID, Valid: true}
+
+	adminID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		return
+	}
+
+	updated, err := queries.UpdateDoctorVerificationStatus(dbCtx, repository.UpdateDoctorVerificationStatusParams{
+		ID:                 pgDoctorID,
+		VerificationStatus: status,
+		VerificationNotes:  notes,
+		ReviewedBy:         pgtype.UUID{Bytes: adminID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update doctor"})
+		log.Printf("reviewDoctor: failed to update verification status: %v", err)
+		return
+	}
+	if updated == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Doctor not found"})
+		return
+	}
+
+	d, err := queries.GetDoctorByID(dbCtx, pgDoctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("reviewDoctor: failed to reload doctor: %v", err)
+		return
+	}
+
+	if d.Email != nil {
+		if err := mail.Send(dbCtx, reviewMessage(*d.Email, status, notes)); err != nil {
+			log.Printf("reviewDoctor: failed to notify doctor: %v", err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, doctorReviewResponse(d))
+}
+
+func reviewMessage(to, status string, notes *string) mailer.Message {
+	if status == middleware.DoctorVerified {
+		return mailer.Message{
+			To:      to,
+			Subject: "Your Health-Sync doctor account has been verified",
+			Body:    "Your credentials have been approved. You are now listed in the doctor directory and can publish availability.\n",
+		}
+	}
+
+	reason := ""
+	if notes != nil {
+		reason = *notes
+	}
+	return mailer.Message{
+		To:      to,
+		Subject: "Your Health-Sync doctor account could not be verified",
+		Body:    fmt.Sprintf("We could not verify your credentials.\n\nReason: %s\n\nYou can upload new documents and contact support to be reviewed again.\n", reason),
+	}
+}
+
+func doctorReviewResponse(d repository.Doctor) gin.H {
+	return gin.H{
+		"id":                  d.ID,
+		"name":                d.Name,
+		"email":               d.Email,
+		"specialization":      d.Specialization,
+		"experience":          d.Experience,
+		"qualification":       d.Qualification,
+		"hospital_name":       d.HospitalName,
+		"consultation_fee":    d.ConsultationFee,
+		"contact_number":      d.ContactNumber,
+		"created_at":          d.CreatedAt,
+		"verification_status": d.VerificationStatus,
+		"verification_notes":  d.VerificationNotes,
+		"reviewed_at":         d.ReviewedAt,
+		"reviewed_by":         d.ReviewedBy,
+	}
+}
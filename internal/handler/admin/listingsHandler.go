@@ -0,0 +1,257 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ListBookingsHandler lists bookings across all doctors. Supported filters:
+// status, user_id, doctor_id, from and to (YYYY-MM-DD, on booking_date).
+func ListBookingsHandler(ctx *gin.Context, queries *repository.Queries) {
+	p := parsePage(ctx)
+
+	userID, err := optionalUUID(ctx, "user_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+		return
+	}
+	doctorID, err := optionalUUID(ctx, "doctor_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor_id"})
+		return
+	}
+	fromDate, err := optionalDate(ctx, "from")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date format"})
+		return
+	}
+	toDate, err := optionalDate(ctx, "to")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date format"})
+		return
+	}
+	status := optionalString(ctx, "status")
+
+	bookings, err := queries.ListAllBookings(ctx, repository.ListAllBookingsParams{
+		Status:     status,
+		UserID:     userID,
+		DoctorID:   doctorID,
+		FromDate:   fromDate,
+		ToDate:     toDate,
+		PageSize:   p.Size,
+		PageOffset: p.Offset(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookings"})
+		log.Printf("ListBookingsHandler: failed to list bookings: %v", err)
+		return
+	}
+
+	total, err := queries.CountAllBookings(ctx, repository.CountAllBookingsParams{
+		Status:   status,
+		UserID:   userID,
+		DoctorID: doctorID,
+		FromDate: fromDate,
+		ToDate:   toDate,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookings"})
+		log.Printf("ListBookingsHandler: failed to count bookings: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(bookings))
+	for i, b := range bookings {
+		items[i] = gin.H{
+			"id":                 b.ID,
+			"user_id":            b.UserID,
+			"doctor_id":          b.DoctorID,
+			"availability_id":    b.AvailabilityID,
+			"booking_date":       b.BookingDate,
+			"booking_start_time": utils.FormatTime(b.BookingStartTime),
+			"booking_end_time":   utils.FormatTime(b.BookingEndTime),
+			"status":             b.Status,
+			"created_at":         b.CreatedAt,
+			"updated_at":         b.UpdatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, pagedResponse(p, total, items))
+}
+
+// ListMedicationsHandler lists medication reminders of all patients,
+// optionally filtered by user_id and a search on the medication name.
+func ListMedicationsHandler(ctx *gin.Context, queries *repository.Queries) {
+	p := parsePage(ctx)
+
+	userID, err := optionalUUID(ctx, "user_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+		return
+	}
+	search := optionalString(ctx, "search")
+
+	medications, err := queries.ListAllMedications(ctx, repository.ListAllMedicationsParams{
+		UserID:     userID,
+		Search:     search,
+		PageSize:   p.Size,
+		PageOffset: p.Offset(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve medications"})
+		log.Printf("ListMedicationsHandler: failed to list medications: %v", err)
+		return
+	}
+
+	total, err := queries.CountAllMedications(ctx, repository.CountAllMedicationsParams{
+		UserID: userID,
+		Search: search,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve medications"})
+		log.Printf("ListMedicationsHandler: failed to count medications: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(medications))
+	for i, m := range medications {
+		items[i] = gin.H{
+			"id":              m.ID,
+			"user_id":         m.UserID,
+			"medication_name": m.MedicationName,
+			"dosage":          m.Dosage,
+			"time_to_notify":  utils.FormatTime(m.TimeToNotify),
+			"frequency":       m.Frequency,
+			"is_readbyuser":   m.IsReadbyuser,
+			"created_at":      m.CreatedAt,
+			"updated_at":      m.UpdatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, pagedResponse(p, total, items))
+}
+
+// ListAvailabilityHandler lists availability slots of all doctors. Supported
+// filters: doctor_id, is_booked, from and to (on availability_date).
+func ListAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
+	p := parsePage(ctx)
+
+	doctorID, err := optionalUUID(ctx, "doctor_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor_id"})
+		return
+	}
+	fromDate, err := optionalDate(ctx, "from")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date format"})
+		return
+	}
+	toDate, err := optionalDate(ctx, "to")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date format"})
+		return
+	}
+	var isBooked *bool
+	if value := ctx.Query("is_booked"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid is_booked"})
+			return
+		}
+		isBooked = &parsed
+	}
+
+	slots, err := queries.ListAllAvailability(ctx, repository.ListAllAvailabilityParams{
+		DoctorID:   doctorID,
+		IsBooked:   isBooked,
+		FromDate:   fromDate,
+		ToDate:     toDate,
+		PageSize:   p.Size,
+		PageOffset: p.Offset(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve availability"})
+		log.Printf("ListAvailabilityHandler: failed to list availability: %v", err)
+		return
+	}
+
+	total, err := queries.CountAllAvailability(ctx, repository.CountAllAvailabilityParams{
+		DoctorID: doctorID,
+		IsBooked: isBooked,
+		FromDate: fromDate,
+		ToDate:   toDate,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve availability"})
+		log.Printf("ListAvailabilityHandler: failed to count availability: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(slots))
+	for i, slot := range slots {
+		items[i] = gin.H{
+			"id":                slot.ID,
+			"doctor_id":         slot.DoctorID,
+			"availability_date": slot.AvailabilityDate,
+			"start_time":        utils.FormatTime(slot.StartTime),
+			"end_time":          utils.FormatTime(slot.EndTime),
+			"is_booked":         slot.IsBooked,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, pagedResponse(p, total, items))
+}
+
+// DeleteAvailabilityHandler removes a single slot of any doctor, as long as
+// nobody has booked it.
+func DeleteAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
+	availabilityUUID, err := uuid.Parse(ctx.Param("availabilityId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid availability ID"})
+		return
+	}
+	availabilityID := pgtype.UUID{Bytes: availabilityUUID, Valid: true}
+
+	bookings, err := queries.GetBookingsByAvailabilityID(ctx, availabilityID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bookings"})
+		log.Printf("DeleteAvailabilityHandler: failed to check bookings: %v", err)
+		return
+	}
+	if len(bookings) > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Cannot delete availability slot with bookings"})
+		return
+	}
+
+	deleted, err := queries.DeleteAvailabilityByID(ctx, availabilityID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete availability"})
+		log.Printf("DeleteAvailabilityHandler: failed to delete availability: %v", err)
+		return
+	}
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Availability not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Availability deleted successfully"})
+}
+
+// DeleteStaleAvailabilityHandler removes past slots that were never booked.
+func DeleteStaleAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
+	deleted, err := queries.DeleteStaleAvailability(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete availability"})
+		log.Printf("DeleteStaleAvailabilityHandler: failed to delete availability: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Stale availability deleted", "deleted": deleted})
+}
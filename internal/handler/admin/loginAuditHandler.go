@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// ListLoginAuditHandler lists failed and blocked login attempts, newest
+// first. Supported filters: email.
+func ListLoginAuditHandler(ctx *gin.Context, queries *repository.Queries) {
+	p := parsePage(ctx)
+	email := optionalString(ctx, "email")
+
+	entries, err := queries.ListLoginAudit(ctx, repository.ListLoginAuditParams{
+		Email:      email,
+		PageSize:   p.Size,
+		PageOffset: p.Offset(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve login audit"})
+		log.Printf("ListLoginAuditHandler: failed to list login audit: %v", err)
+		return
+	}
+
+	total, err := queries.CountLoginAudit(ctx, email)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve login audit"})
+		log.Printf("ListLoginAuditHandler: failed to count login audit: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(entries))
+	for i, e := range entries {
+		items[i] = gin.H{
+			"id":         e.ID,
+			"email":      e.Email,
+			"role":       e.Role,
+			"ip_address": e.IpAddress,
+			"user_agent": e.UserAgent,
+			"reason":     e.Reason,
+			"created_at": e.CreatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, pagedResponse(p, total, items))
+}
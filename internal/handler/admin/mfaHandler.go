@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RequireDoctorMFAHandler makes two-factor authentication mandatory for a
+// doctor. Until they enroll, routes guarded by RequireDoctorMFA answer 403.
+func RequireDoctorMFAHandler(ctx *gin.Context, queries *repository.Queries) {
+	setDoctorMFARequired(ctx, queries, true)
+}
+
+func MakeDoctorMFAOptionalHandler(ctx *gin.Context, queries *repository.Queries) {
+	setDoctorMFARequired(ctx, queries, false)
+}
+
+func setDoctorMFARequired(ctx *gin.Context, queries *repository.Queries, required bool) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctorId"})
+		return
+	}
+
+	updated, err := queries.SetDoctorMfaRequired(dbCtx, repository.SetDoctorMfaRequiredParams{
+		ID:          pgtype.UUID{Bytes: id, Valid: true},
+		MfaRequired: required,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update doctor"})
+		log.Printf("setDoctorMFARequired: failed to update doctor: %v", err)
+		return
+	}
+	if updated == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Doctor not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication setting updated", "mfa_required": required})
+}
+
+// ResetDoctorMFAHandler removes a doctor's authenticator and recovery codes,
+// e.g. after a lost phone. The doctor has to enroll again.
+func ResetDoctorMFAHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctorId"})
+		return
+	}
+	doctorID := pgtype.UUID{Bytes: id, Valid: true}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetDoctorMFAHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	if err := qtx.DisableDoctorMfa(dbCtx, doctorID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication"})
+		log.Printf("ResetDoctorMFAHandler: failed to disable MFA: %v", err)
+		return
+	}
+	if err := qtx.DeleteDoctorMfaRecoveryCodes(dbCtx, doctorID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication"})
+		log.Printf("ResetDoctorMFAHandler: failed to delete recovery codes: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetDoctorMFAHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type page struct {
+	Number int32
+	Size   int32
+}
+
+func (p page) Offset() int32 {
+	return (p.Number - 1) * p.Size
+}
+
+// parsePage reads ?page= (1-based) and ?page_size=, falling back to the
+// defaults for missing or invalid values.
+func parsePage(ctx *gin.Context) page {
+	p := page{Number: 1, Size: defaultPageSize}
+	if n, err := strconv.Atoi(ctx.Query("page")); err == nil && n > 0 {
+		p.Number = int32(n)
+	}
+	if n, err := strconv.Atoi(ctx.Query("page_size")); err == nil && n > 0 {
+		p.Size = int32(min(n, maxPageSize))
+	}
+	return p
+}
+
+func pagedResponse(p page, total int64, items interface{}) gin.H {
+	return gin.H{
+		"items":     items,
+		"page":      p.Number,
+		"page_size": p.Size,
+		"total":     total,
+	}
+}
+
+// optionalString returns nil for an empty query parameter.
+func optionalString(ctx *gin.Context, key string) *string {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// optionalUUID parses a query parameter, returning an invalid (NULL) UUID
+// when it is absent.
+func optionalUUID(ctx *gin.Context, key string) (pgtype.UUID, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return pgtype.UUID{}, nil
+	}
+	parsed, err := uuid.Parse(value)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}, nil
+}
+
+// optionalDate parses a YYYY-MM-DD query parameter, returning a NULL date
+// when it is absent.
+func optionalDate(ctx *gin.Context, key string) (pgtype.Date, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return pgtype.Date{}, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return pgtype.Date{}, err
+	}
+	return pgtype.Date{Time: parsed, Valid: true}, nil
+}
@@ -0,0 +1,143 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/review"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ModerateReviewRequest struct {
+	Action string `json:"action" binding:"required,oneof=keep hide"`
+	Notes  string `json:"notes" binding:"max=1000"`
+}
+
+func moderationResponse(r repository.DoctorReview) gin.H {
+	return gin.H{
+		"id":               r.ID,
+		"booking_id":       r.BookingID,
+		"doctor_id":        r.DoctorID,
+		"user_id":          r.UserID,
+		"rating":           r.Rating,
+		"review":           r.Review,
+		"doctor_reply":     r.DoctorReply,
+		"status":           r.Status,
+		"flag_reason":      r.FlagReason,
+		"flagged_by":       r.FlaggedBy,
+		"flagged_at":       r.FlaggedAt,
+		"moderation_notes": r.ModerationNotes,
+		"moderated_by":     r.ModeratedBy,
+		"moderated_at":     r.ModeratedAt,
+		"created_at":       r.CreatedAt,
+	}
+}
+
+// ListFlaggedReviewsHandler is the moderation queue: flagged reviews, the
+// longest waiting first.
+func ListFlaggedReviewsHandler(ctx *gin.Context, queries *repository.Queries) {
+	p := parsePage(ctx)
+
+	reviews, err := queries.ListFlaggedDoctorReviews(ctx, repository.ListFlaggedDoctorReviewsParams{
+		PageSize:   p.Size,
+		PageOffset: p.Offset(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reviews"})
+		log.Printf("ListFlaggedReviewsHandler: failed to list reviews: %v", err)
+		return
+	}
+
+	total, err := queries.CountFlaggedDoctorReviews(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reviews"})
+		log.Printf("ListFlaggedReviewsHandler: failed to count reviews: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(reviews))
+	for i, r := range reviews {
+		items[i] = moderationResponse(r)
+	}
+
+	ctx.JSON(http.StatusOK, pagedResponse(p, total, items))
+}
+
+// ModerateReviewHandler keeps a review, publishing it again, or hides it.
+// Hidden reviews no longer count towards the doctor's rating.
+func ModerateReviewHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reviewID, err := uuid.Parse(ctx.Param("reviewId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+	adminID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		return
+	}
+
+	var req ModerateReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status := review.StatusPublished
+	if req.Action == "hide" {
+		status = review.StatusHidden
+	}
+	var notes *string
+	if trimmed := strings.TrimSpace(req.Notes); trimmed != "" {
+		notes = &trimmed
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ModerateReviewHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	moderated, err := qtx.ModerateDoctorReview(dbCtx, repository.ModerateDoctorReviewParams{
+		Status:          status,
+		ModerationNotes: notes,
+		ModeratedBy:     pgtype.UUID{Bytes: adminID, Valid: true},
+		ID:              pgtype.UUID{Bytes: reviewID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate review"})
+		log.Printf("ModerateReviewHandler: failed to update review: %v", err)
+		return
+	}
+	if err := qtx.RefreshDoctorRating(dbCtx, moderated.DoctorID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate review"})
+		log.Printf("ModerateReviewHandler: failed to refresh rating: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate review"})
+		log.Printf("ModerateReviewHandler: failed to commit transaction: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, moderationResponse(moderated))
+}
@@ -2,39 +2,20 @@ package auth
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
+	"github.com/SRIRAMGJ007/Health-Sync/internal/loginguard"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
-	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/joho/godotenv"
 	"golang.org/x/crypto/bcrypt"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
-func init() {
-	// Load environment variables from .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Warning: No .env file found")
-	}
-	googleOAuthConfig = &oauth2.Config{
-		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
-		Scopes:       []string{"profile", "email"},
-		Endpoint:     google.Endpoint,
-	}
-}
-
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
@@ -54,12 +35,6 @@ type DoctorRegisterRequest struct {
 	Role            string `json:"role" binding:"required"`
 }
 
-type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	jwt.StandardClaims
-}
-
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
@@ -71,21 +46,6 @@ type DoctorLoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-type GoogleUser struct {
-	ID    string `json:"sub"`
-	Email string `json:"email"`
-	Name  string `json:"name"`
-}
-
-//	var googleOAuthConfig = &oauth2.Config{
-//		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-//		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-//		RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
-//		Scopes:       []string{"profile", "email"},
-//		Endpoint:     google.Endpoint,
-//	}
-var googleOAuthConfig *oauth2.Config
-
 func RegisterHandler(ctx *gin.Context, queries *repository.Queries) {
 	var req RegisterRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -111,15 +71,16 @@ func RegisterHandler(ctx *gin.Context, queries *repository.Queries) {
 			return
 		}
 
-		token, err := utils.GenerateJWT(user.ID.String(), user.Email, "user")
+		token, refreshToken, err := issueTokens(ctx, queries, user.ID, user.Email, "user")
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
 		}
 
 		ctx.JSON(http.StatusCreated, gin.H{
-			"message": "user created successfully",
-			"token":   token,
+			"message":       "user created successfully",
+			"token":         token,
+			"refresh_token": refreshToken,
 			"user": gin.H{
 				"id":    user.ID,
 				"email": user.Email,
@@ -161,15 +122,16 @@ func RegisterHandler(ctx *gin.Context, queries *repository.Queries) {
 			return
 		}
 
-		token, err := utils.GenerateJWT(doctor.ID.String(), *doctor.Email, "doctor")
+		token, refreshToken, err := issueTokens(ctx, queries, doctor.ID, *doctor.Email, "doctor")
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
 		}
 
 		ctx.JSON(http.StatusOK, gin.H{
-			"message": "Doctor login successful",
-			"token":   token,
+			"message":       "Doctor login successful",
+			"token":         token,
+			"refresh_token": refreshToken,
 			"doctor": gin.H{
 				"id":               doctor.ID,
 				"email":            doctor.Email,
@@ -186,7 +148,7 @@ func RegisterHandler(ctx *gin.Context, queries *repository.Queries) {
 	}
 }
 
-func UserRegisterHandler(ctx *gin.Context, queries *repository.Queries) {
+func UserRegisterHandler(ctx *gin.Context, queries *repository.Queries, mail mailer.Mailer) {
 	var req RegisterRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -210,24 +172,30 @@ func UserRegisterHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
-	token, err := utils.GenerateJWT(user.ID.String(), user.Email, "user")
+	token, refreshToken, err := issueTokens(ctx, queries, user.ID, user.Email, "user")
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	if err := sendEmailVerification(ctx, queries, mail, user.ID, user.Email, "user"); err != nil {
+		log.Printf("UserRegisterHandler: failed to send verification email: %v", err)
+	}
+
 	ctx.JSON(http.StatusCreated, gin.H{
-		"message": "User created successfully",
-		"token":   token,
+		"message":       "User created successfully",
+		"token":         token,
+		"refresh_token": refreshToken,
 		"user": gin.H{
-			"id":    user.ID,
-			"email": user.Email,
-			"name":  user.Name,
+			"id":             user.ID,
+			"email":          user.Email,
+			"name":           user.Name,
+			"email_verified": false,
 		},
 	})
 }
 
-func DoctorRegisterHandler(ctx *gin.Context, queries *repository.Queries) {
+func DoctorRegisterHandler(ctx *gin.Context, queries *repository.Queries, mail mailer.Mailer) {
 	var req DoctorRegisterRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -263,29 +231,36 @@ func DoctorRegisterHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
-	token, err := utils.GenerateJWT(doctor.ID.String(), *doctor.Email, "doctor")
+	token, refreshToken, err := issueTokens(ctx, queries, doctor.ID, *doctor.Email, "doctor")
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	if err := sendEmailVerification(ctx, queries, mail, doctor.ID, *doctor.Email, "doctor"); err != nil {
+		log.Printf("DoctorRegisterHandler: failed to send verification email: %v", err)
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Doctor created successfully",
-		"token":   token,
+		"message":       "Doctor created successfully",
+		"token":         token,
+		"refresh_token": refreshToken,
 		"doctor": gin.H{
-			"id":               doctor.ID,
-			"email":            doctor.Email,
-			"name":             doctor.Name,
-			"specialization":   doctor.Specialization,
-			"experience":       doctor.Experience,
-			"qualification":    doctor.Qualification,
-			"hospital_name":    doctor.HospitalName,
-			"consultation_fee": doctor.ConsultationFee,
+			"id":                  doctor.ID,
+			"email":               doctor.Email,
+			"name":                doctor.Name,
+			"specialization":      doctor.Specialization,
+			"experience":          doctor.Experience,
+			"qualification":       doctor.Qualification,
+			"hospital_name":       doctor.HospitalName,
+			"consultation_fee":    doctor.ConsultationFee,
+			"email_verified":      false,
+			"verification_status": middleware.DoctorPendingVerification,
 		},
 	})
 }
 
-func UserLoginHandler(ctx *gin.Context, queries *repository.Queries) {
+func UserLoginHandler(ctx *gin.Context, queries *repository.Queries, guard *loginguard.Guard) {
 	log.Printf("user login request received")
 	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -297,31 +272,44 @@ func UserLoginHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
+	attempt := newLoginAttempt(ctx, guard, queries, req.Email, "user")
+	if attempt.blocked(ctx, dbCtx) {
+		return
+	}
+
 	user, err := queries.GetUserByEmail(dbCtx, req.Email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+			attempt.failed(ctx, dbCtx, loginFailureUnknownAccount)
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(*user.PasswordHash), []byte(req.Password))
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	// Accounts created through Google have no password.
+	if user.PasswordHash == nil || bcrypt.CompareHashAndPassword([]byte(*user.PasswordHash), []byte(req.Password)) != nil {
+		attempt.failed(ctx, dbCtx, loginFailureInvalidPassword)
+		return
+	}
+
+	if user.SuspendedAt.Valid {
+		attempt.rejected(ctx, dbCtx, loginFailureSuspended)
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
 		return
 	}
+	attempt.succeeded(dbCtx)
 
-	token, err := utils.GenerateJWT(user.ID.String(), user.Email, "user")
+	token, refreshToken, err := issueTokens(ctx, queries, user.ID, user.Email, "user")
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "User login successful",
-		"token":   token,
+		"message":       "User login successful",
+		"token":         token,
+		"refresh_token": refreshToken,
 		"user": gin.H{
 			"id":    user.ID,
 			"email": user.Email,
@@ -330,7 +318,7 @@ func UserLoginHandler(ctx *gin.Context, queries *repository.Queries) {
 	})
 }
 
-func DoctorLoginHandler(ctx *gin.Context, queries *repository.Queries) {
+func DoctorLoginHandler(ctx *gin.Context, queries *repository.Queries, guard *loginguard.Guard) {
 	log.Printf("doctor login request received")
 	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -342,135 +330,112 @@ func DoctorLoginHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
+	attempt := newLoginAttempt(ctx, guard, queries, req.Email, "doctor")
+	if attempt.blocked(ctx, dbCtx) {
+		return
+	}
+
 	doctor, err := queries.GetDoctorByEmail(dbCtx, &req.Email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+			attempt.failed(ctx, dbCtx, loginFailureUnknownAccount)
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(*doctor.PasswordHash), []byte(req.Password))
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	if doctor.PasswordHash == nil || bcrypt.CompareHashAndPassword([]byte(*doctor.PasswordHash), []byte(req.Password)) != nil {
+		attempt.failed(ctx, dbCtx, loginFailureInvalidPassword)
 		return
 	}
 
-	token, err := utils.GenerateJWT(doctor.ID.String(), *doctor.Email, "doctor")
+	if doctor.SuspendedAt.Valid {
+		attempt.rejected(ctx, dbCtx, loginFailureSuspended)
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		return
+	}
+
+	// With two-factor authentication the password only earns a challenge;
+	// the lockout counter is reset once the code has been checked too.
+	if doctor.MfaEnabledAt.Valid {
+		respondMFAChallenge(ctx, dbCtx, queries, doctor.ID)
+		return
+	}
+	attempt.succeeded(dbCtx)
+
+	token, refreshToken, err := issueTokens(ctx, queries, doctor.ID, *doctor.Email, "doctor")
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Doctor login successful",
-		"token":   token,
+		"message":                 "Doctor login successful",
+		"token":                   token,
+		"refresh_token":           refreshToken,
+		"mfa_enrollment_required": doctor.MfaRequired,
 		"doctor": gin.H{
-			"id":               doctor.ID,
-			"email":            doctor.Email,
-			"name":             doctor.Name,
-			"specialization":   doctor.Specialization,
-			"experience":       doctor.Experience,
-			"qualification":    doctor.Qualification,
-			"hospital_name":    doctor.HospitalName,
-			"consultation_fee": doctor.ConsultationFee,
+			"id":                  doctor.ID,
+			"email":               doctor.Email,
+			"name":                doctor.Name,
+			"specialization":      doctor.Specialization,
+			"experience":          doctor.Experience,
+			"qualification":       doctor.Qualification,
+			"hospital_name":       doctor.HospitalName,
+			"consultation_fee":    doctor.ConsultationFee,
+			"verification_status": doctor.VerificationStatus,
 		},
 	})
 }
 
-func GoogleAuthhandler(ctx *gin.Context, queries *repository.Queries) {
-
-	log.Printf("Googleauth request recived")
+func AdminLoginHandler(ctx *gin.Context, queries *repository.Queries, guard *loginguard.Guard) {
+	log.Printf("admin login request received")
 	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	code := ctx.Query("code")
-	if code == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "google authorizationcode missing"})
-		log.Println("google authorizationcode missing")
+	var req DoctorLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		log.Println("error binding json: ", err)
 		return
 	}
 
-	Gtoken, err := googleOAuthConfig.Exchange(context.Background(), code)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to exchange google token"})
-		log.Printf("failed to exchange google token :%v ", err)
+	attempt := newLoginAttempt(ctx, guard, queries, req.Email, middleware.RoleAdmin)
+	if attempt.blocked(ctx, dbCtx) {
 		return
 	}
 
-	client := googleOAuthConfig.Client(context.Background(), Gtoken)
-	// https://www.googleapis.com/oauth2/v1/userinfo?alt=json
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v3/userinfo")
+	admin, err := queries.GetAdminByEmail(dbCtx, req.Email)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user info"})
-		log.Printf("failed to fetch user info :%v ", err)
+		if err == pgx.ErrNoRows {
+			attempt.failed(ctx, dbCtx, loginFailureUnknownAccount)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	defer resp.Body.Close()
-
-	// decode user info
-	var googleuser GoogleUser
-	if err := json.NewDecoder(resp.Body).Decode(&googleuser); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decode user info"})
-		log.Printf("failed to decode user info : %v ", err)
-		return
-	}
-	// check if user exist
-	user, err := queries.GetUserByGoogleID(ctx, &googleuser.ID)
-	// the below condition is for creating new google user
-	log.Println(" checking if user already exists ")
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			newuser, err := queries.CreateUserWithGoogle(dbCtx, repository.CreateUserWithGoogleParams{
-				GoogleID: &googleuser.ID,
-				Email:    googleuser.Email,
-				Name:     &googleuser.Name,
-			})
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
-				log.Printf("failed to create user :%v", err)
-				return
-			}
-			// user = newuser
-			token, err := utils.GenerateJWT(newuser.ID.String(), newuser.Email, "user")
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-				log.Printf("failed to generate token for the  user :%v", err)
-				return
-			}
-
-			// Send response
-			ctx.JSON(http.StatusOK, gin.H{
-				"message": "new user login successful",
-				"token":   token,
-				"user": gin.H{
-					"id":    newuser.ID,
-					"email": newuser.Email,
-					"name":  newuser.Name,
-				},
-			})
-		}
+	if bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(req.Password)) != nil {
+		attempt.failed(ctx, dbCtx, loginFailureInvalidPassword)
 		return
 	}
-	// the below condition is for existing google user
-	token, err := utils.GenerateJWT(user.ID.String(), user.Email, "user")
+	attempt.succeeded(dbCtx)
+
+	token, refreshToken, err := issueTokens(ctx, queries, admin.ID, admin.Email, middleware.RoleAdmin)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
-		log.Printf("failed to decode user info : %v ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
-		"user": gin.H{
-			"id":    user.ID,
-			"email": user.Email,
-			"name":  user.Name,
+		"message":       "Admin login successful",
+		"token":         token,
+		"refresh_token": refreshToken,
+		"admin": gin.H{
+			"id":    admin.ID,
+			"email": admin.Email,
+			"name":  admin.Name,
 		},
 	})
-
 }
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const emailVerificationTTL = 48 * time.Hour
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// VerifyEmailHandler accepts the token either as ?token= (the link in the
+// email) or as a JSON body.
+func VerifyEmailHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var req VerifyEmailRequest
+	var err error
+	if ctx.Request.Method == http.MethodGet {
+		err = ctx.ShouldBindQuery(&req)
+	} else {
+		err = ctx.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stored, err := queries.GetEmailVerificationTokenByHash(dbCtx, token.Hash(req.Token))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired verification token"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("VerifyEmailHandler: failed to look up verification token: %v", err)
+		return
+	}
+
+	if stored.UsedAt.Valid || time.Now().After(stored.ExpiresAt.Time) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired verification token"})
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("VerifyEmailHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	used, err := qtx.MarkEmailVerificationTokenUsed(dbCtx, stored.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("VerifyEmailHandler: failed to mark token used: %v", err)
+		return
+	}
+	if used == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired verification token"})
+		return
+	}
+
+	if stored.Role == middleware.RoleDoctor {
+		err = qtx.MarkDoctorEmailVerified(dbCtx, stored.AccountID)
+	} else {
+		err = qtx.MarkUserEmailVerified(dbCtx, stored.AccountID)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("VerifyEmailHandler: failed to mark email verified: %v", err)
+		return
+	}
+
+	if err := qtx.InvalidateEmailVerificationTokens(dbCtx, stored.AccountID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("VerifyEmailHandler: failed to invalidate verification tokens: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("VerifyEmailHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Email address verified"})
+}
+
+func ResendVerificationEmailHandler(ctx *gin.Context, queries *repository.Queries, mail mailer.Mailer) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	parsed, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		return
+	}
+	accountID := pgtype.UUID{Bytes: parsed, Valid: true}
+	role := ctx.GetString("role")
+
+	var verifiedAt pgtype.Timestamptz
+	if role == middleware.RoleDoctor {
+		verifiedAt, err = queries.GetDoctorEmailVerifiedAt(dbCtx, accountID)
+	} else {
+		verifiedAt, err = queries.GetUserEmailVerifiedAt(dbCtx, accountID)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResendVerificationEmailHandler: failed to load verification state: %v", err)
+		return
+	}
+	if verifiedAt.Valid {
+		ctx.JSON(http.StatusOK, gin.H{"message": "Email address is already verified"})
+		return
+	}
+
+	// Only the newest link should work.
+	if err := queries.InvalidateEmailVerificationTokens(dbCtx, accountID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResendVerificationEmailHandler: failed to invalidate verification tokens: %v", err)
+		return
+	}
+
+	if err := sendEmailVerification(dbCtx, queries, mail, accountID, ctx.GetString("email"), role); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		log.Printf("ResendVerificationEmailHandler: failed to send verification email: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+func sendEmailVerification(ctx context.Context, queries *repository.Queries, mail mailer.Mailer, accountID pgtype.UUID, email, role string) error {
+	verifyToken, verifyHash, err := token.GenerateOpaque()
+	if err != nil {
+		return err
+	}
+
+	_, err = queries.CreateEmailVerificationToken(ctx, repository.CreateEmailVerificationTokenParams{
+		AccountID: accountID,
+		Role:      role,
+		TokenHash: verifyHash,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(emailVerificationTTL), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your Health-Sync email address",
+		Body: fmt.Sprintf("Welcome to Health-Sync! Please confirm your email address.\n\n%s\n\n"+
+			"The link expires in %d hours.\n",
+			linkWithToken(os.Getenv("VERIFY_EMAIL_URL"), verifyToken), int(emailVerificationTTL.Hours())),
+	})
+}
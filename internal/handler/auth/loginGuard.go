@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/loginguard"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Reasons stored in login_audit.
+const (
+	loginFailureUnknownAccount  = "unknown_account"
+	loginFailureInvalidPassword = "invalid_password"
+	loginFailureSuspended       = "suspended"
+	loginFailureLocked          = "locked"
+	loginFailureInvalidMFACode  = "invalid_mfa_code"
+)
+
+// loginAttempt bundles what the login handlers need to throttle and audit a
+// password login.
+type loginAttempt struct {
+	guard      *loginguard.Guard
+	queries    *repository.Queries
+	email      string
+	role       string
+	accountKey string
+	ipKey      string
+}
+
+func newLoginAttempt(ctx *gin.Context, guard *loginguard.Guard, queries *repository.Queries, email, role string) *loginAttempt {
+	return &loginAttempt{
+		guard:      guard,
+		queries:    queries,
+		email:      email,
+		role:       role,
+		accountKey: loginguard.AccountKey(role, email),
+		ipKey:      loginguard.IPKey(ctx.ClientIP()),
+	}
+}
+
+// blocked responds with 429 and returns true while the account or the
+// client address is locked out. Store errors fail open.
+func (a *loginAttempt) blocked(ctx *gin.Context, dbCtx context.Context) bool {
+	wait, err := a.guard.Check(dbCtx, a.accountKey, a.ipKey)
+	if err != nil {
+		log.Printf("loginAttempt: failed to check login attempts: %v", err)
+		return false
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	a.audit(ctx, dbCtx, loginFailureLocked)
+	setRetryAfter(ctx, wait)
+	ctx.JSON(http.StatusTooManyRequests, gin.H{
+		"error":               "Too many failed login attempts, try again later",
+		"retry_after_seconds": retryAfterSeconds(wait),
+	})
+	return true
+}
+
+// failed counts a bad email, password or two-factor code and responds with
+// 401. When the failure starts a delay the response says how long to wait.
+func (a *loginAttempt) failed(ctx *gin.Context, dbCtx context.Context, reason string) {
+	a.audit(ctx, dbCtx, reason)
+
+	wait, err := a.guard.Fail(dbCtx, a.accountKey, a.ipKey)
+	if err != nil {
+		log.Printf("loginAttempt: failed to record login failure: %v", err)
+	}
+
+	message := "Invalid email or password"
+	if reason == loginFailureInvalidMFACode {
+		message = "Invalid two-factor code"
+	}
+	resp := gin.H{"error": message}
+	if wait > 0 {
+		setRetryAfter(ctx, wait)
+		resp["retry_after_seconds"] = retryAfterSeconds(wait)
+	}
+	ctx.JSON(http.StatusUnauthorized, resp)
+}
+
+// rejected audits a login with valid credentials that is refused anyway,
+// e.g. for a suspended account. It does not count towards the lockout.
+func (a *loginAttempt) rejected(ctx *gin.Context, dbCtx context.Context, reason string) {
+	a.audit(ctx, dbCtx, reason)
+}
+
+func (a *loginAttempt) succeeded(dbCtx context.Context) {
+	if err := a.guard.Succeed(dbCtx, a.accountKey); err != nil {
+		log.Printf("loginAttempt: failed to reset login attempts: %v", err)
+	}
+}
+
+func (a *loginAttempt) audit(ctx *gin.Context, dbCtx context.Context, reason string) {
+	ip := ctx.ClientIP()
+	userAgent := ctx.Request.UserAgent()
+	err := a.queries.CreateLoginAudit(dbCtx, repository.CreateLoginAuditParams{
+		Email:     a.email,
+		Role:      a.role,
+		IpAddress: &ip,
+		UserAgent: &userAgent,
+		Reason:    reason,
+	})
+	if err != nil {
+		log.Printf("loginAttempt: failed to write login audit: %v", err)
+	}
+}
+
+func retryAfterSeconds(wait time.Duration) int {
+	return int(math.Ceil(wait.Seconds()))
+}
+
+func setRetryAfter(ctx *gin.Context, wait time.Duration) {
+	ctx.Header("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+}
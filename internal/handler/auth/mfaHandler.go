@@ -0,0 +1,597 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/loginguard"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/token"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/totp"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	mfaChallengeTTL         = 5 * time.Minute
+	maxMFAChallengeAttempts = 5
+	recoveryCodeCount       = 10
+)
+
+type MFACodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// mfaIssuer is the name authenticator apps show next to the code.
+func mfaIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Health-Sync"
+}
+
+func callerID(ctx *gin.Context) (pgtype.UUID, bool) {
+	id, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		return pgtype.UUID{}, false
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, true
+}
+
+// MFAStatusHandler tells the calling doctor whether two-factor
+// authentication is enabled or required for the account.
+func MFAStatusHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	mfa, err := queries.GetDoctorMfa(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFAStatusHandler: failed to load MFA settings: %v", err)
+		return
+	}
+
+	remaining, err := queries.CountUnusedDoctorMfaRecoveryCodes(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFAStatusHandler: failed to count recovery codes: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"enabled":                  mfa.MfaEnabledAt.Valid,
+		"enabled_at":               mfa.MfaEnabledAt,
+		"required":                 mfa.MfaRequired,
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// MFAEnrollHandler starts enrollment by generating a new TOTP secret. The
+// secret only becomes active once MFAConfirmHandler has seen a valid code.
+func MFAEnrollHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	mfa, err := queries.GetDoctorMfa(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFAEnrollHandler: failed to load MFA settings: %v", err)
+		return
+	}
+	if mfa.MfaEnabledAt.Valid {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		log.Printf("MFAEnrollHandler: %v", err)
+		return
+	}
+
+	encrypted, err := utils.EncryptData([]byte(secret), []byte(os.Getenv("ENCRYPTION_KEY")))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store secret"})
+		log.Printf("MFAEnrollHandler: failed to encrypt secret: %v", err)
+		return
+	}
+
+	err = queries.SetDoctorMfaSecret(dbCtx, repository.SetDoctorMfaSecretParams{ID: doctorID, MfaSecret: encrypted})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store secret"})
+		log.Printf("MFAEnrollHandler: failed to store secret: %v", err)
+		return
+	}
+
+	account := ""
+	if mfa.Email != nil {
+		account = *mfa.Email
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":          "Scan the provisioning URI with an authenticator app, then confirm with a code",
+		"secret":           secret,
+		"provisioning_uri": totp.ProvisioningURI(mfaIssuer(), account, secret),
+	})
+}
+
+// MFAConfirmHandler enables two-factor authentication once the doctor has
+// proven the authenticator app works, and returns the recovery codes. They
+// are shown only this once.
+func MFAConfirmHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	var req MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+
+	mfa, err := queries.GetDoctorMfa(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFAConfirmHandler: failed to load MFA settings: %v", err)
+		return
+	}
+	if mfa.MfaEnabledAt.Valid {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+		return
+	}
+	if mfa.MfaSecret == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start enrollment first"})
+		return
+	}
+
+	secret, err := decryptMFASecret(mfa.MfaSecret)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read secret"})
+		log.Printf("MFAConfirmHandler: %v", err)
+		return
+	}
+
+	step, valid := totp.Validate(secret, req.Code, time.Now(), 0)
+	if !valid {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFAConfirmHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	if err := qtx.EnableDoctorMfa(dbCtx, repository.EnableDoctorMfaParams{ID: doctorID, MfaLastUsedStep: &step}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		log.Printf("MFAConfirmHandler: failed to enable MFA: %v", err)
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(dbCtx, qtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		log.Printf("MFAConfirmHandler: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFAConfirmHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+// MFARecoveryCodesHandler replaces the recovery codes after checking a
+// current code.
+func MFARecoveryCodesHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	var req MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	mfa, err := queries.GetDoctorMfa(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFARecoveryCodesHandler: failed to load MFA settings: %v", err)
+		return
+	}
+	if !mfa.MfaEnabledAt.Valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication is not enabled"})
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFARecoveryCodesHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	valid, err := verifyDoctorMFA(dbCtx, qtx, mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		log.Printf("MFARecoveryCodesHandler: %v", err)
+		return
+	}
+	if !valid {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(dbCtx, qtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		log.Printf("MFARecoveryCodesHandler: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFARecoveryCodesHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// MFADisableHandler turns two-factor authentication off after checking a
+// current code. Doctors an admin has required to use it cannot opt out.
+func MFADisableHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	var req MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	mfa, err := queries.GetDoctorMfa(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFADisableHandler: failed to load MFA settings: %v", err)
+		return
+	}
+	if !mfa.MfaEnabledAt.Valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication is not enabled"})
+		return
+	}
+	if mfa.MfaRequired {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "two-factor authentication is required for this account"})
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFADisableHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	valid, err := verifyDoctorMFA(dbCtx, qtx, mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		log.Printf("MFADisableHandler: %v", err)
+		return
+	}
+	if !valid {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	if err := qtx.DisableDoctorMfa(dbCtx, doctorID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		log.Printf("MFADisableHandler: failed to disable MFA: %v", err)
+		return
+	}
+	if err := qtx.DeleteDoctorMfaRecoveryCodes(dbCtx, doctorID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		log.Printf("MFADisableHandler: failed to delete recovery codes: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("MFADisableHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// DoctorMFALoginHandler is the second login step for doctors with
+// two-factor authentication: it trades the challenge token from
+// DoctorLoginHandler plus a TOTP or recovery code for the real tokens.
+func DoctorMFALoginHandler(ctx *gin.Context, queries *repository.Queries, guard *loginguard.Guard) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var req MFALoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	challenge, err := queries.GetMfaChallengeByHash(dbCtx, token.Hash(req.MFAToken))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired MFA token"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DoctorMFALoginHandler: failed to look up challenge: %v", err)
+		return
+	}
+	if challenge.UsedAt.Valid || time.Now().After(challenge.ExpiresAt.Time) || challenge.Attempts >= maxMFAChallengeAttempts {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired MFA token"})
+		return
+	}
+
+	doctor, err := queries.GetDoctorByID(dbCtx, challenge.DoctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DoctorMFALoginHandler: failed to load doctor: %v", err)
+		return
+	}
+
+	attempt := newLoginAttempt(ctx, guard, queries, *doctor.Email, "doctor")
+	if attempt.blocked(ctx, dbCtx) {
+		return
+	}
+
+	if doctor.SuspendedAt.Valid {
+		attempt.rejected(ctx, dbCtx, loginFailureSuspended)
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		return
+	}
+
+	mfa := repository.GetDoctorMfaRow{
+		ID:              doctor.ID,
+		Email:           doctor.Email,
+		MfaSecret:       doctor.MfaSecret,
+		MfaEnabledAt:    doctor.MfaEnabledAt,
+		MfaRequired:     doctor.MfaRequired,
+		MfaLastUsedStep: doctor.MfaLastUsedStep,
+	}
+	valid, err := verifyDoctorMFA(dbCtx, queries, mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		log.Printf("DoctorMFALoginHandler: %v", err)
+		return
+	}
+	if !valid {
+		if _, err := queries.IncrementMfaChallengeAttempts(dbCtx, challenge.ID); err != nil {
+			log.Printf("DoctorMFALoginHandler: failed to count attempt: %v", err)
+		}
+		attempt.failed(ctx, dbCtx, loginFailureInvalidMFACode)
+		return
+	}
+
+	used, err := queries.MarkMfaChallengeUsed(dbCtx, challenge.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DoctorMFALoginHandler: failed to mark challenge used: %v", err)
+		return
+	}
+	if used == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired MFA token"})
+		return
+	}
+	attempt.succeeded(dbCtx)
+
+	if err := queries.DeleteExpiredMfaChallenges(dbCtx); err != nil {
+		log.Printf("DoctorMFALoginHandler: failed to prune challenges: %v", err)
+	}
+
+	token, refreshToken, err := issueTokens(ctx, queries, doctor.ID, *doctor.Email, "doctor")
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       "Doctor login successful",
+		"token":         token,
+		"refresh_token": refreshToken,
+		"doctor":        doctorProfile(doctor),
+	})
+}
+
+func doctorProfile(doctor repository.Doctor) gin.H {
+	return gin.H{
+		"id":                  doctor.ID,
+		"email":               doctor.Email,
+		"name":                doctor.Name,
+		"specialization":      doctor.Specialization,
+		"experience":          doctor.Experience,
+		"qualification":       doctor.Qualification,
+		"hospital_name":       doctor.HospitalName,
+		"consultation_fee":    doctor.ConsultationFee,
+		"verification_status": doctor.VerificationStatus,
+	}
+}
+
+// respondMFAChallenge answers the first login step of a doctor with
+// two-factor authentication: instead of tokens the client gets a challenge
+// to redeem at DoctorMFALoginHandler.
+func respondMFAChallenge(ctx *gin.Context, dbCtx context.Context, queries *repository.Queries, doctorID pgtype.UUID) {
+	mfaToken, err := createMFAChallenge(dbCtx, queries, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor login"})
+		log.Printf("respondMFAChallenge: failed to create MFA challenge: %v", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Two-factor authentication required",
+		"mfa_required": true,
+		"mfa_token":    mfaToken,
+		"expires_in":   int(mfaChallengeTTL.Seconds()),
+	})
+}
+
+// createMFAChallenge stores a short-lived challenge for the second login
+// step and returns the token the client sends back with the code.
+func createMFAChallenge(ctx context.Context, queries *repository.Queries, doctorID pgtype.UUID) (string, error) {
+	challenge, hash, err := token.GenerateOpaque()
+	if err != nil {
+		return "", err
+	}
+
+	err = queries.CreateMfaChallenge(ctx, repository.CreateMfaChallengeParams{
+		DoctorID:  doctorID,
+		TokenHash: hash,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(mfaChallengeTTL), Valid: true},
+	})
+	if err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// verifyDoctorMFA checks a TOTP code or, failing that, a recovery code and
+// consumes it so it cannot be used twice.
+func verifyDoctorMFA(ctx context.Context, queries *repository.Queries, mfa repository.GetDoctorMfaRow, code, recoveryCode string) (bool, error) {
+	if !mfa.MfaEnabledAt.Valid || mfa.MfaSecret == nil {
+		return false, nil
+	}
+
+	if code != "" {
+		secret, err := decryptMFASecret(mfa.MfaSecret)
+		if err != nil {
+			return false, err
+		}
+
+		var lastUsed int64
+		if mfa.MfaLastUsedStep != nil {
+			lastUsed = *mfa.MfaLastUsedStep
+		}
+		step, valid := totp.Validate(secret, code, time.Now(), lastUsed)
+		if !valid {
+			return false, nil
+		}
+
+		// Guards against the same code being replayed concurrently.
+		updated, err := queries.UseDoctorMfaStep(ctx, repository.UseDoctorMfaStepParams{Step: &step, ID: mfa.ID})
+		if err != nil {
+			return false, err
+		}
+		return updated == 1, nil
+	}
+
+	if recoveryCode != "" {
+		updated, err := queries.UseDoctorMfaRecoveryCode(ctx, repository.UseDoctorMfaRecoveryCodeParams{
+			DoctorID: mfa.ID,
+			CodeHash: token.Hash(normalizeRecoveryCode(recoveryCode)),
+		})
+		if err != nil {
+			return false, err
+		}
+		return updated == 1, nil
+	}
+
+	return false, nil
+}
+
+func decryptMFASecret(encrypted []byte) (string, error) {
+	secret, err := utils.DecryptData(encrypted, []byte(os.Getenv("ENCRYPTION_KEY")))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt MFA secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+// replaceRecoveryCodes drops any existing recovery codes and stores a fresh
+// set, returning the plain codes for the doctor to write down.
+func replaceRecoveryCodes(ctx context.Context, queries *repository.Queries, doctorID pgtype.UUID) ([]string, error) {
+	if err := queries.DeleteDoctorMfaRecoveryCodes(ctx, doctorID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+		codes[i] = code[:4] + "-" + code[4:]
+
+		err := queries.CreateDoctorMfaRecoveryCode(ctx, repository.CreateDoctorMfaRecoveryCodeParams{
+			DoctorID: doctorID,
+			CodeHash: token.Hash(normalizeRecoveryCode(codes[i])),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes without the dash and
+// in any case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,470 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/oidc"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oauthStateTTL    = 10 * time.Minute
+	oauthStateCookie = "oauth_state"
+)
+
+var (
+	errIdentityRoleMismatch = errors.New("identity is linked to a different account type")
+	errIdentityConflict     = errors.New("account is already linked to another identity of this provider")
+	errIdentityUnverified   = errors.New("provider did not verify the email address")
+	errNoDoctorAccount      = errors.New("no doctor account uses this email")
+)
+
+// OIDCProvidersHandler lists the identity providers a login page can offer.
+func OIDCProvidersHandler(ctx *gin.Context, providers *oidc.Registry) {
+	items := make([]gin.H, 0, len(providers.Names()))
+	for _, name := range providers.Names() {
+		provider, _ := providers.Get(name)
+		roles := []string{}
+		for _, role := range []string{middleware.RoleUser, middleware.RoleDoctor} {
+			if provider.AllowsRole(role) {
+				roles = append(roles, role)
+			}
+		}
+		items = append(items, gin.H{"name": name, "roles": roles})
+	}
+	ctx.JSON(http.StatusOK, gin.H{"providers": items})
+}
+
+// OIDCStartHandler begins sign-in with an identity provider. It stores a
+// fresh state, nonce and PKCE verifier, binds the state to the browser with a
+// cookie and redirects to the provider. ?role=doctor signs in to a doctor
+// account, the default is a patient account.
+func OIDCStartHandler(ctx *gin.Context, queries *repository.Queries, providers *oidc.Registry, providerName string) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	provider, ok := providers.Get(providerName)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "unknown identity provider"})
+		return
+	}
+
+	role := ctx.DefaultQuery("role", middleware.RoleUser)
+	if role != middleware.RoleUser && role != middleware.RoleDoctor {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "role must be user or doctor"})
+		return
+	}
+	if !provider.AllowsRole(role) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s sign-in is not available for %s accounts", providerName, role)})
+		return
+	}
+
+	state, stateHash, err := token.GenerateOpaque()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start login"})
+		log.Printf("OIDCStartHandler: failed to generate state: %v", err)
+		return
+	}
+	nonce, _, err := token.GenerateOpaque()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start login"})
+		log.Printf("OIDCStartHandler: failed to generate nonce: %v", err)
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(dbCtx, state, nonce, verifier)
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable"})
+		log.Printf("OIDCStartHandler: %v", err)
+		return
+	}
+
+	err = queries.CreateOAuthState(dbCtx, repository.CreateOAuthStateParams{
+		Provider:     providerName,
+		Role:         role,
+		StateHash:    stateHash,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(oauthStateTTL), Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start login"})
+		log.Printf("OIDCStartHandler: failed to store state: %v", err)
+		return
+	}
+
+	if err := queries.DeleteExpiredOAuthStates(dbCtx); err != nil {
+		log.Printf("OIDCStartHandler: failed to prune states: %v", err)
+	}
+
+	setOAuthStateCookie(ctx, state, int(oauthStateTTL.Seconds()))
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallbackHandler is the redirect target of OIDCStartHandler. The state
+// has to match the cookie and a stored, unexpired start request; the ID token
+// is verified locally. An identity whose verified email belongs to an
+// existing account is linked to that account.
+func OIDCCallbackHandler(ctx *gin.Context, queries *repository.Queries, providers *oidc.Registry, providerName string) {
+	log.Printf("%s login callback received", providerName)
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	provider, ok := providers.Get(providerName)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "unknown identity provider"})
+		return
+	}
+
+	if oauthErr := ctx.Query("error"); oauthErr != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": providerName + " login failed: " + oauthErr})
+		return
+	}
+
+	state := ctx.Query("state")
+	cookieState, _ := ctx.Cookie(oauthStateCookie)
+	setOAuthStateCookie(ctx, "", -1)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid oauth state"})
+		return
+	}
+
+	// Deleting the row makes the state single use.
+	stored, err := queries.ConsumeOAuthState(dbCtx, token.Hash(state))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid oauth state"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("OIDCCallbackHandler: failed to look up state: %v", err)
+		return
+	}
+	if stored.Provider != providerName || time.Now().After(stored.ExpiresAt.Time) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid oauth state"})
+		return
+	}
+
+	code := ctx.Query("code")
+	if code == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "authorization code missing"})
+		return
+	}
+
+	exchangeCtx, cancelExchange := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelExchange()
+	identity, err := provider.Exchange(exchangeCtx, code, stored.CodeVerifier, stored.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid id token"})
+		} else {
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": "failed to exchange authorization code"})
+		}
+		log.Printf("OIDCCallbackHandler: %v", err)
+		return
+	}
+
+	accountID, created, err := resolveIdentity(dbCtx, queries, providerName, stored.Role, identity)
+	if err != nil {
+		switch {
+		case errors.Is(err, errIdentityRoleMismatch), errors.Is(err, errIdentityConflict):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, errIdentityUnverified):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, errNoDoctorAccount):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no doctor account uses this email, register first"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sign in"})
+			log.Printf("OIDCCallbackHandler: %v", err)
+		}
+		return
+	}
+
+	if stored.Role == middleware.RoleDoctor {
+		oidcDoctorLogin(ctx, dbCtx, queries, accountID)
+		return
+	}
+	oidcUserLogin(ctx, dbCtx, queries, accountID, created)
+}
+
+func oidcUserLogin(ctx *gin.Context, dbCtx context.Context, queries *repository.Queries, userID pgtype.UUID, created bool) {
+	user, err := queries.GetUserProfileByID(dbCtx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("oidcUserLogin: failed to load user: %v", err)
+		return
+	}
+
+	if user.SuspendedAt.Valid {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		return
+	}
+
+	token, refreshToken, err := issueTokens(ctx, queries, user.ID, user.Email, middleware.RoleUser)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		log.Printf("oidcUserLogin: failed to generate token: %v", err)
+		return
+	}
+
+	message := "Login successful"
+	if created {
+		message = "new user login successful"
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       message,
+		"token":         token,
+		"refresh_token": refreshToken,
+		"user": gin.H{
+			"id":    user.ID,
+			"email": user.Email,
+			"name":  user.Name,
+		},
+	})
+}
+
+// oidcDoctorLogin applies the same checks as the password login, including
+// the two-factor step.
+func oidcDoctorLogin(ctx *gin.Context, dbCtx context.Context, queries *repository.Queries, doctorID pgtype.UUID) {
+	doctor, err := queries.GetDoctorByID(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("oidcDoctorLogin: failed to load doctor: %v", err)
+		return
+	}
+
+	if doctor.SuspendedAt.Valid {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		return
+	}
+
+	if doctor.MfaEnabledAt.Valid {
+		respondMFAChallenge(ctx, dbCtx, queries, doctor.ID)
+		return
+	}
+
+	token, refreshToken, err := issueTokens(ctx, queries, doctor.ID, *doctor.Email, middleware.RoleDoctor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		log.Printf("oidcDoctorLogin: failed to generate token: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":                 "Doctor login successful",
+		"token":                   token,
+		"refresh_token":           refreshToken,
+		"mfa_enrollment_required": doctor.MfaRequired,
+		"doctor":                  doctorProfile(doctor),
+	})
+}
+
+// resolveIdentity finds the account for an external identity: by provider
+// and subject first, then by verified email (linking the identity), and for
+// patients otherwise creates a new account. Doctors have to register first.
+func resolveIdentity(ctx context.Context, queries *repository.Queries, providerName, role string, identity oidc.Identity) (pgtype.UUID, bool, error) {
+	linked, err := queries.GetUserIdentity(ctx, repository.GetUserIdentityParams{Provider: providerName, Subject: identity.Subject})
+	if err == nil {
+		if linked.Role != role {
+			return pgtype.UUID{}, false, errIdentityRoleMismatch
+		}
+		if err := queries.TouchUserIdentity(ctx, linked.ID); err != nil {
+			log.Printf("resolveIdentity: failed to record login: %v", err)
+		}
+		return linked.AccountID, false, nil
+	}
+	if err != pgx.ErrNoRows {
+		return pgtype.UUID{}, false, err
+	}
+
+	if !identity.EmailVerified || identity.Email == "" {
+		return pgtype.UUID{}, false, errIdentityUnverified
+	}
+
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return pgtype.UUID{}, false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := queries.WithTx(tx)
+
+	var accountID pgtype.UUID
+	created := false
+	if role == middleware.RoleDoctor {
+		doctor, err := qtx.GetDoctorByEmail(ctx, &identity.Email)
+		if err == pgx.ErrNoRows {
+			return pgtype.UUID{}, false, errNoDoctorAccount
+		}
+		if err != nil {
+			return pgtype.UUID{}, false, err
+		}
+		accountID = doctor.ID
+		err = qtx.MarkDoctorEmailVerified(ctx, accountID)
+		if err != nil {
+			return pgtype.UUID{}, false, err
+		}
+	} else {
+		user, err := qtx.GetUserByEmail(ctx, identity.Email)
+		switch {
+		case err == nil:
+			accountID = user.ID
+			if err := qtx.MarkUserEmailVerified(ctx, accountID); err != nil {
+				return pgtype.UUID{}, false, err
+			}
+		case err == pgx.ErrNoRows:
+			newuser, err := qtx.CreateUserWithIdentity(ctx, repository.CreateUserWithIdentityParams{
+				Email: identity.Email,
+				Name:  &identity.Name,
+			})
+			if err != nil {
+				return pgtype.UUID{}, false, err
+			}
+			accountID = newuser.ID
+			created = true
+		default:
+			return pgtype.UUID{}, false, err
+		}
+	}
+
+	// One identity per provider and account, so a second account at the
+	// same provider cannot take over by sharing an email.
+	_, err = qtx.GetUserIdentityByAccount(ctx, repository.GetUserIdentityByAccountParams{AccountID: accountID, Provider: providerName})
+	if err == nil {
+		return pgtype.UUID{}, false, errIdentityConflict
+	}
+	if err != pgx.ErrNoRows {
+		return pgtype.UUID{}, false, err
+	}
+
+	inserted, err := qtx.CreateUserIdentity(ctx, repository.CreateUserIdentityParams{
+		Provider:  providerName,
+		Subject:   identity.Subject,
+		AccountID: accountID,
+		Role:      role,
+		Email:     &identity.Email,
+	})
+	if err != nil {
+		return pgtype.UUID{}, false, err
+	}
+	if inserted == 0 {
+		return pgtype.UUID{}, false, errIdentityConflict
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return pgtype.UUID{}, false, err
+	}
+
+	if !created {
+		log.Printf("resolveIdentity: linked %s identity to existing %s %s", providerName, role, accountID.String())
+	}
+	return accountID, created, nil
+}
+
+// ListIdentitiesHandler lists the external identities linked to the
+// caller's account.
+func ListIdentitiesHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	identities, err := queries.ListUserIdentitiesByAccount(dbCtx, accountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ListIdentitiesHandler: failed to list identities: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(identities))
+	for i, identity := range identities {
+		items[i] = gin.H{
+			"id":            identity.ID,
+			"provider":      identity.Provider,
+			"email":         identity.Email,
+			"last_login_at": identity.LastLoginAt,
+			"created_at":    identity.CreatedAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"identities": items})
+}
+
+// UnlinkIdentityHandler removes a linked identity, unless it is the only way
+// left to sign in to the account.
+func UnlinkIdentityHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("identityId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid identityId"})
+		return
+	}
+	identityID := pgtype.UUID{Bytes: id, Valid: true}
+
+	identities, err := queries.ListUserIdentitiesByAccount(dbCtx, accountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UnlinkIdentityHandler: failed to list identities: %v", err)
+		return
+	}
+
+	// Only patients can be created through a provider without a password;
+	// doctors always register with one.
+	if ctx.GetString("role") == middleware.RoleUser && len(identities) == 1 {
+		user, err := queries.GetUserProfileByID(dbCtx, accountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			log.Printf("UnlinkIdentityHandler: failed to load user: %v", err)
+			return
+		}
+		if user.PasswordHash == nil {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "set a password before removing your only sign-in method"})
+			return
+		}
+	}
+
+	deleted, err := queries.DeleteUserIdentity(dbCtx, repository.DeleteUserIdentityParams{ID: identityID, AccountID: accountID})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink identity"})
+		log.Printf("UnlinkIdentityHandler: failed to delete identity: %v", err)
+		return
+	}
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Identity not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Identity unlinked"})
+}
+
+// setOAuthStateCookie scopes the cookie to /auth and keeps it out of reach of
+// scripts. SameSite=Lax lets it ride along on the redirect back from the
+// provider.
+func setOAuthStateCookie(ctx *gin.Context, value string, maxAge int) {
+	secure := ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oauthStateCookie, value, maxAge, "/auth", "", secure, true)
+}
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/oidc"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/testdb"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/oauth2"
+)
+
+// stubProvider stands in for an identity provider. It remembers the nonce
+// and PKCE challenge of each start request and checks them when a code is
+// exchanged, the way a provider and Client.Verify do.
+type stubProvider struct {
+	mu       sync.Mutex
+	requests map[string]stubCode // by state
+	codes    map[string]stubCode
+}
+
+type stubCode struct {
+	identity  oidc.Identity
+	nonce     string
+	challenge string
+}
+
+func newStubProvider() *stubProvider {
+	return &stubProvider{requests: map[string]stubCode{}, codes: map[string]stubCode{}}
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) AllowsRole(role string) bool { return true }
+
+func (p *stubProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[state] = stubCode{nonce: nonce, challenge: oauth2.S256ChallengeFromVerifier(verifier)}
+	return "https://idp.example.test/authorize?state=" + url.QueryEscape(state), nil
+}
+
+func (p *stubProvider) Exchange(ctx context.Context, code, verifier, nonce string) (oidc.Identity, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	issued, ok := p.codes[code]
+	delete(p.codes, code)
+	switch {
+	case !ok:
+		return oidc.Identity{}, errors.New("token endpoint: invalid_grant")
+	case oauth2.S256ChallengeFromVerifier(verifier) != issued.challenge:
+		return oidc.Identity{}, errors.New("token endpoint: invalid_grant: code_verifier does not match")
+	case nonce != issued.nonce:
+		return oidc.Identity{}, fmt.Errorf("%w: nonce mismatch", oidc.ErrInvalidIDToken)
+	}
+	return issued.identity, nil
+}
+
+// signIn plays the user signing in at the provider after the start request
+// with state, and returns the code the provider redirects back with.
+func (p *stubProvider) signIn(t *testing.T, state string, identity oidc.Identity) string {
+	t.Helper()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	issued, ok := p.requests[state]
+	if !ok {
+		t.Fatalf("no start request for state %q", state)
+	}
+	issued.identity = identity
+	code := uuid.NewString()
+	p.codes[code] = issued
+	return code
+}
+
+type oidcTest struct {
+	queries  *repository.Queries
+	provider *stubProvider
+	router   *gin.Engine
+}
+
+func newOIDCTest(t *testing.T) *oidcTest {
+	queries := testdb.Connect(t)
+	t.Setenv("JWT_SECRET", "oidc-handler-test")
+	gin.SetMode(gin.TestMode)
+
+	provider := newStubProvider()
+	providers := oidc.NewRegistry(provider)
+	router := gin.New()
+	router.GET("/auth/oidc/:provider/start", func(ctx *gin.Context) {
+		OIDCStartHandler(ctx, queries, providers, ctx.Param("provider"))
+	})
+	router.GET("/auth/oidc/:provider/callback", func(ctx *gin.Context) {
+		OIDCCallbackHandler(ctx, queries, providers, ctx.Param("provider"))
+	})
+	return &oidcTest{queries: queries, provider: provider, router: router}
+}
+
+// start begins a patient sign-in and returns its state and the state cookie.
+func (o *oidcTest) start(t *testing.T) (string, *http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	o.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/oidc/stub/start?role=user", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("start: got status %d: %s", w.Code, w.Body)
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("start: bad redirect: %v", err)
+	}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == oauthStateCookie {
+			return location.Query().Get("state"), cookie
+		}
+	}
+	t.Fatal("start: no state cookie")
+	return "", nil
+}
+
+func (o *oidcTest) callback(state, code string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	query := url.Values{"state": {state}, "code": {code}}
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/stub/callback?"+query.Encode(), nil)
+	if cookie != nil {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	w := httptest.NewRecorder()
+	o.router.ServeHTTP(w, req)
+	return w
+}
+
+func newIdentity(email string, verified bool) oidc.Identity {
+	return oidc.Identity{Subject: uuid.NewString(), Email: email, EmailVerified: verified, Name: "Stub User"}
+}
+
+func TestOIDCCallbackRejectsMismatchedState(t *testing.T) {
+	o := newOIDCTest(t)
+	state, cookie := o.start(t)
+	identity := newIdentity(testdb.Email("oidc-state"), true)
+
+	// A state that is not the one bound to the browser.
+	if w := o.callback("forged", o.provider.signIn(t, state, identity), cookie); w.Code != http.StatusBadRequest {
+		t.Errorf("state not matching the cookie: got status %d, want 400", w.Code)
+	}
+	// A state the cookie agrees with, but that was never started.
+	forged := &http.Cookie{Name: oauthStateCookie, Value: "forged"}
+	if w := o.callback("forged", o.provider.signIn(t, state, identity), forged); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown state: got status %d, want 400", w.Code)
+	}
+	// No cookie at all.
+	if w := o.callback(state, o.provider.signIn(t, state, identity), nil); w.Code != http.StatusBadRequest {
+		t.Errorf("missing cookie: got status %d, want 400", w.Code)
+	}
+
+	if w := o.callback(state, o.provider.signIn(t, state, identity), cookie); w.Code != http.StatusOK {
+		t.Fatalf("matching state: got status %d: %s", w.Code, w.Body)
+	}
+	// The state is single use.
+	if w := o.callback(state, o.provider.signIn(t, state, identity), cookie); w.Code != http.StatusBadRequest {
+		t.Errorf("replayed state: got status %d, want 400", w.Code)
+	}
+}
+
+func TestOIDCCallbackRejectsMismatchedNonce(t *testing.T) {
+	o := newOIDCTest(t)
+	state, cookie := o.start(t)
+	identity := newIdentity(testdb.Email("oidc-nonce"), true)
+
+	// An ID token minted for another sign-in carries that sign-in's nonce.
+	code := o.provider.signIn(t, state, identity)
+	o.provider.codes[code] = stubCode{identity: identity, nonce: "another-sign-in", challenge: o.provider.codes[code].challenge}
+
+	if w := o.callback(state, code, cookie); w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401: %s", w.Code, w.Body)
+	}
+	if _, err := o.queries.GetUserByEmail(context.Background(), identity.Email); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("an account was created for the rejected sign-in: %v", err)
+	}
+}
+
+func TestOIDCCallbackRejectsMismatchedCodeVerifier(t *testing.T) {
+	o := newOIDCTest(t)
+	state, cookie := o.start(t)
+	identity := newIdentity(testdb.Email("oidc-pkce"), true)
+
+	// A code obtained by a different start request has another challenge.
+	code := o.provider.signIn(t, state, identity)
+	o.provider.codes[code] = stubCode{
+		identity:  identity,
+		nonce:     o.provider.codes[code].nonce,
+		challenge: oauth2.S256ChallengeFromVerifier(oauth2.GenerateVerifier()),
+	}
+
+	if w := o.callback(state, code, cookie); w.Code != http.StatusBadGateway {
+		t.Errorf("got status %d, want 502: %s", w.Code, w.Body)
+	}
+	if _, err := o.queries.GetUserByEmail(context.Background(), identity.Email); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("an account was created for the rejected sign-in: %v", err)
+	}
+}
+
+func TestOIDCCallbackLinksExistingEmail(t *testing.T) {
+	o := newOIDCTest(t)
+	ctx := context.Background()
+	user := testdb.NewUser(t, o.queries)
+
+	// An unverified email does not get to take over the account.
+	state, cookie := o.start(t)
+	unverified := newIdentity(user.Email, false)
+	if w := o.callback(state, o.provider.signIn(t, state, unverified), cookie); w.Code != http.StatusForbidden {
+		t.Errorf("unverified email: got status %d, want 403: %s", w.Code, w.Body)
+	}
+	if _, err := o.queries.GetUserIdentity(ctx, repository.GetUserIdentityParams{Provider: "stub", Subject: unverified.Subject}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("unverified identity was linked: %v", err)
+	}
+
+	state, cookie = o.start(t)
+	identity := newIdentity(user.Email, true)
+	w := o.callback(state, o.provider.signIn(t, state, identity), cookie)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+
+	var body struct {
+		Message string `json:"message"`
+		Token   string `json:"token"`
+		User    struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("bad response: %v", err)
+	}
+	if body.User.ID != user.ID.String() || body.Message != "Login successful" || body.Token == "" {
+		t.Errorf("got %+v, want a login to the existing user %s", body, user.ID.String())
+	}
+
+	linked, err := o.queries.GetUserIdentity(ctx, repository.GetUserIdentityParams{Provider: "stub", Subject: identity.Subject})
+	if err != nil {
+		t.Fatalf("identity was not linked: %v", err)
+	}
+	if linked.AccountID != user.ID {
+		t.Errorf("identity linked to %s, want %s", linked.AccountID.String(), user.ID.String())
+	}
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const passwordResetTTL = time.Hour
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=user doctor"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func ForgotPasswordHandler(ctx *gin.Context, queries *repository.Queries, mail mailer.Mailer) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var req ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID, err := accountIDByEmail(dbCtx, queries, req.Email, req.Role)
+	if err != nil && err != pgx.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ForgotPasswordHandler: failed to look up account: %v", err)
+		return
+	}
+
+	// Unknown emails get the same answer so the endpoint cannot be used to
+	// find out who is registered.
+	if err == nil {
+		if err := sendPasswordReset(dbCtx, queries, mail, accountID, req.Email, req.Role); err != nil {
+			log.Printf("ForgotPasswordHandler: failed to send reset email: %v", err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "If the account exists, a password reset email has been sent"})
+}
+
+func ResetPasswordHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stored, err := queries.GetPasswordResetTokenByHash(dbCtx, token.Hash(req.Token))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired reset token"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetPasswordHandler: failed to look up reset token: %v", err)
+		return
+	}
+
+	if stored.UsedAt.Valid || time.Now().After(stored.ExpiresAt.Time) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired reset token"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	hashedPasswordStr := string(hashedPassword)
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetPasswordHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	used, err := qtx.MarkPasswordResetTokenUsed(dbCtx, stored.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetPasswordHandler: failed to mark token used: %v", err)
+		return
+	}
+	if used == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired reset token"})
+		return
+	}
+
+	switch stored.Role {
+	case middleware.RoleUser:
+		user, err := qtx.GetUserProfileByID(dbCtx, stored.AccountID)
+		if err == nil {
+			err = qtx.UpdateUserPassword(dbCtx, repository.UpdateUserPasswordParams{
+				Email:        user.Email,
+				PasswordHash: &hashedPasswordStr,
+			})
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+			log.Printf("ResetPasswordHandler: failed to update user password: %v", err)
+			return
+		}
+	case middleware.RoleDoctor:
+		doctor, err := qtx.GetDoctorByID(dbCtx, stored.AccountID)
+		if err == nil {
+			err = qtx.UpdateDoctorPassword(dbCtx, repository.UpdateDoctorPasswordParams{
+				Email:        doctor.Email,
+				PasswordHash: &hashedPasswordStr,
+			})
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+			log.Printf("ResetPasswordHandler: failed to update doctor password: %v", err)
+			return
+		}
+	}
+
+	// Outstanding reset links and every existing login stop working.
+	if err := qtx.InvalidatePasswordResetTokens(dbCtx, stored.AccountID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetPasswordHandler: failed to invalidate reset tokens: %v", err)
+		return
+	}
+	if err := qtx.RevokeRefreshTokensBySubject(dbCtx, stored.AccountID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetPasswordHandler: failed to revoke refresh tokens: %v", err)
+		return
+	}
+	if err := qtx.RevokeSessionsBySubject(dbCtx, stored.AccountID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetPasswordHandler: failed to revoke sessions: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ResetPasswordHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
+func accountIDByEmail(ctx context.Context, queries *repository.Queries, email, role string) (pgtype.UUID, error) {
+	if role == middleware.RoleDoctor {
+		doctor, err := queries.GetDoctorByEmail(ctx, &email)
+		return doctor.ID, err
+	}
+	user, err := queries.GetUserByEmail(ctx, email)
+	return user.ID, err
+}
+
+func sendPasswordReset(ctx context.Context, queries *repository.Queries, mail mailer.Mailer, accountID pgtype.UUID, email, role string) error {
+	resetToken, resetHash, err := token.GenerateOpaque()
+	if err != nil {
+		return err
+	}
+
+	_, err = queries.CreatePasswordResetToken(ctx, repository.CreatePasswordResetTokenParams{
+		AccountID: accountID,
+		Role:      role,
+		TokenHash: resetHash,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(passwordResetTTL), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Reset your Health-Sync password",
+		Body: fmt.Sprintf("We received a request to reset your Health-Sync password.\n\n%s\n\n"+
+			"The link expires in %d minutes. If you did not ask for a reset you can ignore this email.\n",
+			linkWithToken(os.Getenv("PASSWORD_RESET_URL"), resetToken), int(passwordResetTTL.Minutes())),
+	})
+}
+
+// linkWithToken appends the token to the frontend URL, or returns the bare
+// token when no URL is configured.
+func linkWithToken(baseURL, value string) string {
+	if baseURL == "" {
+		return "Token: " + value
+	}
+	return baseURL + "?token=" + url.QueryEscape(value)
+}
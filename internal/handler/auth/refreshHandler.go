@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// issueTokens signs an access token and starts a new refresh token family
+// for a fresh login. The family doubles as the session of the device that
+// signed in, described by the X-Device-Name and X-Device-Platform headers.
+func issueTokens(ctx *gin.Context, queries *repository.Queries, subjectID pgtype.UUID, email, role string) (string, string, error) {
+	familyID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	err := queries.CreateSession(ctx, repository.CreateSessionParams{
+		ID:         familyID,
+		SubjectID:  subjectID,
+		Role:       role,
+		DeviceName: deviceHeader(ctx, "X-Device-Name"),
+		Platform:   deviceHeader(ctx, "X-Device-Platform"),
+		IpAddress:  nullableString(ctx.ClientIP()),
+		UserAgent:  deviceHeader(ctx, "User-Agent"),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return issueTokensInFamily(ctx, queries, familyID, subjectID, email, role)
+}
+
+// issueTokensInFamily signs an access token and stores a new refresh token
+// in an existing family.
+func issueTokensInFamily(ctx context.Context, queries *repository.Queries, familyID, subjectID pgtype.UUID, email, role string) (string, string, error) {
+	accessToken, err := token.Generate(subjectID.String(), email, role, familyID.String())
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, refreshHash, err := token.GenerateOpaque()
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = queries.CreateRefreshToken(ctx, repository.CreateRefreshTokenParams{
+		FamilyID:  familyID,
+		SubjectID: subjectID,
+		Role:      role,
+		Email:     email,
+		TokenHash: refreshHash,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(token.RefreshTokenTTL), Valid: true},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+func RefreshTokenHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var req RefreshRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	stored, err := queries.GetRefreshTokenByHash(dbCtx, token.Hash(req.RefreshToken))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RefreshTokenHandler: failed to look up refresh token: %v", err)
+		return
+	}
+
+	if stored.RevokedAt.Valid {
+		// A rotated token was presented again, so it has leaked: cut off
+		// every token descended from the same login.
+		log.Printf("RefreshTokenHandler: reuse of revoked refresh token, revoking family %s", stored.FamilyID.String())
+		if err := queries.RevokeRefreshTokenFamily(dbCtx, stored.FamilyID); err != nil {
+			log.Printf("RefreshTokenHandler: failed to revoke family: %v", err)
+		}
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	if time.Now().After(stored.ExpiresAt.Time) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token expired"})
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RefreshTokenHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	revoked, err := qtx.RevokeRefreshToken(dbCtx, stored.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RefreshTokenHandler: failed to revoke refresh token: %v", err)
+		return
+	}
+
+	if revoked == 0 {
+		// Another request rotated this token first; treat it as reuse.
+		log.Printf("RefreshTokenHandler: concurrent reuse of refresh token, revoking family %s", stored.FamilyID.String())
+		if err := qtx.RevokeRefreshTokenFamily(dbCtx, stored.FamilyID); err == nil {
+			tx.Commit(dbCtx)
+		}
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	err = qtx.TouchSession(dbCtx, repository.TouchSessionParams{
+		ID:        stored.FamilyID,
+		IpAddress: nullableString(ctx.ClientIP()),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RefreshTokenHandler: failed to update session: %v", err)
+		return
+	}
+
+	accessToken, refreshToken, err := issueTokensInFamily(dbCtx, qtx, stored.FamilyID, stored.SubjectID, stored.Email, stored.Role)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		log.Printf("RefreshTokenHandler: failed to issue tokens: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RefreshTokenHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+// LogoutHandler revokes the access token used for the request and, when a
+// refresh token is supplied, every refresh token of the same login.
+func LogoutHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var req LogoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if req.RefreshToken != "" {
+		stored, err := queries.GetRefreshTokenByHash(dbCtx, token.Hash(req.RefreshToken))
+		if err != nil && err != pgx.ErrNoRows {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			log.Printf("LogoutHandler: failed to look up refresh token: %v", err)
+			return
+		}
+
+		if err == nil && middleware.IsCaller(ctx, stored.SubjectID) {
+			if err := queries.RevokeRefreshTokenFamily(dbCtx, stored.FamilyID); err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+				log.Printf("LogoutHandler: failed to revoke family: %v", err)
+				return
+			}
+		}
+	}
+
+	// Signing out ends the device session: its refresh tokens stop working
+	// and it no longer receives push notifications.
+	if sessionID, ok := currentSessionID(ctx); ok {
+		subjectID, _ := uuid.Parse(ctx.GetString("user_id"))
+		_, err := queries.RevokeSession(dbCtx, repository.RevokeSessionParams{
+			ID:        sessionID,
+			SubjectID: pgtype.UUID{Bytes: subjectID, Valid: true},
+		})
+		if err == nil {
+			err = queries.RevokeRefreshTokenFamily(dbCtx, sessionID)
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+			log.Printf("LogoutHandler: failed to revoke session: %v", err)
+			return
+		}
+	}
+
+	err := queries.RevokeAccessToken(dbCtx, repository.RevokeAccessTokenParams{
+		Jti:       ctx.GetString("jti"),
+		ExpiresAt: pgtype.Timestamptz{Time: ctx.GetTime("token_expires_at"), Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		log.Printf("LogoutHandler: failed to revoke access token: %v", err)
+		return
+	}
+
+	if err := queries.DeleteExpiredRevokedAccessTokens(dbCtx); err != nil {
+		log.Printf("LogoutHandler: failed to prune revoked access tokens: %v", err)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// JWKSHandler publishes the public keys used to sign access tokens so other
+// services can verify them.
+func JWKSHandler(ctx *gin.Context) {
+	keys, err := token.Keys()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "signing keys unavailable"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys.JWKS())
+}
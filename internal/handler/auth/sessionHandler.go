@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxDeviceFieldLength bounds the client supplied device description.
+const maxDeviceFieldLength = 200
+
+type UpdateDeviceRequest struct {
+	DeviceName *string `json:"device_name"`
+	Platform   *string `json:"platform"`
+	FCMToken   *string `json:"fcm_token"`
+}
+
+// deviceHeader reads a request header describing the device, returning nil
+// when it is absent.
+func deviceHeader(ctx *gin.Context, name string) *string {
+	return nullableString(ctx.GetHeader(name))
+}
+
+// nullableString trims s and returns nil when nothing is left.
+func nullableString(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if len(s) > maxDeviceFieldLength {
+		s = s[:maxDeviceFieldLength]
+	}
+	return &s
+}
+
+// currentSessionID returns the session the access token was issued for.
+// Tokens issued before sessions were recorded have none.
+func currentSessionID(ctx *gin.Context) (pgtype.UUID, bool) {
+	id, err := uuid.Parse(ctx.GetString("session_id"))
+	if err != nil {
+		return pgtype.UUID{}, false
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, true
+}
+
+// ListSessionsHandler lists the devices currently signed in to the caller's
+// account, most recently used first.
+func ListSessionsHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subjectID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+	current, _ := currentSessionID(ctx)
+
+	sessions, err := queries.ListActiveSessionsBySubject(dbCtx, subjectID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ListSessionsHandler: failed to list sessions: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(sessions))
+	for i, session := range sessions {
+		items[i] = gin.H{
+			"id":                 session.ID,
+			"device_name":        session.DeviceName,
+			"platform":           session.Platform,
+			"ip_address":         session.IpAddress,
+			"user_agent":         session.UserAgent,
+			"push_notifications": session.FcmToken != nil,
+			"created_at":         session.CreatedAt,
+			"last_seen_at":       session.LastSeenAt,
+			"current":            session.ID == current,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"sessions": items})
+}
+
+// RevokeSessionHandler signs one of the caller's devices out. Its refresh
+// tokens are revoked and its access tokens are rejected from now on.
+func RevokeSessionHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subjectID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("sessionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sessionId"})
+		return
+	}
+	sessionID := pgtype.UUID{Bytes: id, Valid: true}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RevokeSessionHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	revoked, err := qtx.RevokeSession(dbCtx, repository.RevokeSessionParams{ID: sessionID, SubjectID: subjectID})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		log.Printf("RevokeSessionHandler: failed to revoke session: %v", err)
+		return
+	}
+	if revoked == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := qtx.RevokeRefreshTokenFamily(dbCtx, sessionID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		log.Printf("RevokeSessionHandler: failed to revoke refresh tokens: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RevokeSessionHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Device signed out"})
+}
+
+// UpdateCurrentDeviceHandler lets the app describe the device it runs on and
+// register its FCM token for push notifications. Fields left out keep their
+// value; an empty fcm_token turns push notifications off for the device.
+func UpdateCurrentDeviceHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sessionID, ok := currentSessionID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "sign in again to register this device"})
+		return
+	}
+
+	var req UpdateDeviceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	params := repository.UpdateSessionDeviceParams{ID: sessionID}
+	if req.DeviceName != nil {
+		params.DeviceName = nullableString(*req.DeviceName)
+	}
+	if req.Platform != nil {
+		params.Platform = nullableString(strings.ToLower(*req.Platform))
+	}
+	clearToken := false
+	if req.FCMToken != nil {
+		fcmToken := strings.TrimSpace(*req.FCMToken)
+		if fcmToken == "" {
+			clearToken = true
+		} else {
+			params.FcmToken = &fcmToken
+		}
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateCurrentDeviceHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	updated, err := qtx.UpdateSessionDevice(dbCtx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update device"})
+		log.Printf("UpdateCurrentDeviceHandler: failed to update session: %v", err)
+		return
+	}
+	if updated == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if clearToken {
+		session, err := qtx.GetSession(dbCtx, sessionID)
+		if err == nil && session.FcmToken != nil {
+			err = qtx.ClearFCMToken(dbCtx, session.FcmToken)
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update device"})
+			log.Printf("UpdateCurrentDeviceHandler: failed to clear FCM token: %v", err)
+			return
+		}
+	} else if params.FcmToken != nil {
+		// A push token belongs to one app install. When it shows up on a new
+		// session the device signed in again, so older sessions must not
+		// keep sending to it.
+		err := qtx.ReleaseFCMToken(dbCtx, repository.ReleaseFCMTokenParams{
+			FcmToken:      params.FcmToken,
+			KeepSessionID: sessionID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update device"})
+			log.Printf("UpdateCurrentDeviceHandler: failed to release FCM token: %v", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateCurrentDeviceHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Device updated"})
+}
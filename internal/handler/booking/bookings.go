@@ -1,21 +1,33 @@
 package booking
 
 import (
+	"context"
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"time"
 
+	"github.com/SRIRAMGJ007/Health-Sync/internal/bookingstatus"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type CreateBookingRequest struct {
-	AvailabilityID pgtype.UUID `json:"availability_id" binding:"required"`
+	StartTime string `json:"start_time"` // appointment within the window on the doctor's clock, earliest free when empty
+	StartsAt  string `json:"starts_at"`  // or the appointment's start as RFC 3339
 }
 
 type UpdateBookingStatusRequest struct {
 	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason"`
 }
 
 type DoctorResponse struct {
@@ -28,26 +40,55 @@ type DoctorResponse struct {
 }
 
 type BookingResponse struct {
-	ID               pgtype.UUID    `json:"id"`
-	UserID           pgtype.UUID    `json:"user_id"`
-	DoctorID         pgtype.UUID    `json:"doctor_id"`
-	AvailabilityID   pgtype.UUID    `json:"availability_id"`
-	BookingDate      string         `json:"booking_date"`
-	BookingStartTime string         `json:"booking_start_time"`
-	BookingEndTime   string         `json:"booking_end_time"`
-	Status           string         `json:"status"`
-	Doctor           DoctorResponse `json:"doctor"`
+	ID                  pgtype.UUID        `json:"id"`
+	UserID              pgtype.UUID        `json:"user_id"`
+	DoctorID            pgtype.UUID        `json:"doctor_id"`
+	AvailabilityID      pgtype.UUID        `json:"availability_id"`
+	BookingDate         string             `json:"booking_date"`
+	BookingStartTime    string             `json:"booking_start_time"`
+	BookingEndTime      string             `json:"booking_end_time"`
+	StartsAt            *time.Time         `json:"starts_at"`
+	EndsAt              *time.Time         `json:"ends_at"`
+	Timezone            string             `json:"timezone"`
+	Status              string             `json:"status"`
+	QueuePosition       *int32             `json:"queue_position,omitempty"`
+	RescheduledFrom     pgtype.UUID        `json:"rescheduled_from"`
+	CancelledAt         pgtype.Timestamptz `json:"cancelled_at"`
+	CancellationReason  *string            `json:"cancellation_reason"`
+	LateCancellationFee bool               `json:"late_cancellation_fee"`
+	Doctor              DoctorResponse     `json:"doctor"`
 }
 
 type DoctorBookingResponse struct {
 	BookingDate      pgtype.Date `json:"booking_date"`
 	BookingStartTime string      `json:"booking_start_time"`
 	BookingEndTime   string      `json:"booking_end_time"`
+	StartsAt         *time.Time  `json:"starts_at"`
+	EndsAt           *time.Time  `json:"ends_at"`
 	Status           string      `json:"status"`
 	PatientName      string      `json:"patient_name"`
 	AvailabilityID   pgtype.UUID `json:"availability_id"`
 }
 
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint
+// violation. The bookings_within_capacity trigger raises one for an
+// appointment that is already full.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isDuplicateBooking reports whether err refused a patient's second active
+// booking of the same appointment.
+func isDuplicateBooking(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "idx_bookings_active_patient"
+}
+
+// CreateBookingHandler books an appointment in an availability window for
+// the user, or a place in its queue. Reserving the appointment and inserting
+// the booking happen in one transaction, and a request for an appointment
+// that is fully booked gets 409 Conflict.
 func CreateBookingHandler(ctx *gin.Context, queries *repository.Queries) {
 
 	userIDStr := ctx.Param("userId")
@@ -67,39 +108,78 @@ func CreateBookingHandler(ctx *gin.Context, queries *repository.Queries) {
 	}
 	parsedAvailabilityID := pgtype.UUID{Bytes: availabilityID, Valid: true}
 
-	availability, err := queries.GetDoctorAvailabilityByID(ctx, parsedAvailabilityID)
+	// The body is optional: without a start time the earliest free
+	// appointment of the window is booked.
+	var req CreateBookingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	choice, err := parseAppointmentChoice(req.StartTime, req.StartsAt)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := database.DB.Begin(dbCtx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "AvailabilityiD not found"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateBookingHandler: failed to begin transaction: %v", err)
 		return
 	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
 
-	if *availability.IsBooked {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Availability slot is already booked"})
+	res, err := reserve(dbCtx, qtx, parsedAvailabilityID, choice)
+	if err != nil {
+		reserveError(ctx, "CreateBookingHandler", err)
 		return
 	}
 
-	booking, err := queries.CreateBooking(ctx, repository.CreateBookingParams{
+	booking, err := qtx.CreateBooking(dbCtx, repository.CreateBookingParams{
 		UserID:           parsedUserID,
-		DoctorID:         availability.DoctorID,
+		DoctorID:         res.Window.DoctorID,
 		AvailabilityID:   parsedAvailabilityID,
-		BookingDate:      availability.AvailabilityDate,
-		BookingStartTime: availability.StartTime,
-		BookingEndTime:   availability.EndTime,
-		Status:           "pending",
+		BookingDate:      res.Window.AvailabilityDate,
+		BookingStartTime: res.Start,
+		BookingEndTime:   res.End,
+		Status:           bookingstatus.Pending,
+		QueuePosition:    res.QueuePosition,
+		StartsAt:         timezone.Timestamptz(res.StartsAt),
+		EndsAt:           timezone.Timestamptz(res.EndsAt),
 	})
-
+	if isDuplicateBooking(err) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "You have already booked this appointment"})
+		return
+	}
+	if isUniqueViolation(err) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Availability slot is already booked"})
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
+		log.Printf("CreateBookingHandler: failed to create booking: %v", err)
 		return
 	}
 
-	bookingStatus := true
-	err = queries.UpdateAvailabilityBookedStatus(ctx, repository.UpdateAvailabilityBookedStatusParams{
-		ID:       parsedAvailabilityID,
-		IsBooked: &bookingStatus,
+	err = qtx.CreateBookingStatusHistory(dbCtx, repository.CreateBookingStatusHistoryParams{
+		BookingID:     booking.ID,
+		ToStatus:      booking.Status,
+		ChangedBy:     parsedUserID,
+		ChangedByRole: middleware.RoleUser,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability status"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
+		log.Printf("CreateBookingHandler: failed to record booking status: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateBookingHandler: failed to commit: %v", err)
 		return
 	}
 
@@ -111,7 +191,11 @@ func CreateBookingHandler(ctx *gin.Context, queries *repository.Queries) {
 		BookingDate:      booking.BookingDate.Time.String(),
 		BookingStartTime: utils.FormatTime(booking.BookingStartTime),
 		BookingEndTime:   utils.FormatTime(booking.BookingEndTime),
+		StartsAt:         timezone.In(booking.StartsAt, res.Location),
+		EndsAt:           timezone.In(booking.EndsAt, res.Location),
+		Timezone:         res.Location.String(),
 		Status:           booking.Status,
+		QueuePosition:    booking.QueuePosition,
 	}
 
 	ctx.JSON(http.StatusCreated, resp)
@@ -130,8 +214,8 @@ func GetBookingByIDHandler(ctx *gin.Context, queries *repository.Queries) {
 	parsedBookingID := pgtype.UUID{Bytes: bookingID, Valid: true}
 
 	booking, err := queries.GetBookingByID(ctx, parsedBookingID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Booking not found"})
+	if err != nil || !middleware.IsCaller(ctx, booking.UserID) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
 		return
 	}
 
@@ -142,14 +226,21 @@ func GetBookingByIDHandler(ctx *gin.Context, queries *repository.Queries) {
 	}
 
 	resp := BookingResponse{
-		ID:               booking.ID,
-		UserID:           booking.UserID,
-		DoctorID:         booking.DoctorID,
-		AvailabilityID:   booking.AvailabilityID,
-		BookingDate:      booking.BookingDate.Time.String(),
-		BookingStartTime: utils.FormatTime(booking.BookingStartTime),
-		BookingEndTime:   utils.FormatTime(booking.BookingEndTime),
-		Status:           booking.Status,
+		ID:                  booking.ID,
+		UserID:              booking.UserID,
+		DoctorID:            booking.DoctorID,
+		AvailabilityID:      booking.AvailabilityID,
+		BookingDate:         booking.BookingDate.Time.String(),
+		BookingStartTime:    utils.FormatTime(booking.BookingStartTime),
+		BookingEndTime:      utils.FormatTime(booking.BookingEndTime),
+		StartsAt:            timezone.In(booking.StartsAt, timezone.Stored(doctor.Timezone)),
+		EndsAt:              timezone.In(booking.EndsAt, timezone.Stored(doctor.Timezone)),
+		Timezone:            doctor.Timezone,
+		Status:              booking.Status,
+		RescheduledFrom:     booking.RescheduledFrom,
+		CancelledAt:         booking.CancelledAt,
+		CancellationReason:  booking.CancellationReason,
+		LateCancellationFee: booking.LateCancellationFee,
 		Doctor: DoctorResponse{
 			Name:           doctor.Name,
 			Specialization: doctor.Specialization,
@@ -190,14 +281,21 @@ func GetBookingsByUserIDHandler(ctx *gin.Context, queries *repository.Queries) {
 		}
 
 		resp[i] = BookingResponse{
-			ID:               booking.ID,
-			UserID:           booking.UserID,
-			DoctorID:         booking.DoctorID,
-			AvailabilityID:   booking.AvailabilityID,
-			BookingDate:      booking.BookingDate.Time.String(),
-			BookingStartTime: utils.FormatTime(booking.BookingStartTime),
-			BookingEndTime:   utils.FormatTime(booking.BookingEndTime),
-			Status:           booking.Status,
+			ID:                  booking.ID,
+			UserID:              booking.UserID,
+			DoctorID:            booking.DoctorID,
+			AvailabilityID:      booking.AvailabilityID,
+			BookingDate:         booking.BookingDate.Time.String(),
+			BookingStartTime:    utils.FormatTime(booking.BookingStartTime),
+			BookingEndTime:      utils.FormatTime(booking.BookingEndTime),
+			StartsAt:            timezone.In(booking.StartsAt, timezone.Stored(doctor.Timezone)),
+			EndsAt:              timezone.In(booking.EndsAt, timezone.Stored(doctor.Timezone)),
+			Timezone:            doctor.Timezone,
+			Status:              booking.Status,
+			RescheduledFrom:     booking.RescheduledFrom,
+			CancelledAt:         booking.CancelledAt,
+			CancellationReason:  booking.CancellationReason,
+			LateCancellationFee: booking.LateCancellationFee,
 			Doctor: DoctorResponse{
 				ID:             doctor.ID.String(),
 				Name:           doctor.Name,
@@ -211,35 +309,6 @@ func GetBookingsByUserIDHandler(ctx *gin.Context, queries *repository.Queries) {
 	}
 	ctx.JSON(http.StatusOK, resp)
 
-}
-func UpdateBookingStatusHandler(ctx *gin.Context, queries *repository.Queries) {
-
-	bookingIDStr := ctx.Param("bookingId")
-
-	bookingID, err := uuid.Parse(bookingIDStr)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
-		return
-	}
-
-	parsedBookingID := pgtype.UUID{Bytes: bookingID, Valid: true}
-	var req UpdateBookingStatusRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	err = queries.UpdateBookingStatus(ctx, repository.UpdateBookingStatusParams{
-		ID:     parsedBookingID,
-		Status: req.Status,
-	})
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update booking status"})
-		return
-	}
-
-	ctx.JSON(http.StatusOK, gin.H{"message": "Booking status updated successfully"})
-
 }
 func GetBookingsByDoctorIDHandler(ctx *gin.Context, queries *repository.Queries) {
 
@@ -259,6 +328,12 @@ func GetBookingsByDoctorIDHandler(ctx *gin.Context, queries *repository.Queries)
 		return
 	}
 
+	loc, err := timezone.Doctor(ctx, queries, parsedDoctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookings"})
+		return
+	}
+
 	resp := make([]DoctorBookingResponse, len(bookings))
 	for i, booking := range bookings {
 		user, err := queries.GetUserByID(ctx, booking.UserID)
@@ -271,6 +346,8 @@ func GetBookingsByDoctorIDHandler(ctx *gin.Context, queries *repository.Queries)
 			BookingDate:      booking.BookingDate,
 			BookingStartTime: utils.FormatTime(booking.BookingStartTime),
 			BookingEndTime:   utils.FormatTime(booking.BookingEndTime),
+			StartsAt:         timezone.In(booking.StartsAt, loc),
+			EndsAt:           timezone.In(booking.EndsAt, loc),
 			Status:           booking.Status,
 			PatientName:      *user.Name,
 			AvailabilityID:   booking.AvailabilityID,
@@ -280,24 +357,3 @@ func GetBookingsByDoctorIDHandler(ctx *gin.Context, queries *repository.Queries)
 	ctx.JSON(http.StatusOK, resp)
 
 }
-func DeleteBookingHandler(ctx *gin.Context, queries *repository.Queries) {
-
-	bookingIDStr := ctx.Param("bookingId")
-
-	bookingID, err := uuid.Parse(bookingIDStr)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
-		return
-	}
-
-	parsedBookingID := pgtype.UUID{Bytes: bookingID, Valid: true}
-
-	err = queries.DeleteBooking(ctx, parsedBookingID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete booking"})
-		return
-	}
-
-	ctx.JSON(http.StatusOK, gin.H{"message": "Booking deleted successfully"})
-
-}
@@ -0,0 +1,98 @@
+package booking
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/availability"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/testdb"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// TestCreateBookingHandlerConcurrent books one single-place appointment
+// from many patients at once. Exactly one may get it.
+func TestCreateBookingHandlerConcurrent(t *testing.T) {
+	queries := testdb.Connect(t)
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	doctor := testdb.NewDoctor(t, queries)
+	loc, err := timezone.Doctor(ctx, queries, doctor.ID)
+	if err != nil {
+		t.Fatalf("failed to load doctor timezone: %v", err)
+	}
+	date := pgtype.Date{Time: availability.Date(time.Now().In(loc).AddDate(0, 0, 1)), Valid: true}
+	start := availability.PgTime(10 * time.Hour)
+	end := availability.PgTime(10*time.Hour + 30*time.Minute)
+	slot, err := queries.CreateDoctorAvailability(ctx, repository.CreateDoctorAvailabilityParams{
+		DoctorID:         doctor.ID,
+		AvailabilityDate: date,
+		StartTime:        start,
+		EndTime:          end,
+		Capacity:         1,
+		BookingMode:      availability.ModeSlots,
+		StartsAt:         timezone.Timestamptz(timezone.At(date, start, loc)),
+		EndsAt:           timezone.Timestamptz(timezone.At(date, end, loc)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create availability: %v", err)
+	}
+
+	const patients = 20
+	users := make([]pgtype.UUID, patients)
+	for i := range users {
+		users[i] = testdb.NewUser(t, queries).ID
+	}
+
+	codes := make([]int, patients)
+	ready := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, userID := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			c.Params = gin.Params{
+				{Key: "userId", Value: userID.String()},
+				{Key: "availabilityId", Value: slot.ID.String()},
+			}
+			<-ready
+			CreateBookingHandler(c, queries)
+			codes[i] = w.Code
+		}()
+	}
+	close(ready)
+	wg.Wait()
+
+	created, conflicts := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			conflicts++
+		default:
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+	if created != 1 || conflicts != patients-1 {
+		t.Errorf("got %d created and %d conflicts, want 1 and %d", created, conflicts, patients-1)
+	}
+
+	booked, err := queries.ListActiveBookingStarts(ctx, slot.ID)
+	if err != nil {
+		t.Fatalf("failed to list bookings: %v", err)
+	}
+	if len(booked) != 1 {
+		t.Errorf("got %d active bookings, want 1", len(booked))
+	}
+}
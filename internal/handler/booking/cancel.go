@@ -0,0 +1,145 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/bookingstatus"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CancelBookingRequest struct {
+	Reason string `json:"reason"`
+}
+
+// lateCancellationError is returned when a patient cancels inside the
+// doctor's notice period and the doctor does not charge a late fee instead.
+type lateCancellationError struct {
+	notice time.Duration
+}
+
+func (e *lateCancellationError) Error() string {
+	return fmt.Sprintf("Bookings with this doctor can only be cancelled online up to %s before the appointment, please contact the clinic", e.notice)
+}
+
+// cancelBooking cancels a locked booking. Patients are held to the doctor's
+// cancellation policy; the returned flag tells whether the late cancellation
+// fee is due. The booking is kept with its reason and the slot is released.
+func cancelBooking(ctx context.Context, queries *repository.Queries, booking repository.Booking, to string, by actor, reason *string) (bool, error) {
+	lateFee := false
+	if by.Role == middleware.RoleUser {
+		policy, err := queries.GetDoctorCancellationPolicy(ctx, booking.DoctorID)
+		if err != nil {
+			return false, err
+		}
+		notice := time.Duration(policy.CancellationNoticeMinutes) * time.Minute
+		if time.Until(booking.StartsAt.Time) < notice {
+			if !policy.LateCancellationFee {
+				return false, &lateCancellationError{notice: notice}
+			}
+			lateFee = true
+		}
+	}
+
+	if err := setBookingStatus(ctx, queries, booking, to, by, reason); err != nil {
+		return false, err
+	}
+
+	err := queries.MarkBookingCancelled(ctx, repository.MarkBookingCancelledParams{
+		ID:                  booking.ID,
+		CancellationReason:  reason,
+		LateCancellationFee: lateFee,
+	})
+	return lateFee, err
+}
+
+// CancelBookingHandler cancels the patient's booking. The booking stays on
+// record as cancelled_by_patient and its slot can be booked again.
+func CancelBookingHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bookingID, err := uuid.Parse(ctx.Param("bookingId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	// The reason is optional, so is the body.
+	var req CancelBookingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	by, ok := currentActor(ctx)
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CancelBookingHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	booking, err := qtx.GetBookingByIDForUpdate(dbCtx, pgtype.UUID{Bytes: bookingID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !by.involves(booking)) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CancelBookingHandler: failed to load booking: %v", err)
+		return
+	}
+
+	if !bookingstatus.Allowed(by.Role, booking.Status, bookingstatus.CancelledByPatient) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "A " + booking.Status + " booking cannot be cancelled"})
+		return
+	}
+
+	var reason *string
+	if r := strings.TrimSpace(req.Reason); r != "" {
+		reason = &r
+	}
+
+	lateFee, err := cancelBooking(dbCtx, qtx, booking, bookingstatus.CancelledByPatient, by, reason)
+	var late *lateCancellationError
+	if errors.As(err, &late) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": late.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
+		log.Printf("CancelBookingHandler: failed to cancel booking: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CancelBookingHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":               "Booking cancelled successfully",
+		"status":                bookingstatus.CancelledByPatient,
+		"late_cancellation_fee": lateFee,
+	})
+}
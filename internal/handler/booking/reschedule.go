@@ -0,0 +1,234 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/bookingstatus"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const defaultRescheduleCutoff = 24 * time.Hour
+
+type RescheduleBookingRequest struct {
+	AvailabilityID string `json:"availability_id" binding:"required"`
+	StartTime      string `json:"start_time"` // appointment within the window on the doctor's clock, earliest free when empty
+	StartsAt       string `json:"starts_at"`  // or the appointment's start as RFC 3339
+	Reason         string `json:"reason"`
+}
+
+// rescheduleCutoff is how long before the appointment a patient can still
+// move it, read from BOOKING_RESCHEDULE_CUTOFF (e.g. "12h").
+func rescheduleCutoff() time.Duration {
+	value := os.Getenv("BOOKING_RESCHEDULE_CUTOFF")
+	if value == "" {
+		return defaultRescheduleCutoff
+	}
+	cutoff, err := time.ParseDuration(value)
+	if err != nil || cutoff < 0 {
+		log.Printf("rescheduleCutoff: invalid BOOKING_RESCHEDULE_CUTOFF %q, using %s", value, defaultRescheduleCutoff)
+		return defaultRescheduleCutoff
+	}
+	return cutoff
+}
+
+// RescheduleBookingHandler moves a patient's booking to another free slot of
+// the same doctor. The old booking is kept as rescheduled and its slot is
+// released in the same transaction that claims the new one, so the patient
+// never loses the appointment to another patient halfway through.
+func RescheduleBookingHandler(ctx *gin.Context, queries *repository.Queries, mail mailer.Mailer) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bookingID, err := uuid.Parse(ctx.Param("bookingId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var req RescheduleBookingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	availabilityID, err := uuid.Parse(req.AvailabilityID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid availability ID"})
+		return
+	}
+	parsedAvailabilityID := pgtype.UUID{Bytes: availabilityID, Valid: true}
+	choice, err := parseAppointmentChoice(req.StartTime, req.StartsAt)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	by, ok := currentActor(ctx)
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RescheduleBookingHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	old, err := qtx.GetBookingByIDForUpdate(dbCtx, pgtype.UUID{Bytes: bookingID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !by.involves(old)) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RescheduleBookingHandler: failed to load booking: %v", err)
+		return
+	}
+
+	if !bookingstatus.Allowed(by.Role, old.Status, bookingstatus.Rescheduled) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "A " + old.Status + " booking cannot be rescheduled"})
+		return
+	}
+
+	cutoff := rescheduleCutoff()
+	if time.Until(old.StartsAt.Time) < cutoff {
+		ctx.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Bookings can only be rescheduled up to %s before the appointment", cutoff)})
+		return
+	}
+
+	slot, err := qtx.GetDoctorAvailabilityByID(dbCtx, parsedAvailabilityID)
+	if err != nil || slot.DoctorID != old.DoctorID {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Availability slot not found for this doctor"})
+		return
+	}
+
+	var reason *string
+	if r := strings.TrimSpace(req.Reason); r != "" {
+		reason = &r
+	}
+
+	// The old booking lets go of its appointment first, so the patient can
+	// move to another appointment of the same window.
+	if err := setBookingStatus(dbCtx, qtx, old, bookingstatus.Rescheduled, by, reason); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule booking"})
+		log.Printf("RescheduleBookingHandler: failed to update old booking: %v", err)
+		return
+	}
+
+	// Without a chosen start, the earliest free appointment other than the
+	// one just released is picked.
+	if parsedAvailabilityID == old.AvailabilityID {
+		choice.Exclude = &old.BookingStartTime
+	}
+	res, err := reserve(dbCtx, qtx, parsedAvailabilityID, choice)
+	if err != nil {
+		reserveError(ctx, "RescheduleBookingHandler", err)
+		return
+	}
+	if res.Window.ID == old.AvailabilityID && res.Start == old.BookingStartTime {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "The booking is already for this slot"})
+		return
+	}
+
+	booking, err := qtx.CreateBooking(dbCtx, repository.CreateBookingParams{
+		UserID:           old.UserID,
+		DoctorID:         old.DoctorID,
+		AvailabilityID:   res.Window.ID,
+		BookingDate:      res.Window.AvailabilityDate,
+		BookingStartTime: res.Start,
+		BookingEndTime:   res.End,
+		Status:           bookingstatus.Pending,
+		RescheduledFrom:  old.ID,
+		QueuePosition:    res.QueuePosition,
+		StartsAt:         timezone.Timestamptz(res.StartsAt),
+		EndsAt:           timezone.Timestamptz(res.EndsAt),
+	})
+	if isDuplicateBooking(err) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "You have already booked this appointment"})
+		return
+	}
+	if isUniqueViolation(err) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Availability slot is already booked"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule booking"})
+		log.Printf("RescheduleBookingHandler: failed to create booking: %v", err)
+		return
+	}
+
+	err = qtx.CreateBookingStatusHistory(dbCtx, repository.CreateBookingStatusHistoryParams{
+		BookingID:     booking.ID,
+		ToStatus:      booking.Status,
+		ChangedBy:     by.ID,
+		ChangedByRole: by.Role,
+		Reason:        reason,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule booking"})
+		log.Printf("RescheduleBookingHandler: failed to record booking status: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("RescheduleBookingHandler: failed to commit: %v", err)
+		return
+	}
+
+	// The booking has moved either way; a failed email is only logged.
+	if doctor, err := queries.GetDoctorByID(dbCtx, booking.DoctorID); err != nil {
+		log.Printf("RescheduleBookingHandler: failed to load doctor: %v", err)
+	} else if doctor.Email != nil {
+		if err := mail.Send(dbCtx, rescheduleMessage(*doctor.Email, old, booking, reason)); err != nil {
+			log.Printf("RescheduleBookingHandler: failed to notify doctor: %v", err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, BookingResponse{
+		ID:               booking.ID,
+		UserID:           booking.UserID,
+		DoctorID:         booking.DoctorID,
+		AvailabilityID:   booking.AvailabilityID,
+		BookingDate:      booking.BookingDate.Time.String(),
+		BookingStartTime: utils.FormatTime(booking.BookingStartTime),
+		BookingEndTime:   utils.FormatTime(booking.BookingEndTime),
+		StartsAt:         timezone.In(booking.StartsAt, res.Location),
+		EndsAt:           timezone.In(booking.EndsAt, res.Location),
+		Timezone:         res.Location.String(),
+		Status:           booking.Status,
+		QueuePosition:    booking.QueuePosition,
+		RescheduledFrom:  booking.RescheduledFrom,
+	})
+}
+
+func rescheduleMessage(to string, old, booking repository.Booking, reason *string) mailer.Message {
+	body := fmt.Sprintf("A patient has moved their appointment.\n\nFrom: %s %s\nTo:   %s %s\n",
+		old.BookingDate.Time.Format("2006-01-02"), utils.FormatTime(old.BookingStartTime),
+		booking.BookingDate.Time.Format("2006-01-02"), utils.FormatTime(booking.BookingStartTime))
+	if reason != nil {
+		body += fmt.Sprintf("\nReason: %s\n", *reason)
+	}
+	return mailer.Message{
+		To:      to,
+		Subject: "A Health-Sync appointment has been rescheduled",
+		Body:    body,
+	}
+}
@@ -0,0 +1,191 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/availability"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// errDoctorUnavailable is returned when booking a doctor who is not
+	// verified, or whose account is suspended or deactivated.
+	errDoctorUnavailable = errors.New("doctor is not accepting bookings")
+	// errSlotStarted is returned for an appointment that has already begun.
+	errSlotStarted = errors.New("availability slot has already started")
+)
+
+// reservation is the appointment a new booking gets in an availability
+// window.
+type reservation struct {
+	Window        repository.DoctorAvailability
+	Start         pgtype.Time // on the doctor's clock
+	End           pgtype.Time
+	StartsAt      time.Time
+	EndsAt        time.Time
+	Location      *time.Location // the doctor's timezone
+	QueuePosition *int32
+}
+
+// appointmentChoice is the appointment a patient asked for, either as a
+// start time on the doctor's clock or as an instant. Neither picks the
+// earliest free one that has not started, other than Exclude.
+type appointmentChoice struct {
+	Clock   *time.Duration
+	At      *time.Time
+	Exclude *pgtype.Time // start of an appointment of the window not to pick
+}
+
+// start resolves the choice to a start time in the window, whose date and
+// clock are in loc.
+func (c appointmentChoice) start(window repository.DoctorAvailability, loc *time.Location) (*time.Duration, error) {
+	if c.At == nil {
+		return c.Clock, nil
+	}
+	date, clock := timezone.Wall(*c.At, loc)
+	if !date.Time.Equal(window.AvailabilityDate.Time) {
+		return nil, availability.ErrNoSuchSlot
+	}
+	start := time.Duration(clock.Microseconds) * time.Microsecond
+	return &start, nil
+}
+
+// reserve picks the appointment for a new booking in the availability
+// window, the one the patient chose or the earliest free one. Only verified
+// doctors with an active account can be booked, and appointments that have
+// already started cannot be. The window row stays locked until the
+// transaction ends, so concurrent bookings of the same window are counted
+// one after the other. A window is marked as booked once its last
+// appointment is taken.
+func reserve(ctx context.Context, queries *repository.Queries, availabilityID pgtype.UUID, choice appointmentChoice) (reservation, error) {
+	row, err := queries.LockAvailability(ctx, availabilityID)
+	if err != nil {
+		return reservation{}, err
+	}
+	doctor, err := queries.GetDoctorBookingState(ctx, row.DoctorID)
+	if err != nil {
+		return reservation{}, err
+	}
+	if doctor.VerificationStatus != middleware.DoctorVerified || doctor.SuspendedAt.Valid || doctor.DeactivatedAt.Valid {
+		return reservation{}, errDoctorUnavailable
+	}
+	loc, err := timezone.Doctor(ctx, queries, row.DoctorID)
+	if err != nil {
+		return reservation{}, err
+	}
+	start, err := choice.start(row, loc)
+	if err != nil {
+		return reservation{}, err
+	}
+
+	booked, err := queries.ListActiveBookingStarts(ctx, availabilityID)
+	if err != nil {
+		return reservation{}, err
+	}
+
+	window := availability.WindowFromRow(row)
+	taken := booked
+	if start == nil {
+		taken = unavailable(window, booked, choice.Exclude, loc)
+	}
+	slot, err := window.Reserve(taken, start)
+	if err != nil {
+		return reservation{}, err
+	}
+
+	startsAt, endsAt := slot.Instants(loc)
+	if startsAt.Before(time.Now()) {
+		return reservation{}, errSlotStarted
+	}
+	res := reservation{
+		Window:   row,
+		Start:    availability.PgTime(slot.Start),
+		End:      availability.PgTime(slot.End),
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Location: loc,
+	}
+	if window.Mode == availability.ModeQueue {
+		position, err := queries.NextQueuePosition(ctx, availabilityID)
+		if err != nil {
+			return reservation{}, err
+		}
+		res.QueuePosition = &position
+	}
+
+	isBooked := window.Full(append(booked, res.Start))
+	err = queries.UpdateAvailabilityBookedStatus(ctx, repository.UpdateAvailabilityBookedStatusParams{
+		ID:       availabilityID,
+		IsBooked: &isBooked,
+	})
+	return res, err
+}
+
+// unavailable adds the appointments that are not to be picked automatically,
+// the excluded one and those that have started, to the booked starts as
+// full.
+func unavailable(window availability.Window, booked []pgtype.Time, exclude *pgtype.Time, loc *time.Location) []pgtype.Time {
+	taken := append([]pgtype.Time(nil), booked...)
+	now := time.Now()
+	for _, slot := range window.Slots() {
+		start := availability.PgTime(slot.Start)
+		startsAt, _ := slot.Instants(loc)
+		if (exclude != nil && *exclude == start) || startsAt.Before(now) {
+			for i := 0; i < window.Capacity; i++ {
+				taken = append(taken, start)
+			}
+		}
+	}
+	return taken
+}
+
+// reserveError answers a request whose reserve failed.
+func reserveError(ctx *gin.Context, handler string, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Availability slot not found"})
+	case errors.Is(err, errDoctorUnavailable):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Doctor is not accepting bookings"})
+	case errors.Is(err, availability.ErrFull):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Availability slot is already booked"})
+	case errors.Is(err, availability.ErrNoSuchSlot):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errSlotStarted):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Availability slot has already started"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("%s: failed to reserve availability: %v", handler, err)
+	}
+}
+
+// parseAppointmentChoice parses the optional start_time, on the doctor's
+// clock, or starts_at, RFC 3339, of the appointment to book.
+func parseAppointmentChoice(startTime, startsAt string) (appointmentChoice, error) {
+	var choice appointmentChoice
+	if startsAt != "" {
+		at, err := timezone.ParseInstant(startsAt)
+		if err != nil {
+			return choice, err
+		}
+		choice.At = &at
+		return choice, nil
+	}
+	if startTime != "" {
+		t, err := time.Parse("15:04:05", startTime)
+		if err != nil {
+			return choice, errors.New("invalid start_time format")
+		}
+		start := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+		choice.Clock = &start
+	}
+	return choice, nil
+}
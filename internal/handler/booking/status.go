@@ -0,0 +1,219 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/bookingstatus"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// actor is the account changing a booking, taken from the access token.
+type actor struct {
+	ID   pgtype.UUID
+	Role string
+}
+
+func currentActor(ctx *gin.Context) (actor, bool) {
+	id, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		return actor{}, false
+	}
+	return actor{ID: pgtype.UUID{Bytes: id, Valid: true}, Role: ctx.GetString("role")}, true
+}
+
+// involves reports whether the actor is the patient or the doctor of the
+// booking, depending on their role.
+func (a actor) involves(booking repository.Booking) bool {
+	switch a.Role {
+	case middleware.RoleUser:
+		return booking.UserID == a.ID
+	case middleware.RoleDoctor:
+		return booking.DoctorID == a.ID
+	}
+	return false
+}
+
+// setBookingStatus moves a booking to a new status and records the change.
+// The slot is handed back when the booking stops holding it. It must run in
+// the transaction that locked the booking.
+func setBookingStatus(ctx context.Context, queries *repository.Queries, booking repository.Booking, to string, by actor, reason *string) error {
+	err := queries.UpdateBookingStatus(ctx, repository.UpdateBookingStatusParams{
+		ID:     booking.ID,
+		Status: to,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = queries.CreateBookingStatusHistory(ctx, repository.CreateBookingStatusHistoryParams{
+		BookingID:     booking.ID,
+		FromStatus:    &booking.Status,
+		ToStatus:      to,
+		ChangedBy:     by.ID,
+		ChangedByRole: by.Role,
+		Reason:        reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	if bookingstatus.HoldsSlot(booking.Status) && !bookingstatus.HoldsSlot(to) {
+		isBooked := false
+		return queries.UpdateAvailabilityBookedStatus(ctx, repository.UpdateAvailabilityBookedStatusParams{
+			ID:       booking.AvailabilityID,
+			IsBooked: &isBooked,
+		})
+	}
+	return nil
+}
+
+// UpdateBookingStatusHandler moves a booking along its lifecycle. Doctors
+// confirm, complete, mark no-shows and cancel; patients cancel their own
+// bookings. Other changes are refused with 409 Conflict.
+func UpdateBookingStatusHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bookingID, err := uuid.Parse(ctx.Param("bookingId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var req UpdateBookingStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !bookingstatus.Valid(req.Status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown booking status"})
+		return
+	}
+	if req.Status == bookingstatus.Rescheduled {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Use the reschedule endpoint to move a booking to another slot"})
+		return
+	}
+
+	by, ok := currentActor(ctx)
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateBookingStatusHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	booking, err := qtx.GetBookingByIDForUpdate(dbCtx, pgtype.UUID{Bytes: bookingID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !by.involves(booking)) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateBookingStatusHandler: failed to load booking: %v", err)
+		return
+	}
+
+	if !bookingstatus.Allowed(by.Role, booking.Status, req.Status) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":            "Booking cannot change from " + booking.Status + " to " + req.Status,
+			"status":           booking.Status,
+			"allowed_statuses": bookingstatus.Next(by.Role, booking.Status),
+		})
+		return
+	}
+
+	var reason *string
+	if r := strings.TrimSpace(req.Reason); r != "" {
+		reason = &r
+	}
+
+	resp := gin.H{"message": "Booking status updated successfully", "status": req.Status}
+	if bookingstatus.Cancelled(req.Status) {
+		var lateFee bool
+		lateFee, err = cancelBooking(dbCtx, qtx, booking, req.Status, by, reason)
+		resp["late_cancellation_fee"] = lateFee
+	} else {
+		err = setBookingStatus(dbCtx, qtx, booking, req.Status, by, reason)
+	}
+	var late *lateCancellationError
+	if errors.As(err, &late) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": late.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update booking status"})
+		log.Printf("UpdateBookingStatusHandler: failed to update status: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateBookingStatusHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetBookingStatusHistoryHandler lists every status change of a booking to
+// its patient or doctor, oldest first.
+func GetBookingStatusHistoryHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bookingID, err := uuid.Parse(ctx.Param("bookingId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+	parsedBookingID := pgtype.UUID{Bytes: bookingID, Valid: true}
+
+	by, ok := currentActor(ctx)
+	if !ok {
+		return
+	}
+
+	booking, err := queries.GetBookingByID(dbCtx, parsedBookingID)
+	if err != nil || !by.involves(booking) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+
+	history, err := queries.ListBookingStatusHistory(dbCtx, parsedBookingID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve booking history"})
+		log.Printf("GetBookingStatusHistoryHandler: failed to list history: %v", err)
+		return
+	}
+
+	items := make([]gin.H, len(history))
+	for i, change := range history {
+		items[i] = gin.H{
+			"from_status":     change.FromStatus,
+			"to_status":       change.ToStatus,
+			"changed_by":      change.ChangedBy,
+			"changed_by_role": change.ChangedByRole,
+			"reason":          change.Reason,
+			"changed_at":      change.CreatedAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": booking.Status, "history": items})
+}
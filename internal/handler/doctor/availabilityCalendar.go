@@ -0,0 +1,209 @@
+package doctor
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultNextSlots = 10
+	maxNextSlots     = 50
+)
+
+// NextFreeSlotResponse is a bookable appointment. Book it with its
+// availability_id and start_time.
+type NextFreeSlotResponse struct {
+	AvailabilityID   pgtype.UUID `json:"availability_id"`
+	DoctorID         pgtype.UUID `json:"doctor_id"`
+	AvailabilityDate pgtype.Date `json:"availability_date"`
+	StartTime        string      `json:"start_time"`
+	EndTime          string      `json:"end_time"`
+	StartsAt         *time.Time  `json:"starts_at"`
+	EndsAt           *time.Time  `json:"ends_at"`
+	BookingMode      string      `json:"booking_mode"`
+	Remaining        int32       `json:"remaining"`
+}
+
+// SpecializationFreeSlotResponse is a bookable appointment with the doctor
+// it is with.
+type SpecializationFreeSlotResponse struct {
+	NextFreeSlotResponse
+	DoctorName      string         `json:"doctor_name"`
+	HospitalName    string         `json:"hospital_name"`
+	ConsultationFee pgtype.Numeric `json:"consultation_fee"`
+	Timezone        string         `json:"timezone"`
+}
+
+type CalendarDayResponse struct {
+	Date       string `json:"date"`
+	FreeSlots  int64  `json:"free_slots"`
+	FreePlaces int32  `json:"free_places"` // free_slots times the places left in each
+}
+
+func newNextFreeSlotResponse(slot repository.UpcomingFreeAppointment, loc *time.Location) NextFreeSlotResponse {
+	return NextFreeSlotResponse{
+		AvailabilityID:   slot.AvailabilityID,
+		DoctorID:         slot.DoctorID,
+		AvailabilityDate: slot.AvailabilityDate,
+		StartTime:        utils.FormatTime(slot.StartTime),
+		EndTime:          utils.FormatTime(slot.EndTime),
+		StartsAt:         timezone.In(slot.StartsAt, loc),
+		EndsAt:           timezone.In(slot.EndsAt, loc),
+		BookingMode:      slot.BookingMode,
+		Remaining:        slot.Remaining,
+	}
+}
+
+// parseLimit reads ?limit=, the number of free slots to return.
+func parseLimit(ctx *gin.Context) int32 {
+	if n, err := strconv.Atoi(ctx.Query("limit")); err == nil && n > 0 {
+		return int32(min(n, maxNextSlots))
+	}
+	return defaultNextSlots
+}
+
+// GetNextFreeSlotsHandler lists the doctor's next free appointments, the
+// earliest first.
+func GetNextFreeSlotsHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+	loc, ok := doctorLocation(ctx, dbCtx, queries, doctorID)
+	if !ok {
+		return
+	}
+
+	slots, err := queries.ListNextFreeAppointmentsByDoctor(dbCtx, repository.ListNextFreeAppointmentsByDoctorParams{
+		DoctorID:   doctorID,
+		MaxResults: parseLimit(ctx),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve free slots"})
+		log.Printf("GetNextFreeSlotsHandler: failed to list free slots: %v", err)
+		return
+	}
+
+	resp := make([]NextFreeSlotResponse, len(slots))
+	for i, slot := range slots {
+		resp[i] = newNextFreeSlotResponse(slot, loc)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"timezone": loc.String(), "slots": resp})
+}
+
+// GetNextFreeSlotsBySpecializationHandler lists the next free appointments
+// with any verified doctor of ?specialization=, the earliest first.
+func GetNextFreeSlotsBySpecializationHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	specialization := strings.TrimSpace(ctx.Query("specialization"))
+	if specialization == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "specialization is required"})
+		return
+	}
+
+	slots, err := queries.ListNextFreeAppointmentsBySpecialization(dbCtx, repository.ListNextFreeAppointmentsBySpecializationParams{
+		Specialization: specialization,
+		MaxResults:     parseLimit(ctx),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve free slots"})
+		log.Printf("GetNextFreeSlotsBySpecializationHandler: failed to list free slots: %v", err)
+		return
+	}
+
+	resp := make([]SpecializationFreeSlotResponse, len(slots))
+	for i, slot := range slots {
+		loc := timezone.Stored(slot.Timezone)
+		resp[i] = SpecializationFreeSlotResponse{
+			NextFreeSlotResponse: newNextFreeSlotResponse(repository.UpcomingFreeAppointment{
+				AvailabilityID:   slot.AvailabilityID,
+				DoctorID:         slot.DoctorID,
+				AvailabilityDate: slot.AvailabilityDate,
+				StartTime:        slot.StartTime,
+				EndTime:          slot.EndTime,
+				StartsAt:         slot.StartsAt,
+				EndsAt:           slot.EndsAt,
+				BookingMode:      slot.BookingMode,
+				Capacity:         slot.Capacity,
+				Remaining:        slot.Remaining,
+			}, loc),
+			DoctorName:      slot.DoctorName,
+			HospitalName:    slot.HospitalName,
+			ConsultationFee: slot.ConsultationFee,
+			Timezone:        loc.String(),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetAvailabilityCalendarHandler counts the doctor's free appointments on
+// each day of ?month=YYYY-MM, the current month on the doctor's clock by
+// default. Days that are over count nothing.
+func GetAvailabilityCalendarHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+	loc, ok := doctorLocation(ctx, dbCtx, queries, doctorID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().In(loc)
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if month := ctx.Query("month"); month != "" {
+		parsed, err := time.Parse("2006-01", month)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "month must be YYYY-MM"})
+			return
+		}
+		first = parsed
+	}
+	last := first.AddDate(0, 1, -1)
+
+	days, err := queries.GetFreeAppointmentCalendar(dbCtx, repository.GetFreeAppointmentCalendarParams{
+		FromDate: pgtype.Date{Time: first, Valid: true},
+		ToDate:   pgtype.Date{Time: last, Valid: true},
+		DoctorID: doctorID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve calendar"})
+		log.Printf("GetAvailabilityCalendarHandler: failed to count free slots: %v", err)
+		return
+	}
+
+	resp := make([]CalendarDayResponse, len(days))
+	for i, day := range days {
+		resp[i] = CalendarDayResponse{
+			Date:       day.Date.Time.Format("2006-01-02"),
+			FreeSlots:  day.FreeSlots,
+			FreePlaces: day.FreePlaces,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"month":    first.Format("2006-01"),
+		"timezone": loc.String(),
+		"days":     resp,
+	})
+}
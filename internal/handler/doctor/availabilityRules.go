@@ -0,0 +1,528 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/availability"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxPreviewDays bounds how far ahead a rule can be previewed.
+const maxPreviewDays = 366
+
+type AvailabilityRuleRequest struct {
+	Weekday       *int   `json:"weekday" binding:"required"` // 0 is Sunday
+	StartTime     string `json:"start_time" binding:"required"`
+	EndTime       string `json:"end_time" binding:"required"`
+	SlotMinutes   int32  `json:"slot_minutes" binding:"required"`
+	BufferMinutes int32  `json:"buffer_minutes"`
+	ValidFrom     string `json:"valid_from"` // defaults to today
+	ValidUntil    string `json:"valid_until"`
+}
+
+type AvailabilityRuleResponse struct {
+	ID                pgtype.UUID `json:"id"`
+	DoctorID          pgtype.UUID `json:"doctor_id"`
+	Weekday           int16       `json:"weekday"`
+	StartTime         string      `json:"start_time"`
+	EndTime           string      `json:"end_time"`
+	SlotMinutes       int32       `json:"slot_minutes"`
+	BufferMinutes     int32       `json:"buffer_minutes"`
+	ValidFrom         pgtype.Date `json:"valid_from"`
+	ValidUntil        pgtype.Date `json:"valid_until"`
+	MaterializedUntil pgtype.Date `json:"materialized_until"`
+}
+
+type AvailabilityExceptionRequest struct {
+	Date   string `json:"date" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+type SlotResponse struct {
+	Date      string    `json:"date"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
+func availabilityRuleResponse(rule repository.AvailabilityRule) AvailabilityRuleResponse {
+	return AvailabilityRuleResponse{
+		ID:                rule.ID,
+		DoctorID:          rule.DoctorID,
+		Weekday:           rule.Weekday,
+		StartTime:         utils.FormatTime(rule.StartTime),
+		EndTime:           utils.FormatTime(rule.EndTime),
+		SlotMinutes:       rule.SlotMinutes,
+		BufferMinutes:     rule.BufferMinutes,
+		ValidFrom:         rule.ValidFrom,
+		ValidUntil:        rule.ValidUntil,
+		MaterializedUntil: rule.MaterializedUntil,
+	}
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04:05", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// parseRule validates a rule request. valid_from defaults to the date now
+// shows.
+func parseRule(req AvailabilityRuleRequest, now time.Time) (availability.Rule, error) {
+	rule := availability.Rule{
+		Weekday:    time.Weekday(*req.Weekday),
+		SlotLength: time.Duration(req.SlotMinutes) * time.Minute,
+		Buffer:     time.Duration(req.BufferMinutes) * time.Minute,
+		ValidFrom:  availability.Date(now),
+	}
+
+	var err error
+	if rule.Start, err = parseClock(req.StartTime); err != nil {
+		return rule, errors.New("invalid start_time format")
+	}
+	if rule.End, err = parseClock(req.EndTime); err != nil {
+		return rule, errors.New("invalid end_time format")
+	}
+	if req.ValidFrom != "" {
+		if rule.ValidFrom, err = time.Parse("2006-01-02", req.ValidFrom); err != nil {
+			return rule, errors.New("invalid valid_from format")
+		}
+	}
+	if req.ValidUntil != "" {
+		if rule.ValidUntil, err = time.Parse("2006-01-02", req.ValidUntil); err != nil {
+			return rule, errors.New("invalid valid_until format")
+		}
+	}
+	return rule, rule.Validate()
+}
+
+func parseDoctorID(ctx *gin.Context) (pgtype.UUID, bool) {
+	doctorID, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return pgtype.UUID{}, false
+	}
+	return pgtype.UUID{Bytes: doctorID, Valid: true}, true
+}
+
+// doctorLocation loads the doctor's timezone, answering 404 for an unknown
+// doctor.
+func doctorLocation(ctx *gin.Context, dbCtx context.Context, queries *repository.Queries, doctorID pgtype.UUID) (*time.Location, bool) {
+	loc, err := timezone.Doctor(dbCtx, queries, doctorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Doctor not found"})
+		return nil, false
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("doctorLocation: failed to load timezone: %v", err)
+		return nil, false
+	}
+	return loc, true
+}
+
+// CreateAvailabilityRuleHandler saves a weekly availability rule and creates
+// its slots up to the horizon right away. The background job extends them
+// as the horizon moves.
+func CreateAvailabilityRuleHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	var req AvailabilityRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	loc, ok := doctorLocation(ctx, dbCtx, queries, doctorID)
+	if !ok {
+		return
+	}
+	now := time.Now().In(loc)
+	rule, err := parseRule(req, now)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := repository.CreateAvailabilityRuleParams{
+		DoctorID:      doctorID,
+		Weekday:       int16(rule.Weekday),
+		StartTime:     availability.PgTime(rule.Start),
+		EndTime:       availability.PgTime(rule.End),
+		SlotMinutes:   req.SlotMinutes,
+		BufferMinutes: req.BufferMinutes,
+		ValidFrom:     pgtype.Date{Time: rule.ValidFrom, Valid: true},
+	}
+	if !rule.ValidUntil.IsZero() {
+		params.ValidUntil = pgtype.Date{Time: rule.ValidUntil, Valid: true}
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateAvailabilityRuleHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	row, err := qtx.CreateAvailabilityRule(dbCtx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create availability rule"})
+		log.Printf("CreateAvailabilityRuleHandler: failed to create rule: %v", err)
+		return
+	}
+
+	skip, err := availability.Exceptions(dbCtx, qtx, doctorID, now)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateAvailabilityRuleHandler: failed to load exceptions: %v", err)
+		return
+	}
+	created, err := availability.MaterializeRule(dbCtx, qtx, row, skip, loc, now)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create availability slots"})
+		log.Printf("CreateAvailabilityRuleHandler: failed to materialize rule: %v", err)
+		return
+	}
+
+	row, err = qtx.GetAvailabilityRule(dbCtx, repository.GetAvailabilityRuleParams{ID: row.ID, DoctorID: doctorID})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateAvailabilityRuleHandler: failed to reload rule: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateAvailabilityRuleHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"rule": availabilityRuleResponse(row), "slots_created": created})
+}
+
+func ListAvailabilityRulesHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	rules, err := queries.ListAvailabilityRulesByDoctor(ctx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve availability rules"})
+		log.Printf("ListAvailabilityRulesHandler: failed to list rules: %v", err)
+		return
+	}
+
+	resp := make([]AvailabilityRuleResponse, len(rules))
+	for i, rule := range rules {
+		resp[i] = availabilityRuleResponse(rule)
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// DeleteAvailabilityRuleHandler removes a rule together with its future
+// slots nobody has booked. Booked slots are kept.
+func DeleteAvailabilityRuleHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+	ruleID, err := uuid.Parse(ctx.Param("ruleId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+	parsedRuleID := pgtype.UUID{Bytes: ruleID, Valid: true}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeleteAvailabilityRuleHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	if _, err := qtx.GetAvailabilityRule(dbCtx, repository.GetAvailabilityRuleParams{ID: parsedRuleID, DoctorID: doctorID}); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Availability rule not found"})
+		return
+	}
+
+	removed, err := qtx.DeleteFreeRuleAvailability(dbCtx, parsedRuleID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete availability slots"})
+		log.Printf("DeleteAvailabilityRuleHandler: failed to delete slots: %v", err)
+		return
+	}
+
+	if _, err := qtx.DeleteAvailabilityRule(dbCtx, repository.DeleteAvailabilityRuleParams{ID: parsedRuleID, DoctorID: doctorID}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete availability rule"})
+		log.Printf("DeleteAvailabilityRuleHandler: failed to delete rule: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeleteAvailabilityRuleHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Availability rule deleted", "slots_removed": removed})
+}
+
+// PreviewAvailabilityRuleHandler lists the slots a rule would create over
+// the next ?days (default the horizon) without saving anything. Dates the
+// doctor has blocked get no slots.
+func PreviewAvailabilityRuleHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	days := availability.HorizonDays()
+	if value := ctx.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPreviewDays {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and " + strconv.Itoa(maxPreviewDays)})
+			return
+		}
+		days = n
+	}
+
+	var req AvailabilityRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	loc, ok := doctorLocation(ctx, ctx, queries, doctorID)
+	if !ok {
+		return
+	}
+	now := time.Now().In(loc)
+	rule, err := parseRule(req, now)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skip, err := availability.Exceptions(ctx, queries, doctorID, now)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("PreviewAvailabilityRuleHandler: failed to load exceptions: %v", err)
+		return
+	}
+
+	slots := rule.Slots(now, availability.Date(now).AddDate(0, 0, days), skip)
+	resp := make([]SlotResponse, len(slots))
+	for i, slot := range slots {
+		startsAt, endsAt := slot.Instants(loc)
+		resp[i] = SlotResponse{
+			Date:      availability.DateKey(slot.Date),
+			StartTime: utils.FormatTime(availability.PgTime(slot.Start)),
+			EndTime:   utils.FormatTime(availability.PgTime(slot.End)),
+			StartsAt:  startsAt,
+			EndsAt:    endsAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"timezone": loc.String(), "slots": resp})
+}
+
+// CreateAvailabilityExceptionHandler blocks a date, e.g. a holiday. Free
+// slots on that date are removed; booked ones stay and are counted in the
+// response so the doctor can cancel them.
+func CreateAvailabilityExceptionHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	var req AvailabilityExceptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+	pgDate := pgtype.Date{Time: date, Valid: true}
+
+	var reason *string
+	if r := strings.TrimSpace(req.Reason); r != "" {
+		reason = &r
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateAvailabilityExceptionHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	exception, err := qtx.CreateAvailabilityException(dbCtx, repository.CreateAvailabilityExceptionParams{
+		DoctorID:      doctorID,
+		ExceptionDate: pgDate,
+		Reason:        reason,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create availability exception"})
+		log.Printf("CreateAvailabilityExceptionHandler: failed to create exception: %v", err)
+		return
+	}
+
+	removed, err := qtx.DeleteFreeAvailabilityOnDate(dbCtx, repository.DeleteFreeAvailabilityOnDateParams{
+		DoctorID:         doctorID,
+		AvailabilityDate: pgDate,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove availability slots"})
+		log.Printf("CreateAvailabilityExceptionHandler: failed to delete slots: %v", err)
+		return
+	}
+
+	booked, err := qtx.CountBookedAvailabilityOnDate(dbCtx, repository.CountBookedAvailabilityOnDateParams{
+		DoctorID:         doctorID,
+		AvailabilityDate: pgDate,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateAvailabilityExceptionHandler: failed to count booked slots: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateAvailabilityExceptionHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"id":            exception.ID,
+		"date":          availability.DateKey(exception.ExceptionDate.Time),
+		"reason":        exception.Reason,
+		"slots_removed": removed,
+		"booked_slots":  booked,
+	})
+}
+
+func ListAvailabilityExceptionsHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	loc, ok := doctorLocation(ctx, ctx, queries, doctorID)
+	if !ok {
+		return
+	}
+
+	exceptions, err := queries.ListAvailabilityExceptionsByDoctor(ctx, repository.ListAvailabilityExceptionsByDoctorParams{
+		DoctorID: doctorID,
+		FromDate: pgtype.Date{Time: availability.Date(time.Now().In(loc)), Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve availability exceptions"})
+		log.Printf("ListAvailabilityExceptionsHandler: failed to list exceptions: %v", err)
+		return
+	}
+
+	resp := make([]gin.H, len(exceptions))
+	for i, exception := range exceptions {
+		resp[i] = gin.H{
+			"id":     exception.ID,
+			"date":   availability.DateKey(exception.ExceptionDate.Time),
+			"reason": exception.Reason,
+		}
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// DeleteAvailabilityExceptionHandler unblocks a date and gives it back the
+// slots of the doctor's rules.
+func DeleteAvailabilityExceptionHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+	exceptionID, err := uuid.Parse(ctx.Param("exceptionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exception ID"})
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeleteAvailabilityExceptionHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	exception, err := qtx.DeleteAvailabilityException(dbCtx, repository.DeleteAvailabilityExceptionParams{
+		ID:       pgtype.UUID{Bytes: exceptionID, Valid: true},
+		DoctorID: doctorID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Availability exception not found"})
+		return
+	}
+
+	loc, ok := doctorLocation(ctx, dbCtx, qtx, doctorID)
+	if !ok {
+		return
+	}
+
+	created := int64(0)
+	if !exception.ExceptionDate.Time.Before(availability.Date(time.Now().In(loc))) {
+		created, err = availability.RefillDate(dbCtx, qtx, doctorID, exception.ExceptionDate.Time)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore availability slots"})
+			log.Printf("DeleteAvailabilityExceptionHandler: failed to refill date: %v", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeleteAvailabilityExceptionHandler: failed to commit: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Availability exception deleted", "slots_created": created})
+}
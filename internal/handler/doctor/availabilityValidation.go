@@ -0,0 +1,119 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/availability"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SlotProblem is one reason an availability slot was rejected.
+type SlotProblem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// slotProblems checks a slot on its own: it has to end after it starts and
+// must not lie in the past on the doctor's clock, loc.
+func slotProblems(date pgtype.Date, start, end pgtype.Time, loc *time.Location, now time.Time) []SlotProblem {
+	var problems []SlotProblem
+	if end.Microseconds <= start.Microseconds {
+		problems = append(problems, SlotProblem{Field: "end_time", Message: "must be after start_time"})
+	}
+
+	today := availability.Date(now.In(loc))
+	switch {
+	case date.Time.Before(today):
+		problems = append(problems, SlotProblem{Field: "availability_date", Message: "must not be in the past"})
+	case timezone.At(date, start, loc).Before(now):
+		problems = append(problems, SlotProblem{Field: "start_time", Message: "must not be in the past"})
+	}
+	return problems
+}
+
+// validateSlot rejects an invalid or overlapping slot with 422 and reports
+// whether the slot may be saved. slot.ExcludeID is the slot being updated.
+func validateSlot(ctx *gin.Context, dbCtx context.Context, queries *repository.Queries, slot repository.ListOverlappingAvailabilityParams, loc *time.Location) bool {
+	if problems := slotProblems(slot.AvailabilityDate, slot.StartTime, slot.EndTime, loc, time.Now()); len(problems) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid availability slot", "details": problems})
+		return false
+	}
+
+	conflicts, err := queries.ListOverlappingAvailability(dbCtx, slot)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check overlapping slots"})
+		log.Printf("validateSlot: failed to list overlapping slots: %v", err)
+		return false
+	}
+	if len(conflicts) > 0 {
+		overlapResponse(ctx, conflicts)
+		return false
+	}
+	return true
+}
+
+// overlapConflict answers a write that lost a race against another slot and
+// was stopped by the exclusion constraint.
+func overlapConflict(ctx *gin.Context, dbCtx context.Context, queries *repository.Queries, slot repository.ListOverlappingAvailabilityParams) {
+	conflicts, err := queries.ListOverlappingAvailability(dbCtx, slot)
+	if err != nil {
+		log.Printf("overlapConflict: failed to list overlapping slots: %v", err)
+	}
+	overlapResponse(ctx, conflicts)
+}
+
+func overlapResponse(ctx *gin.Context, conflicts []pgtype.UUID) {
+	if conflicts == nil {
+		conflicts = []pgtype.UUID{}
+	}
+	ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":                "Availability slot overlaps existing slots",
+		"conflicting_slot_ids": conflicts,
+	})
+}
+
+// isExclusionViolation reports whether err is a PostgreSQL exclusion
+// constraint violation, raised when two slots of a doctor overlap.
+func isExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01"
+}
+
+// activeBooking returns the booking holding the slot, if there is one.
+func activeBooking(dbCtx context.Context, queries *repository.Queries, availabilityID pgtype.UUID) (*repository.Booking, error) {
+	booking, err := queries.GetActiveBookingByAvailability(dbCtx, availabilityID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// bookedWindowChange explains why a window with active bookings, starting
+// at booked, cannot be moved to start and end or freed, or returns "" when
+// it can. The booked appointments have to stay on the window's grid, so the
+// start can only move earlier by whole appointments.
+func bookedWindowChange(current repository.DoctorAvailability, start, end pgtype.Time, isBooked *bool, booked []pgtype.Time) string {
+	window := availability.WindowFromRow(current)
+	earlier := time.Duration(current.StartTime.Microseconds-start.Microseconds) * time.Microsecond
+	switch {
+	case earlier < 0 || end.Microseconds < current.EndTime.Microseconds:
+		return "Cannot shrink an availability slot with an active booking"
+	case earlier > 0 && (window.Mode == availability.ModeQueue || window.SlotLength <= 0 || earlier%window.SlotLength != 0):
+		return "The start of an availability slot with an active booking can only move earlier by whole appointments"
+	case isBooked != nil && !*isBooked && window.Full(booked):
+		return "Cannot free a fully booked availability slot"
+	}
+	return ""
+}
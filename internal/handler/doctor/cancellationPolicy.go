@@ -0,0 +1,76 @@
+package doctor
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CancellationPolicyRequest struct {
+	NoticeMinutes       *int32 `json:"notice_minutes" binding:"required,min=0"`
+	LateCancellationFee bool   `json:"late_cancellation_fee"`
+}
+
+type CancellationPolicyResponse struct {
+	NoticeMinutes       int32 `json:"notice_minutes"`
+	LateCancellationFee bool  `json:"late_cancellation_fee"`
+}
+
+// GetCancellationPolicyHandler tells patients how late they can cancel a
+// booking with the doctor and whether a late cancellation is charged.
+func GetCancellationPolicyHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	policy, err := queries.GetDoctorCancellationPolicy(ctx, pgtype.UUID{Bytes: doctorID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Doctor not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, CancellationPolicyResponse{
+		NoticeMinutes:       policy.CancellationNoticeMinutes,
+		LateCancellationFee: policy.LateCancellationFee,
+	})
+}
+
+// UpdateCancellationPolicyHandler sets the doctor's cancellation policy.
+// Patients cancelling less than notice_minutes before the appointment are
+// charged the late fee when late_cancellation_fee is set, and otherwise have
+// to contact the clinic.
+func UpdateCancellationPolicyHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	var req CancellationPolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = queries.UpdateDoctorCancellationPolicy(ctx, repository.UpdateDoctorCancellationPolicyParams{
+		ID:                        pgtype.UUID{Bytes: doctorID, Valid: true},
+		CancellationNoticeMinutes: *req.NoticeMinutes,
+		LateCancellationFee:       req.LateCancellationFee,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cancellation policy"})
+		log.Printf("UpdateCancellationPolicyHandler: failed to update policy: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, CancellationPolicyResponse{
+		NoticeMinutes:       *req.NoticeMinutes,
+		LateCancellationFee: req.LateCancellationFee,
+	})
+}
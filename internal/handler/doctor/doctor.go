@@ -1,80 +1,147 @@
 package doctor
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/SRIRAMGJ007/Health-Sync/internal/availability"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// CreateAvailabilityRequest takes either starts_at and ends_at with their
+// offsets, or availability_date, start_time and end_time on the doctor's
+// clock.
 type CreateAvailabilityRequest struct {
-	AvailabilityDate string `json:"availability_date" binding:"required"`
-	StartTime        string `json:"start_time" binding:"required"`
-	EndTime          string `json:"end_time" binding:"required"`
+	AvailabilityDate string `json:"availability_date"`
+	StartTime        string `json:"start_time"`
+	EndTime          string `json:"end_time"`
+	StartsAt         string `json:"starts_at"`                              // RFC 3339
+	EndsAt           string `json:"ends_at"`                                // RFC 3339
+	SlotMinutes      *int32 `json:"slot_minutes" binding:"omitempty,min=1"` // whole window is one appointment when empty
+	Capacity         *int32 `json:"capacity" binding:"omitempty,min=1"`     // patients per appointment or queue, default 1
+	BookingMode      string `json:"booking_mode" binding:"omitempty,oneof=slots queue"`
 }
 
-type UpdateDoctorRequest struct {
-	Name           string `json:"name"`
-	Specialization string `json:"specialization"`
-	Experience     string `json:"experience"`
-	Qualification  string `json:"qualification"`
-	HospitalName   string `json:"hospital_name"`
+type AvailabilityResponse struct {
+	ID               pgtype.UUID        `json:"id"`
+	DoctorID         pgtype.UUID        `json:"doctor_id"`
+	AvailabilityDate pgtype.Date        `json:"availability_date"`
+	StartTime        string             `json:"start_time"`
+	EndTime          string             `json:"end_time"`
+	IsBooked         bool               `json:"is_booked"`
+	StartsAt         *time.Time         `json:"starts_at"`
+	EndsAt           *time.Time         `json:"ends_at"`
+	Timezone         string             `json:"timezone"`
+	SlotMinutes      *int32             `json:"slot_minutes"`
+	Capacity         int32              `json:"capacity"`
+	BookingMode      string             `json:"booking_mode"`
+	FreeSlots        []FreeSlotResponse `json:"free_slots,omitempty"`
 }
 
-type AvailabilityResponse struct {
-	ID               pgtype.UUID `json:"id"`
-	DoctorID         pgtype.UUID `json:"doctor_id"`
-	AvailabilityDate pgtype.Date `json:"availability_date"`
-	StartTime        string      `json:"start_time"`
-	EndTime          string      `json:"end_time"`
-	IsBooked         bool        `json:"is_booked"`
+// FreeSlotResponse is an appointment of an availability window that can
+// still be booked. In queue mode it spans the window and Remaining is the
+// number of places left in the queue.
+type FreeSlotResponse struct {
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Remaining int       `json:"remaining"`
 }
 
 type UpdateAvailabilityRequest struct {
 	StartTime string `json:"start_time,omitempty" time_format:"15:04:05"`
 	EndTime   string `json:"end_time,omitempty" time_format:"15:04:05"`
-	IsBooked  bool   `json:"is_booked,omitempty"`
+	IsBooked  *bool  `json:"is_booked,omitempty"` // true closes the window, otherwise it is booked once full
 }
 
-// func UpdateDoctorHandler(ctx *gin.Context, queries *repository.Queries) {
+// newAvailabilityResponse converts a stored availability window, with its
+// instants on the doctor's clock.
+func newAvailabilityResponse(slot repository.DoctorAvailability, loc *time.Location) AvailabilityResponse {
+	return AvailabilityResponse{
+		ID:               slot.ID,
+		DoctorID:         slot.DoctorID,
+		AvailabilityDate: slot.AvailabilityDate,
+		StartTime:        utils.FormatTime(slot.StartTime),
+		EndTime:          utils.FormatTime(slot.EndTime),
+		IsBooked:         *slot.IsBooked,
+		StartsAt:         timezone.In(slot.StartsAt, loc),
+		EndsAt:           timezone.In(slot.EndsAt, loc),
+		Timezone:         loc.String(),
+		SlotMinutes:      slot.SlotMinutes,
+		Capacity:         slot.Capacity,
+		BookingMode:      slot.BookingMode,
+	}
+}
 
-// 	doctorIDStr := ctx.Param("doctorId")
+// parseAvailabilityTimes reads the slot's date and times on the doctor's
+// clock from a create request.
+func parseAvailabilityTimes(ctx *gin.Context, req CreateAvailabilityRequest, loc *time.Location) (pgtype.Date, pgtype.Time, pgtype.Time, bool) {
+	if req.StartsAt != "" || req.EndsAt != "" {
+		startsAt, err := timezone.ParseInstant(req.StartsAt)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid starts_at: " + err.Error()})
+			return pgtype.Date{}, pgtype.Time{}, pgtype.Time{}, false
+		}
+		endsAt, err := timezone.ParseInstant(req.EndsAt)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ends_at: " + err.Error()})
+			return pgtype.Date{}, pgtype.Time{}, pgtype.Time{}, false
+		}
+		date, start := timezone.Wall(startsAt, loc)
+		endDate, end := timezone.Wall(endsAt, loc)
+		if endDate != date {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid availability slot", "details": []SlotProblem{{Field: "ends_at", Message: "must be on the same day as starts_at in the doctor's timezone " + loc.String()}}})
+			return pgtype.Date{}, pgtype.Time{}, pgtype.Time{}, false
+		}
+		return date, start, end, true
+	}
 
-// 	doctorID, err := uuid.Parse(doctorIDStr)
-// 	if err != nil {
-// 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
-// 		return
-// 	}
+	if req.AvailabilityDate == "" || req.StartTime == "" || req.EndTime == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Either starts_at and ends_at, or availability_date, start_time and end_time are required"})
+		return pgtype.Date{}, pgtype.Time{}, pgtype.Time{}, false
+	}
 
-// 	var req UpdateDoctorRequest
-// 	if err := ctx.ShouldBindJSON(&req); err != nil {
-// 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-// 		return
-// 	}
+	// Parse AvailabilityDate
+	availabilityDate, err := time.Parse("2006-01-02", req.AvailabilityDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid availability_date format"})
+		return pgtype.Date{}, pgtype.Time{}, pgtype.Time{}, false
+	}
 
-// 	err = queries.UpdateDoctor(ctx, repository.UpdateDoctorParams{
-// 		ID:             doctorID,
-// 		Name:           req.Name,
-// 		Specialization: req.Specialization,
-// 		Experience:     req.Experience,
-// 		Qualification:  req.Qualification,
-// 		HospitalName:   req.HospitalName,
-// 	})
+	// Parse StartTime
+	startTime, err := time.Parse("15:04:05", req.StartTime)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_time format"})
+		return pgtype.Date{}, pgtype.Time{}, pgtype.Time{}, false
+	}
 
-// 	if err != nil {
-// 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update doctor"})
-// 		return
-// 	}
+	// Parse EndTime
+	endTime, err := time.Parse("15:04:05", req.EndTime)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_time format"})
+		return pgtype.Date{}, pgtype.Time{}, pgtype.Time{}, false
+	}
 
-// 	ctx.JSON(http.StatusOK, gin.H{"message": "Doctor updated successfully"})
+	// Calculate microseconds since midnight
+	startTimeMicro := (startTime.Hour()*3600 + startTime.Minute()*60 + startTime.Second()) * 1e6
+	endTimeMicro := (endTime.Hour()*3600 + endTime.Minute()*60 + endTime.Second()) * 1e6
 
-// }
+	return pgtype.Date{Time: availabilityDate, Valid: true},
+		pgtype.Time{Microseconds: int64(startTimeMicro), Valid: true},
+		pgtype.Time{Microseconds: int64(endTimeMicro), Valid: true},
+		true
+}
 
 func CreateAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
 	log.Println("CreateAvailabilityHandler: Request received")
@@ -92,54 +159,68 @@ func CreateAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
-	// Parse AvailabilityDate
-	availabilityDate, err := time.Parse("2006-01-02", req.AvailabilityDate)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid availability_date format"})
+	loc, ok := doctorLocation(ctx, ctx, queries, parsedid)
+	if !ok {
 		return
 	}
-	availabilityDatePg := pgtype.Date{Time: availabilityDate, Valid: true}
-
-	// Parse StartTime
-	startTime, err := time.Parse("15:04:05", req.StartTime)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_time format"})
+	availabilityDatePg, startTimePg, endTimePg, ok := parseAvailabilityTimes(ctx, req, loc)
+	if !ok {
 		return
 	}
 
-	// Parse EndTime
-	endTime, err := time.Parse("15:04:05", req.EndTime)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_time format"})
+	slot := repository.ListOverlappingAvailabilityParams{
+		DoctorID:         parsedid,
+		AvailabilityDate: availabilityDatePg,
+		StartTime:        startTimePg,
+		EndTime:          endTimePg,
+	}
+	if !validateSlot(ctx, ctx, queries, slot, loc) {
 		return
 	}
 
-	// Calculate microseconds since midnight
-	startTimeMicro := (startTime.Hour()*3600 + startTime.Minute()*60 + startTime.Second()) * 1e6
-	endTimeMicro := (endTime.Hour()*3600 + endTime.Minute()*60 + endTime.Second()) * 1e6
-
-	startTimePg := pgtype.Time{Microseconds: int64(startTimeMicro), Valid: true}
-	endTimePg := pgtype.Time{Microseconds: int64(endTimeMicro), Valid: true}
+	capacity := int32(1)
+	if req.Capacity != nil {
+		capacity = *req.Capacity
+	}
+	bookingMode := availability.ModeSlots
+	if req.BookingMode != "" {
+		bookingMode = req.BookingMode
+	}
+	window := availability.Window{
+		Start:    time.Duration(startTimePg.Microseconds) * time.Microsecond,
+		End:      time.Duration(endTimePg.Microseconds) * time.Microsecond,
+		Capacity: int(capacity),
+		Mode:     bookingMode,
+	}
+	if req.SlotMinutes != nil {
+		window.SlotLength = time.Duration(*req.SlotMinutes) * time.Minute
+	}
+	if err := window.Validate(); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid availability slot", "details": []SlotProblem{{Field: "slot_minutes", Message: err.Error()}}})
+		return
+	}
 
-	availability, err := queries.CreateDoctorAvailability(ctx, repository.CreateDoctorAvailabilityParams{
+	created, err := queries.CreateDoctorAvailability(ctx, repository.CreateDoctorAvailabilityParams{
 		DoctorID:         parsedid,
 		AvailabilityDate: availabilityDatePg,
 		StartTime:        startTimePg,
 		EndTime:          endTimePg,
+		SlotMinutes:      req.SlotMinutes,
+		Capacity:         capacity,
+		BookingMode:      bookingMode,
+		StartsAt:         timezone.Timestamptz(timezone.At(availabilityDatePg, startTimePg, loc)),
+		EndsAt:           timezone.Timestamptz(timezone.At(availabilityDatePg, endTimePg, loc)),
 	})
+	if isExclusionViolation(err) {
+		overlapConflict(ctx, ctx, queries, slot)
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	resp := AvailabilityResponse{
-		ID:               availability.ID,
-		DoctorID:         availability.DoctorID,
-		AvailabilityDate: availability.AvailabilityDate,
-		StartTime:        utils.FormatTime(availability.StartTime), // Formatted time
-		EndTime:          utils.FormatTime(availability.EndTime),   // Formatted time
-		IsBooked:         *availability.IsBooked,
-	}
+	resp := newAvailabilityResponse(created, loc)
 
 	ctx.JSON(http.StatusCreated, resp)
 
@@ -170,43 +251,127 @@ func UpdateAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
-	startTime, err := time.Parse("15:04:05", req.StartTime)
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The window stays locked until the update commits, so no booking can
+	// be made between checking its bookings and changing it.
+	tx, err := database.DB.Begin(dbCtx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_time format"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateAvailabilityHandler: failed to begin transaction: %v", err)
 		return
 	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
 
-	// Parse EndTime
-	endTime, err := time.Parse("15:04:05", req.EndTime)
+	current, err := qtx.LockAvailability(dbCtx, parsedAvailabilityID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && current.DoctorID != parsedDoctorID) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Availability slot not found"})
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_time format"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateAvailabilityHandler: failed to lock availability: %v", err)
+		return
+	}
+	loc, ok := doctorLocation(ctx, dbCtx, qtx, parsedDoctorID)
+	if !ok {
 		return
 	}
 
-	// Calculate microseconds since midnight
-	startTimeMicro := (startTime.Hour()*3600 + startTime.Minute()*60 + startTime.Second()) * 1e6
-	endTimeMicro := (endTime.Hour()*3600 + endTime.Minute()*60 + endTime.Second()) * 1e6
+	// Times left out of the request keep their current value.
+	startTimePg, endTimePg := current.StartTime, current.EndTime
+	if req.StartTime != "" {
+		startTime, err := time.Parse("15:04:05", req.StartTime)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_time format"})
+			return
+		}
+		startTimePg = pgtype.Time{Microseconds: int64((startTime.Hour()*3600 + startTime.Minute()*60 + startTime.Second()) * 1e6), Valid: true}
+	}
+	if req.EndTime != "" {
+		endTime, err := time.Parse("15:04:05", req.EndTime)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_time format"})
+			return
+		}
+		endTimePg = pgtype.Time{Microseconds: int64((endTime.Hour()*3600 + endTime.Minute()*60 + endTime.Second()) * 1e6), Valid: true}
+	}
+
+	// A booked slot may grow, but must keep its appointments and stay booked
+	// while it is full.
+	booking, err := activeBooking(dbCtx, qtx, parsedAvailabilityID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bookings"})
+		log.Printf("UpdateAvailabilityHandler: failed to get active booking: %v", err)
+		return
+	}
+	booked, err := qtx.ListActiveBookingStarts(dbCtx, parsedAvailabilityID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bookings"})
+		log.Printf("UpdateAvailabilityHandler: failed to list active bookings: %v", err)
+		return
+	}
+	if booking != nil {
+		if problem := bookedWindowChange(current, startTimePg, endTimePg, req.IsBooked, booked); problem != "" {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error":      problem,
+				"booking_id": booking.ID,
+			})
+			return
+		}
+	}
 
-	startTimePg := pgtype.Time{Microseconds: int64(startTimeMicro), Valid: true}
-	endTimePg := pgtype.Time{Microseconds: int64(endTimeMicro), Valid: true}
+	slot := repository.ListOverlappingAvailabilityParams{
+		DoctorID:         parsedDoctorID,
+		AvailabilityDate: current.AvailabilityDate,
+		StartTime:        startTimePg,
+		EndTime:          endTimePg,
+		ExcludeID:        parsedAvailabilityID,
+	}
+	if !validateSlot(ctx, dbCtx, qtx, slot, loc) {
+		return
+	}
 
-	err = queries.UpdateDoctorAvailability(ctx, repository.UpdateDoctorAvailabilityParams{
+	// The window is booked when the resized window is full, as reserve
+	// keeps it, or when the doctor closes it.
+	updated := current
+	updated.StartTime, updated.EndTime = startTimePg, endTimePg
+	isBooked := availability.WindowFromRow(updated).Full(booked) || (req.IsBooked != nil && *req.IsBooked)
+
+	err = qtx.UpdateDoctorAvailability(dbCtx, repository.UpdateDoctorAvailabilityParams{
 		StartTime: startTimePg,
 		EndTime:   endTimePg,
-		IsBooked:  &req.IsBooked,
+		IsBooked:  &isBooked,
 		ID:        parsedAvailabilityID,
 		DoctorID:  parsedDoctorID,
+		StartsAt:  timezone.Timestamptz(timezone.At(current.AvailabilityDate, startTimePg, loc)),
+		EndsAt:    timezone.Timestamptz(timezone.At(current.AvailabilityDate, endTimePg, loc)),
 	})
+	if isExclusionViolation(err) {
+		overlapConflict(ctx, dbCtx, queries, slot)
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateAvailabilityHandler: failed to commit: %v", err)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "Availability updated successfully",
 		"updates": gin.H{
-			"start_time": req.StartTime,
-			"end_time":   req.EndTime,
+			"start_time": utils.FormatTime(startTimePg),
+			"end_time":   utils.FormatTime(endTimePg),
+			"is_booked":  isBooked,
+			"starts_at":  timezone.At(current.AvailabilityDate, startTimePg, loc),
+			"ends_at":    timezone.At(current.AvailabilityDate, endTimePg, loc),
 		},
 	})
 
@@ -232,6 +397,11 @@ func GetAvailabilityByDoctorAndDateHandler(ctx *gin.Context, queries *repository
 
 	datePg := pgtype.Date{Time: date, Valid: true}
 
+	loc, ok := doctorLocation(ctx, ctx, queries, parsedDoctorID)
+	if !ok {
+		return
+	}
+
 	availabilitySlots, err := queries.GetDoctorAvailabilityByDoctorAndDate(ctx, repository.GetDoctorAvailabilityByDoctorAndDateParams{
 		DoctorID:         parsedDoctorID,
 		AvailabilityDate: datePg,
@@ -241,15 +411,31 @@ func GetAvailabilityByDoctorAndDateHandler(ctx *gin.Context, queries *repository
 		return
 	}
 
+	bookings, err := queries.ListActiveBookingStartsByDoctorAndDate(ctx, repository.ListActiveBookingStartsByDoctorAndDateParams{
+		DoctorID:    parsedDoctorID,
+		BookingDate: datePg,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	booked := make(map[pgtype.UUID][]pgtype.Time)
+	for _, booking := range bookings {
+		booked[booking.AvailabilityID] = append(booked[booking.AvailabilityID], booking.BookingStartTime)
+	}
+
 	resp := make([]AvailabilityResponse, len(availabilitySlots))
 	for i, slot := range availabilitySlots {
-		resp[i] = AvailabilityResponse{
-			ID:               slot.ID,
-			DoctorID:         slot.DoctorID,
-			AvailabilityDate: slot.AvailabilityDate,
-			StartTime:        utils.FormatTime(slot.StartTime),
-			EndTime:          utils.FormatTime(slot.EndTime),
-			IsBooked:         *slot.IsBooked,
+		resp[i] = newAvailabilityResponse(slot, loc)
+		for _, free := range availability.WindowFromRow(slot).Free(booked[slot.ID]) {
+			startsAt, endsAt := free.Instants(loc)
+			resp[i].FreeSlots = append(resp[i].FreeSlots, FreeSlotResponse{
+				StartTime: utils.FormatTime(availability.PgTime(free.Start)),
+				EndTime:   utils.FormatTime(availability.PgTime(free.End)),
+				StartsAt:  startsAt,
+				EndsAt:    endsAt,
+				Remaining: free.Remaining,
+			})
 		}
 	}
 
@@ -269,6 +455,11 @@ func GetAvailabilityByDoctorHandler(ctx *gin.Context, queries *repository.Querie
 	}
 	parsedDoctorID := pgtype.UUID{Bytes: doctorUUID, Valid: true}
 
+	loc, ok := doctorLocation(ctx, ctx, queries, parsedDoctorID)
+	if !ok {
+		return
+	}
+
 	availabilitySlots, err := queries.GetDoctorAvailabilityByDoctor(ctx, parsedDoctorID)
 	log.Printf("response: %v ----> error is %v", availabilitySlots, err)
 	if err != nil {
@@ -280,14 +471,7 @@ func GetAvailabilityByDoctorHandler(ctx *gin.Context, queries *repository.Querie
 	resp := make([]AvailabilityResponse, len(availabilitySlots))
 	log.Printf("response: %v", resp)
 	for i, slot := range availabilitySlots {
-		resp[i] = AvailabilityResponse{
-			ID:               slot.ID,
-			DoctorID:         slot.DoctorID,
-			AvailabilityDate: slot.AvailabilityDate,
-			StartTime:        utils.FormatTime(slot.StartTime),
-			EndTime:          utils.FormatTime(slot.EndTime),
-			IsBooked:         *slot.IsBooked,
-		}
+		resp[i] = newAvailabilityResponse(slot, loc)
 	}
 	log.Printf("response: %v", resp)
 
@@ -314,7 +498,46 @@ func DeleteAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
 	}
 	parsedAvailabilityID := pgtype.UUID{Bytes: availabilityUUID, Valid: true}
 
-	bookings, err := queries.GetBookingsByAvailabilityID(ctx, parsedAvailabilityID)
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// As in UpdateAvailabilityHandler, the locked window cannot be booked
+	// while it is being deleted.
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeleteAvailabilityHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	current, err := qtx.LockAvailability(dbCtx, parsedAvailabilityID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && current.DoctorID != parsedDoctorID) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Availability slot not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeleteAvailabilityHandler: failed to lock availability: %v", err)
+		return
+	}
+
+	booking, err := activeBooking(dbCtx, qtx, parsedAvailabilityID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bookings"})
+		return
+	}
+	if booking != nil {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":      "Cannot delete an availability slot with an active booking",
+			"booking_id": booking.ID,
+		})
+		return
+	}
+
+	// Cancelled bookings still reference the slot.
+	bookings, err := qtx.GetBookingsByAvailabilityID(dbCtx, parsedAvailabilityID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bookings"})
 		return
@@ -325,7 +548,7 @@ func DeleteAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
-	err = queries.DeleteDoctorAvailability(ctx, repository.DeleteDoctorAvailabilityParams{
+	err = qtx.DeleteDoctorAvailability(dbCtx, repository.DeleteDoctorAvailabilityParams{
 		ID:       parsedAvailabilityID,
 		DoctorID: parsedDoctorID,
 	})
@@ -334,26 +557,12 @@ func DeleteAvailabilityHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeleteAvailabilityHandler: failed to commit: %v", err)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{"message": "Availability deleted successfully"})
 
 }
-
-// func DeleteDoctorHandler(queries *repository.Queries) gin.HandlerFunc {
-// 	return func(ctx *gin.Context) {
-// 		doctorIDStr := ctx.Param("doctorId")
-
-// 		doctorID, err := uuid.Parse(doctorIDStr)
-// 		if err != nil {
-// 			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
-// 			return
-// 		}
-
-// 		err = queries.DeleteDoctor(ctx, doctorID)
-// 		if err != nil {
-// 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete doctor"})
-// 			return
-// 		}
-
-// 		ctx.JSON(http.StatusOK, gin.H{"message": "Doctor deleted successfully"})
-// 	}
-// }
@@ -0,0 +1,133 @@
+package doctor
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UploadLicenseDocumentHandler stores a license or registration certificate
+// for review. Documents are encrypted like medical records. A rejected
+// doctor who uploads a document goes back to pending verification.
+func UploadLicenseDocumentHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+
+	fileData, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer fileData.Close()
+
+	data, err := io.ReadAll(fileData)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	encryptionKey := []byte(os.Getenv("ENCRYPTION_KEY"))
+	if len(encryptionKey) != 32 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid encryption key length"})
+		return
+	}
+
+	encryptedData, err := utils.EncryptData(data, encryptionKey)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Encryption failed"})
+		return
+	}
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UploadLicenseDocumentHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	parsedDoctorID := pgtype.UUID{Bytes: doctorID, Valid: true}
+	document, err := qtx.CreateDoctorLicenseDocument(dbCtx, repository.CreateDoctorLicenseDocumentParams{
+		DoctorID: parsedDoctorID,
+		FileName: file.Filename,
+		FileData: encryptedData,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store document"})
+		log.Printf("UploadLicenseDocumentHandler: failed to store document: %v", err)
+		return
+	}
+
+	// New documents from a rejected doctor are reviewed again.
+	resubmitted, err := qtx.ResubmitRejectedDoctor(dbCtx, parsedDoctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store document"})
+		log.Printf("UploadLicenseDocumentHandler: failed to resubmit for review: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UploadLicenseDocumentHandler: failed to commit: %v", err)
+		return
+	}
+	if resubmitted > 0 {
+		log.Printf("UploadLicenseDocumentHandler: doctor %s is pending verification again", parsedDoctorID.String())
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":     "License document uploaded successfully",
+		"document_id": document.ID,
+		"file_name":   document.FileName,
+		"created_at":  document.CreatedAt,
+	})
+}
+
+func ListLicenseDocumentsHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, err := uuid.Parse(ctx.Param("doctorId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	documents, err := queries.ListDoctorLicenseDocuments(ctx, pgtype.UUID{Bytes: doctorID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch documents"})
+		log.Printf("ListLicenseDocumentsHandler: failed to fetch documents: %v", err)
+		return
+	}
+
+	documentList := make([]gin.H, 0, len(documents))
+	for _, document := range documents {
+		documentList = append(documentList, gin.H{
+			"document_id": document.ID,
+			"file_name":   document.FileName,
+			"created_at":  document.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"doctor_id": doctorID, "documents": documentList})
+}
@@ -0,0 +1,428 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const maxProfilePhotoSize = 2 << 20 // 2 MiB
+
+// Profile photo formats, as detected from the uploaded bytes.
+var profilePhotoTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// UpdateDoctorProfileRequest changes the fields that are set and keeps the
+// others. An empty languages list clears it.
+type UpdateDoctorProfileRequest struct {
+	Name            *string   `json:"name" binding:"omitempty,min=1"`
+	Specialization  *string   `json:"specialization" binding:"omitempty,min=1"`
+	Experience      *int32    `json:"experience" binding:"omitempty,min=0"`
+	Qualification   *string   `json:"qualification" binding:"omitempty,min=1"`
+	HospitalName    *string   `json:"hospital_name" binding:"omitempty,min=1"`
+	ConsultationFee *string   `json:"consultation_fee"`
+	ContactNumber   *string   `json:"contact_number"`
+	Bio             *string   `json:"bio" binding:"omitempty,max=2000"`
+	Languages       *[]string `json:"languages" binding:"omitempty,max=20,dive,min=1,max=50"`
+	ClinicAddress   *string   `json:"clinic_address"`
+	ClinicLatitude  *float64  `json:"clinic_latitude" binding:"omitempty,min=-90,max=90"`
+	ClinicLongitude *float64  `json:"clinic_longitude" binding:"omitempty,min=-180,max=180"`
+}
+
+type DoctorProfileResponse struct {
+	ID                 pgtype.UUID        `json:"id"`
+	Name               string             `json:"name"`
+	Email              *string            `json:"email"`
+	Specialization     string             `json:"specialization"`
+	Experience         int32              `json:"experience"`
+	Qualification      string             `json:"qualification"`
+	HospitalName       string             `json:"hospital_name"`
+	ConsultationFee    pgtype.Numeric     `json:"consultation_fee"`
+	ContactNumber      *string            `json:"contact_number"`
+	Bio                *string            `json:"bio"`
+	Languages          []string           `json:"languages"`
+	ClinicAddress      *string            `json:"clinic_address"`
+	ClinicLatitude     *float64           `json:"clinic_latitude"`
+	ClinicLongitude    *float64           `json:"clinic_longitude"`
+	PhotoURL           *string            `json:"photo_url"`
+	RatingAverage      pgtype.Numeric     `json:"rating_average"`
+	RatingCount        int32              `json:"rating_count"`
+	Timezone           string             `json:"timezone"`
+	VerificationStatus string             `json:"verification_status"`
+	DeactivatedAt      pgtype.Timestamptz `json:"deactivated_at"`
+}
+
+// callerDoctorID is the authenticated doctor's ID.
+func callerDoctorID(ctx *gin.Context) (pgtype.UUID, bool) {
+	id, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		return pgtype.UUID{}, false
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, true
+}
+
+func newDoctorProfileResponse(doctor repository.Doctor, hasPhoto bool) DoctorProfileResponse {
+	resp := DoctorProfileResponse{
+		ID:                 doctor.ID,
+		Name:               doctor.Name,
+		Email:              doctor.Email,
+		Specialization:     doctor.Specialization,
+		Experience:         doctor.Experience,
+		Qualification:      doctor.Qualification,
+		HospitalName:       doctor.HospitalName,
+		ConsultationFee:    doctor.ConsultationFee,
+		ContactNumber:      doctor.ContactNumber,
+		Bio:                doctor.Bio,
+		Languages:          doctor.Languages,
+		ClinicAddress:      doctor.ClinicAddress,
+		ClinicLatitude:     doctor.ClinicLatitude,
+		ClinicLongitude:    doctor.ClinicLongitude,
+		RatingAverage:      doctor.RatingAverage,
+		RatingCount:        doctor.RatingCount,
+		Timezone:           timezone.Stored(doctor.Timezone).String(),
+		VerificationStatus: doctor.VerificationStatus,
+		DeactivatedAt:      doctor.DeactivatedAt,
+	}
+	if resp.Languages == nil {
+		resp.Languages = []string{}
+	}
+	if hasPhoto {
+		url := "/doctors/" + doctor.ID.String() + "/photo"
+		resp.PhotoURL = &url
+	}
+	return resp
+}
+
+// GetMyDoctorProfileHandler returns the authenticated doctor's profile.
+func GetMyDoctorProfileHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	doctor, err := queries.GetDoctorByID(dbCtx, doctorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Doctor not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("GetMyDoctorProfileHandler: failed to load doctor: %v", err)
+		return
+	}
+	hasPhoto, err := queries.DoctorHasProfilePhoto(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("GetMyDoctorProfileHandler: failed to look up photo: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newDoctorProfileResponse(doctor, hasPhoto))
+}
+
+// UpdateMyDoctorProfileHandler applies a partial update to the
+// authenticated doctor's profile. Changing the name, specialization,
+// experience, qualification or hospital returns the doctor to pending
+// verification.
+func UpdateMyDoctorProfileHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	var req UpdateDoctorProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.ClinicLatitude == nil) != (req.ClinicLongitude == nil) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "clinic_latitude and clinic_longitude must be set together"})
+		return
+	}
+
+	params := repository.UpdateDoctorProfileParams{
+		ID:              doctorID,
+		Name:            trimmed(req.Name),
+		Specialization:  trimmed(req.Specialization),
+		Experience:      req.Experience,
+		Qualification:   trimmed(req.Qualification),
+		HospitalName:    trimmed(req.HospitalName),
+		ContactNumber:   trimmed(req.ContactNumber),
+		Bio:             req.Bio,
+		ClinicAddress:   trimmed(req.ClinicAddress),
+		ClinicLatitude:  req.ClinicLatitude,
+		ClinicLongitude: req.ClinicLongitude,
+	}
+	if req.ConsultationFee != nil {
+		if err := params.ConsultationFee.Scan(*req.ConsultationFee); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid consultation fee format"})
+			return
+		}
+		if fee, err := params.ConsultationFee.Float64Value(); err != nil || fee.Float64 < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid consultation fee format"})
+			return
+		}
+	}
+	if req.Languages != nil {
+		params.Languages = make([]string, 0, len(*req.Languages))
+		for _, language := range *req.Languages {
+			params.Languages = append(params.Languages, strings.TrimSpace(language))
+		}
+	}
+
+	doctor, err := queries.UpdateDoctorProfile(dbCtx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Doctor not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		log.Printf("UpdateMyDoctorProfileHandler: failed to update doctor: %v", err)
+		return
+	}
+	hasPhoto, err := queries.DoctorHasProfilePhoto(dbCtx, doctorID)
+	if err != nil {
+		log.Printf("UpdateMyDoctorProfileHandler: failed to look up photo: %v", err)
+	}
+
+	ctx.JSON(http.StatusOK, newDoctorProfileResponse(doctor, hasPhoto))
+}
+
+func trimmed(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	s := strings.TrimSpace(*value)
+	return &s
+}
+
+// UploadProfilePhotoHandler replaces the authenticated doctor's profile
+// photo with the JPEG, PNG or WebP image in the "photo" form field.
+func UploadProfilePhotoHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	file, err := ctx.FormFile("photo")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Photo is required"})
+		return
+	}
+	if file.Size > maxProfilePhotoSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Photo must not be larger than 2 MiB"})
+		return
+	}
+
+	fileData, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer fileData.Close()
+
+	data, err := io.ReadAll(io.LimitReader(fileData, maxProfilePhotoSize+1))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+	if len(data) > maxProfilePhotoSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Photo must not be larger than 2 MiB"})
+		return
+	}
+	contentType := http.DetectContentType(data)
+	if !profilePhotoTypes[contentType] {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Photo must be a JPEG, PNG or WebP image"})
+		return
+	}
+
+	err = queries.UpsertDoctorProfilePhoto(dbCtx, repository.UpsertDoctorProfilePhotoParams{
+		DoctorID:    doctorID,
+		ContentType: contentType,
+		Data:        data,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store photo"})
+		log.Printf("UploadProfilePhotoHandler: failed to store photo: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Profile photo updated successfully",
+		"photo_url": "/doctors/" + doctorID.String() + "/photo",
+	})
+}
+
+// DeleteProfilePhotoHandler removes the authenticated doctor's profile photo.
+func DeleteProfilePhotoHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, ok := callerDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	deleted, err := queries.DeleteDoctorProfilePhoto(ctx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete photo"})
+		log.Printf("DeleteProfilePhotoHandler: failed to delete photo: %v", err)
+		return
+	}
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No profile photo"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Profile photo deleted successfully"})
+}
+
+// GetProfilePhotoHandler serves a doctor's profile photo.
+func GetProfilePhotoHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	photo, err := queries.GetDoctorProfilePhoto(ctx, doctorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No profile photo"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve photo"})
+		log.Printf("GetProfilePhotoHandler: failed to load photo: %v", err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=300")
+	ctx.Data(http.StatusOK, photo.ContentType, photo.Data)
+}
+
+// DeactivateDoctorHandler hides the authenticated doctor from patients. The
+// doctor's upcoming slots that were never booked are removed and no new
+// bookings can be made; past bookings stay. Doctors with upcoming booked
+// appointments have to cancel or finish them first.
+func DeactivateDoctorHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeactivateDoctorHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	booked, err := qtx.CountUpcomingActiveBookingsByDoctor(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("DeactivateDoctorHandler: failed to count upcoming bookings: %v", err)
+		return
+	}
+	if booked > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":             "Cannot deactivate the account while upcoming appointments are booked",
+			"upcoming_bookings": booked,
+		})
+		return
+	}
+
+	err = qtx.SetDoctorDeactivated(dbCtx, repository.SetDoctorDeactivatedParams{
+		DeactivatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ID:            doctorID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate account"})
+		log.Printf("DeactivateDoctorHandler: failed to deactivate doctor: %v", err)
+		return
+	}
+	removed, err := qtx.DeleteFreeUpcomingAvailabilityByDoctor(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate account"})
+		log.Printf("DeactivateDoctorHandler: failed to remove free slots: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate account"})
+		log.Printf("DeactivateDoctorHandler: failed to commit transaction: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       "Account deactivated successfully",
+		"removed_slots": removed,
+	})
+}
+
+// ReactivateDoctorHandler lists the authenticated doctor for patients again.
+// Slots of the doctor's availability rules are laid out again by the
+// background job.
+func ReactivateDoctorHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doctorID, ok := callerDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ReactivateDoctorHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	err = qtx.SetDoctorDeactivated(dbCtx, repository.SetDoctorDeactivatedParams{ID: doctorID})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate account"})
+		log.Printf("ReactivateDoctorHandler: failed to reactivate doctor: %v", err)
+		return
+	}
+	if err := qtx.ResetAvailabilityRulesMaterialization(dbCtx, doctorID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate account"})
+		log.Printf("ReactivateDoctorHandler: failed to reset availability rules: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate account"})
+		log.Printf("ReactivateDoctorHandler: failed to commit transaction: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Account reactivated successfully"})
+}
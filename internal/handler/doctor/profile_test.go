@@ -0,0 +1,62 @@
+package doctor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/testdb"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func updateProfile(t *testing.T, queries *repository.Queries, doctorID pgtype.UUID, body string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", doctorID.String())
+	UpdateMyDoctorProfileHandler(c, queries)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: got status %d: %s", body, w.Code, w.Body)
+	}
+}
+
+// TestUpdateMyDoctorProfileHandlerCredentials checks that a verified doctor
+// goes back to review when a credential changes, and only then.
+func TestUpdateMyDoctorProfileHandlerCredentials(t *testing.T) {
+	queries := testdb.Connect(t)
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"bio", `{"bio": "Family doctor"}`, middleware.DoctorVerified},
+		{"unchanged credentials", `{"name": "Test Doctor", "experience": 5, "qualification": " MBBS "}`, middleware.DoctorVerified},
+		{"name", `{"name": "Another Doctor"}`, middleware.DoctorPendingVerification},
+		{"specialization", `{"specialization": "Cardiology"}`, middleware.DoctorPendingVerification},
+		{"experience", `{"experience": 6}`, middleware.DoctorPendingVerification},
+		{"qualification", `{"qualification": "MBBS, MD"}`, middleware.DoctorPendingVerification},
+		{"hospital", `{"hospital_name": "Another Hospital"}`, middleware.DoctorPendingVerification},
+	}
+	for _, tt := range tests {
+		doctor := testdb.NewDoctor(t, queries)
+		updateProfile(t, queries, doctor.ID, tt.body)
+
+		updated, err := queries.GetDoctorByID(ctx, doctor.ID)
+		if err != nil {
+			t.Fatalf("%s: failed to load doctor: %v", tt.name, err)
+		}
+		if updated.VerificationStatus != tt.want {
+			t.Errorf("%s: verification status %q, want %q", tt.name, updated.VerificationStatus, tt.want)
+		}
+	}
+}
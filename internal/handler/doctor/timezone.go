@@ -0,0 +1,127 @@
+package doctor
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/gin-gonic/gin"
+)
+
+type DoctorTimezoneRequest struct {
+	Timezone string `json:"timezone" binding:"required"`
+}
+
+type DoctorTimezoneResponse struct {
+	Timezone string `json:"timezone"`
+}
+
+// GetDoctorTimezoneHandler returns the timezone the doctor's availability
+// dates and times are read in.
+func GetDoctorTimezoneHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+	loc, ok := doctorLocation(ctx, dbCtx, queries, doctorID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DoctorTimezoneResponse{Timezone: loc.String()})
+}
+
+// UpdateDoctorTimezoneHandler moves the doctor to another timezone. Upcoming
+// slots keep their date and times on the doctor's clock, so the instants
+// they start and end at move with it. That would move booked appointments
+// too, so the change is refused while the doctor has any.
+func UpdateDoctorTimezoneHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseDoctorID(ctx)
+	if !ok {
+		return
+	}
+
+	var req DoctorTimezoneRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	loc, err := timezone.Load(req.Timezone)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := doctorLocation(ctx, dbCtx, queries, doctorID); !ok {
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateDoctorTimezoneHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	booked, err := qtx.CountUpcomingActiveBookingsByDoctor(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateDoctorTimezoneHandler: failed to count upcoming bookings: %v", err)
+		return
+	}
+	if booked > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":             "Cannot change the timezone while upcoming appointments are booked",
+			"upcoming_bookings": booked,
+		})
+		return
+	}
+
+	err = qtx.UpdateDoctorTimezone(dbCtx, repository.UpdateDoctorTimezoneParams{
+		Timezone: loc.String(),
+		ID:       doctorID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update timezone"})
+		log.Printf("UpdateDoctorTimezoneHandler: failed to update timezone: %v", err)
+		return
+	}
+
+	slots, err := qtx.ListUpcomingAvailabilityByDoctor(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("UpdateDoctorTimezoneHandler: failed to list upcoming slots: %v", err)
+		return
+	}
+	for _, slot := range slots {
+		err = qtx.SetAvailabilityInstants(dbCtx, repository.SetAvailabilityInstantsParams{
+			StartsAt: timezone.Timestamptz(timezone.At(slot.AvailabilityDate, slot.StartTime, loc)),
+			EndsAt:   timezone.Timestamptz(timezone.At(slot.AvailabilityDate, slot.EndTime, loc)),
+			ID:       slot.ID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update timezone"})
+			log.Printf("UpdateDoctorTimezoneHandler: failed to move slot %v: %v", slot.ID, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update timezone"})
+		log.Printf("UpdateDoctorTimezoneHandler: failed to commit transaction: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DoctorTimezoneResponse{Timezone: loc.String()})
+}
@@ -0,0 +1,369 @@
+// Package review lets patients rate the doctors of their completed bookings
+// and doctors answer the reviews. Admins moderate flagged reviews from the
+// admin package.
+package review
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/bookingstatus"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Review states, see doctor_reviews.status. Published and flagged reviews
+// are shown and count towards the doctor's rating.
+const (
+	StatusPublished = "published"
+	StatusFlagged   = "flagged" // reported, waiting for an admin
+	StatusHidden    = "hidden"  // removed by an admin
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type CreateReviewRequest struct {
+	Rating int16   `json:"rating" binding:"required,min=1,max=5"`
+	Review *string `json:"review" binding:"omitempty,max=2000"`
+}
+
+type ReplyRequest struct {
+	Reply string `json:"reply" binding:"max=2000"` // an empty reply removes it
+}
+
+type FlagRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type ReviewResponse struct {
+	ID          pgtype.UUID        `json:"id"`
+	BookingID   pgtype.UUID        `json:"booking_id"`
+	DoctorID    pgtype.UUID        `json:"doctor_id"`
+	Rating      int16              `json:"rating"`
+	Review      *string            `json:"review"`
+	PatientName string             `json:"patient_name,omitempty"` // first name only
+	DoctorReply *string            `json:"doctor_reply"`
+	RepliedAt   pgtype.Timestamptz `json:"replied_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+func newReviewResponse(review repository.DoctorReview) ReviewResponse {
+	return ReviewResponse{
+		ID:          review.ID,
+		BookingID:   review.BookingID,
+		DoctorID:    review.DoctorID,
+		Rating:      review.Rating,
+		Review:      review.Review,
+		DoctorReply: review.DoctorReply,
+		RepliedAt:   review.RepliedAt,
+		CreatedAt:   review.CreatedAt,
+	}
+}
+
+func firstName(name *string) string {
+	if name == nil {
+		return ""
+	}
+	if fields := strings.Fields(*name); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+func callerID(ctx *gin.Context) (pgtype.UUID, bool) {
+	id, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		return pgtype.UUID{}, false
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, true
+}
+
+func parseID(ctx *gin.Context, param, name string) (pgtype.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " ID"})
+		return pgtype.UUID{}, false
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, true
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint
+// violation, raised when a booking is reviewed twice.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// CreateReviewHandler records the patient's rating of a completed booking.
+// Each booking can be reviewed once.
+func CreateReviewHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bookingID, ok := parseID(ctx, "bookingId", "booking")
+	if !ok {
+		return
+	}
+	userID, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+
+	var req CreateReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Review != nil {
+		text := strings.TrimSpace(*req.Review)
+		req.Review = &text
+		if text == "" {
+			req.Review = nil
+		}
+	}
+
+	booking, err := queries.GetBookingByID(dbCtx, bookingID)
+	if err != nil || booking.UserID != userID {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	if booking.Status != bookingstatus.Completed {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Only completed bookings can be reviewed",
+			"status": booking.Status,
+		})
+		return
+	}
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("CreateReviewHandler: failed to begin transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(dbCtx)
+	qtx := queries.WithTx(tx)
+
+	review, err := qtx.CreateDoctorReview(dbCtx, repository.CreateDoctorReviewParams{
+		BookingID: booking.ID,
+		DoctorID:  booking.DoctorID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Review:    req.Review,
+	})
+	if isUniqueViolation(err) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Booking has already been reviewed"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		log.Printf("CreateReviewHandler: failed to create review: %v", err)
+		return
+	}
+	if err := qtx.RefreshDoctorRating(dbCtx, booking.DoctorID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		log.Printf("CreateReviewHandler: failed to refresh rating: %v", err)
+		return
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		log.Printf("CreateReviewHandler: failed to commit transaction: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newReviewResponse(review))
+}
+
+// ListDoctorReviewsHandler lists the doctor's visible reviews, newest first,
+// with the doctor's rating.
+func ListDoctorReviewsHandler(ctx *gin.Context, queries *repository.Queries) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doctorID, ok := parseID(ctx, "doctorId", "doctor")
+	if !ok {
+		return
+	}
+
+	pageNumber, pageSize := int32(1), int32(defaultPageSize)
+	if n, err := strconv.Atoi(ctx.Query("page")); err == nil && n > 0 {
+		pageNumber = int32(n)
+	}
+	if n, err := strconv.Atoi(ctx.Query("page_size")); err == nil && n > 0 {
+		pageSize = int32(min(n, maxPageSize))
+	}
+
+	doctor, err := queries.GetDoctorByID(dbCtx, doctorID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && (doctor.VerificationStatus != middleware.DoctorVerified || doctor.DeactivatedAt.Valid)) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Doctor not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		log.Printf("ListDoctorReviewsHandler: failed to load doctor: %v", err)
+		return
+	}
+
+	reviews, err := queries.ListVisibleDoctorReviews(dbCtx, repository.ListVisibleDoctorReviewsParams{
+		DoctorID:   doctorID,
+		PageSize:   pageSize,
+		PageOffset: (pageNumber - 1) * pageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reviews"})
+		log.Printf("ListDoctorReviewsHandler: failed to list reviews: %v", err)
+		return
+	}
+	total, err := queries.CountVisibleDoctorReviews(dbCtx, doctorID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reviews"})
+		log.Printf("ListDoctorReviewsHandler: failed to count reviews: %v", err)
+		return
+	}
+
+	items := make([]ReviewResponse, len(reviews))
+	for i, r := range reviews {
+		items[i] = ReviewResponse{
+			ID:          r.ID,
+			BookingID:   r.BookingID,
+			DoctorID:    r.DoctorID,
+			Rating:      r.Rating,
+			Review:      r.Review,
+			PatientName: firstName(r.PatientName),
+			DoctorReply: r.DoctorReply,
+			RepliedAt:   r.RepliedAt,
+			CreatedAt:   r.CreatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"rating_average": doctor.RatingAverage,
+		"rating_count":   doctor.RatingCount,
+		"items":          items,
+		"page":           pageNumber,
+		"page_size":      pageSize,
+		"total":          total,
+	})
+}
+
+// ReplyToReviewHandler sets the doctor's public reply to a review of them.
+func ReplyToReviewHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, ok := parseID(ctx, "doctorId", "doctor")
+	if !ok {
+		return
+	}
+	reviewID, ok := parseID(ctx, "reviewId", "review")
+	if !ok {
+		return
+	}
+
+	var req ReplyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var reply *string
+	if text := strings.TrimSpace(req.Reply); text != "" {
+		reply = &text
+	}
+
+	review, err := queries.SetDoctorReviewReply(ctx, repository.SetDoctorReviewReplyParams{
+		DoctorReply: reply,
+		ID:          reviewID,
+		DoctorID:    doctorID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reply"})
+		log.Printf("ReplyToReviewHandler: failed to save reply: %v", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newReviewResponse(review))
+}
+
+// FlagReviewHandler reports a review to the admins. Patients may flag any
+// review, doctors only reviews of themselves. The review stays visible
+// until an admin hides it, and a review an admin kept cannot be flagged
+// again.
+func FlagReviewHandler(ctx *gin.Context, queries *repository.Queries) {
+	doctorID, ok := parseID(ctx, "doctorId", "doctor")
+	if !ok {
+		return
+	}
+	reviewID, ok := parseID(ctx, "reviewId", "review")
+	if !ok {
+		return
+	}
+	flaggedBy, ok := callerID(ctx)
+	if !ok {
+		return
+	}
+	if ctx.GetString("role") == middleware.RoleDoctor && flaggedBy != doctorID {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "access to this resource is not allowed"})
+		return
+	}
+
+	var req FlagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	review, err := queries.GetDoctorReview(ctx, reviewID)
+	if err != nil || review.DoctorID != doctorID || review.Status == StatusHidden {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	switch {
+	case review.Status == StatusFlagged:
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Review has already been flagged"})
+		return
+	case review.ModeratedAt.Valid:
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Review has already been moderated"})
+		return
+	}
+
+	// Another flag or a moderation may have got in since the review was
+	// read; the update only applies to a review that is still unmoderated.
+	flagged, err := queries.FlagDoctorReview(ctx, repository.FlagDoctorReviewParams{
+		FlagReason: &reason,
+		FlaggedBy:  flaggedBy,
+		ID:         reviewID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to flag review"})
+		log.Printf("FlagReviewHandler: failed to flag review: %v", err)
+		return
+	}
+	if flagged == 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Review has already been flagged or moderated"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Review flagged for moderation"})
+}
@@ -0,0 +1,241 @@
+package user
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
+)
+
+// Orders of the doctor directory. A leading "-" sorts descending.
+var doctorSorts = map[string]bool{
+	"fee":            true,
+	"-fee":           true,
+	"experience":     true,
+	"-experience":    true,
+	"-rating":        true, // best rated first, unrated last
+	"next_available": true,
+	"relevance":      true, // needs q
+}
+
+// likeEscaper escapes the LIKE wildcards of a search term, with the
+// backslash as escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type DoctorSearchResponse struct {
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	Specialization  string         `json:"specialization"`
+	Experience      int32          `json:"experience"`
+	Qualification   string         `json:"qualification"`
+	HospitalName    string         `json:"hospital_name"`
+	ConsultationFee pgtype.Numeric `json:"consultation_fee"`
+	RatingAverage   pgtype.Numeric `json:"rating_average"` // null until the first review
+	RatingCount     int32          `json:"rating_count"`
+	NextAvailableAt *time.Time     `json:"next_available_at"` // earliest free slot, null when there is none
+	Timezone        string         `json:"timezone"`
+}
+
+// searchCursor is the position after the last doctor of a page. It is bound
+// to the sort order it was issued for.
+type searchCursor struct {
+	Sort string
+	Key  float64
+	ID   pgtype.UUID
+}
+
+func (c searchCursor) encode() string {
+	raw := c.Sort + "|" + strconv.FormatFloat(c.Key, 'g', -1, 64) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSearchCursor(value string) (searchCursor, error) {
+	invalid := errors.New("invalid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return searchCursor{}, invalid
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return searchCursor{}, invalid
+	}
+	key, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return searchCursor{}, invalid
+	}
+	id, err := uuid.Parse(parts[2])
+	if err != nil {
+		return searchCursor{}, invalid
+	}
+	return searchCursor{Sort: parts[0], Key: key, ID: pgtype.UUID{Bytes: id, Valid: true}}, nil
+}
+
+// parseDoctorSearch reads the directory filters from the query string.
+func parseDoctorSearch(ctx *gin.Context) (repository.CountSearchDoctorsParams, error) {
+	var filters repository.CountSearchDoctorsParams
+	optional := func(key string) *string {
+		value := strings.TrimSpace(ctx.Query(key))
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+	filters.Query = optional("q")
+	if name := optional("name"); name != nil {
+		// name matches as a substring, so its wildcards are taken literally.
+		escaped := likeEscaper.Replace(*name)
+		filters.Name = &escaped
+	}
+	filters.Specialization = optional("specialization")
+	filters.HospitalName = optional("hospital_name")
+
+	if value := optional("min_experience"); value != nil {
+		n, err := strconv.Atoi(*value)
+		if err != nil || n < 0 {
+			return filters, errors.New("min_experience must be a non-negative number of years")
+		}
+		years := int32(n)
+		filters.MinExperience = &years
+	}
+	for key, target := range map[string]**float64{"min_fee": &filters.MinFee, "max_fee": &filters.MaxFee} {
+		if value := optional(key); value != nil {
+			fee, err := strconv.ParseFloat(*value, 64)
+			if err != nil || fee < 0 || math.IsInf(fee, 0) || math.IsNaN(fee) {
+				return filters, errors.New(key + " must be a non-negative amount")
+			}
+			*target = &fee
+		}
+	}
+	if filters.MinFee != nil && filters.MaxFee != nil && *filters.MinFee > *filters.MaxFee {
+		return filters, errors.New("min_fee must not be greater than max_fee")
+	}
+	if value := optional("available_on"); value != nil {
+		date, err := time.Parse("2006-01-02", *value)
+		if err != nil {
+			return filters, errors.New("available_on must be a YYYY-MM-DD date")
+		}
+		filters.AvailableOn = pgtype.Date{Time: date, Valid: true}
+	}
+	return filters, nil
+}
+
+// ListDoctorsHandler searches the directory of verified doctors.
+//
+// q is a full-text search over name, specialization and hospital;
+// specialization and hospital_name match exactly, ignoring case, and name
+// matches any part of the name. min_experience, min_fee, max_fee and
+// available_on (a date with a free slot, on the doctor's calendar) narrow
+// the results further. sort is one of fee, -fee, experience, -experience,
+// -rating, next_available and relevance. Pages are fetched with the next_cursor of
+// the previous page.
+func ListDoctorsHandler(ctx *gin.Context, queries *repository.Queries) {
+	filters, err := parseDoctorSearch(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sort := ctx.Query("sort")
+	if sort == "" {
+		sort = "next_available"
+		if filters.Query != nil {
+			sort = "relevance"
+		}
+	}
+	if !doctorSorts[sort] {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "sort must be one of fee, -fee, experience, -experience, -rating, next_available, relevance"})
+		return
+	}
+	if sort == "relevance" && filters.Query == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "sort=relevance needs a search query q"})
+		return
+	}
+
+	pageSize := defaultSearchPageSize
+	if n, err := strconv.Atoi(ctx.Query("page_size")); err == nil && n > 0 {
+		pageSize = min(n, maxSearchPageSize)
+	}
+
+	params := repository.SearchDoctorsParams{
+		SortBy:         sort,
+		Query:          filters.Query,
+		Name:           filters.Name,
+		Specialization: filters.Specialization,
+		HospitalName:   filters.HospitalName,
+		MinExperience:  filters.MinExperience,
+		MinFee:         filters.MinFee,
+		MaxFee:         filters.MaxFee,
+		AvailableOn:    filters.AvailableOn,
+		PageSize:       int32(pageSize) + 1, // one more to tell whether there is a next page
+	}
+	if value := ctx.Query("cursor"); value != "" {
+		cursor, err := decodeSearchCursor(value)
+		if err != nil || cursor.Sort != sort {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		params.AfterKey = &cursor.Key
+		params.AfterID = cursor.ID
+	}
+
+	doctors, err := queries.SearchDoctors(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve doctors"})
+		log.Printf("ListDoctorsHandler: failed to search doctors: %v", err)
+		return
+	}
+	total, err := queries.CountSearchDoctors(ctx, filters)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve doctors"})
+		log.Printf("ListDoctorsHandler: failed to count doctors: %v", err)
+		return
+	}
+
+	var nextCursor *string
+	if len(doctors) > pageSize {
+		doctors = doctors[:pageSize]
+		last := doctors[pageSize-1]
+		cursor := searchCursor{Sort: sort, Key: last.SortKey, ID: last.ID}.encode()
+		nextCursor = &cursor
+	}
+
+	items := make([]DoctorSearchResponse, len(doctors))
+	for i, doctor := range doctors {
+		loc := timezone.Stored(doctor.Timezone)
+		items[i] = DoctorSearchResponse{
+			ID:              doctor.ID.String(),
+			Name:            doctor.Name,
+			Specialization:  doctor.Specialization,
+			Experience:      doctor.Experience,
+			Qualification:   doctor.Qualification,
+			HospitalName:    doctor.HospitalName,
+			ConsultationFee: doctor.ConsultationFee,
+			RatingAverage:   doctor.RatingAverage,
+			RatingCount:     doctor.RatingCount,
+			NextAvailableAt: timezone.In(doctor.NextAvailableAt, loc),
+			Timezone:        loc.String(),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"items":       items,
+		"page_size":   pageSize,
+		"total":       total,
+		"next_cursor": nextCursor,
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,6 +22,7 @@ type UpdateUserRequest struct {
 	BloodGroup               *string `json:"blood_group"`
 	EmergencyContactNumber   *string `json:"emergency_contact_number"`
 	EmergencyContactRelation *string `json:"emergency_contact_relationship"`
+	Timezone                 *string `json:"timezone"` // IANA name such as Europe/Berlin
 }
 
 type AvailabilityResponse struct {
@@ -40,22 +43,32 @@ type UserProfileResponse struct {
 	BloodGroup                   string `json:"blood_group,omitempty"`
 	EmergencyContactNumber       string `json:"emergency_contact_number,omitempty"`
 	EmergencyContactRelationship string `json:"emergency_contact_relationship,omitempty"`
+	Timezone                     string `json:"timezone"`
 }
 
 type DoctorResponse struct {
-	ID             string                 `json:"id"`
-	Name           string                 `json:"name"`
-	Specialization string                 `json:"specialization"`
-	Experience     int32                  `json:"experience"`
-	Qualification  string                 `json:"qualification"`
-	HospitalName   string                 `json:"hospital_name"`
-	Availability   []AvailabilityResponse `json:"availability,omitempty"`
+	ID              string                 `json:"id"`
+	Name            string                 `json:"name"`
+	Specialization  string                 `json:"specialization"`
+	Experience      int32                  `json:"experience"`
+	Qualification   string                 `json:"qualification"`
+	HospitalName    string                 `json:"hospital_name"`
+	ConsultationFee pgtype.Numeric         `json:"consultation_fee"`
+	RatingAverage   pgtype.Numeric         `json:"rating_average"`
+	RatingCount     int32                  `json:"rating_count"`
+	Bio             *string                `json:"bio,omitempty"`
+	Languages       []string               `json:"languages"`
+	ClinicAddress   *string                `json:"clinic_address,omitempty"`
+	ClinicLatitude  *float64               `json:"clinic_latitude,omitempty"`
+	ClinicLongitude *float64               `json:"clinic_longitude,omitempty"`
+	PhotoURL        *string                `json:"photo_url,omitempty"`
+	Availability    []AvailabilityResponse `json:"availability,omitempty"`
 }
 
 type CreateMedicationRequest struct {
 	MedicationName string `json:"medication_name" binding:"required"`
 	Dosage         string `json:"dosage" binding:"required"`
-	TimeToNotify   string `json:"time_to_notify" binding:"required"`
+	TimeToNotify   string `json:"time_to_notify" binding:"required"`               // HH:MM:SS on the user's clock
 	Frequency      string `json:"frequency" binding:"required,oneof=daily weekly"` // Use oneof for validation
 }
 
@@ -98,6 +111,19 @@ func UpdateUserProfile(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
+	var newLoc, oldLoc *time.Location
+	if req.Timezone != nil {
+		if newLoc, err = timezone.Load(*req.Timezone); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if oldLoc, err = timezone.User(ctx, queries, pgUUID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user service"})
+			log.Printf("update user profile failed -> loading timezone : { %v }", err)
+			return
+		}
+	}
+
 	err = queries.UpdateUserProfile(context.Background(), repository.UpdateUserProfileParams{
 		ID:                           pgUUID,
 		Name:                         req.Name,
@@ -106,6 +132,7 @@ func UpdateUserProfile(ctx *gin.Context, queries *repository.Queries) {
 		Gender:                       req.Gender,
 		EmergencyContactNumber:       req.EmergencyContactNumber,
 		EmergencyContactRelationship: req.EmergencyContactRelation,
+		Timezone:                     req.Timezone,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user service"})
@@ -113,6 +140,12 @@ func UpdateUserProfile(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
+	if newLoc != nil && newLoc.String() != oldLoc.String() {
+		if err := rescheduleMedications(ctx, queries, pgUUID, oldLoc, newLoc); err != nil {
+			log.Printf("update user profile: failed to reschedule medications : { %v }", err)
+		}
+	}
+
 	updatedUser, err := queries.GetUserByID(context.Background(), pgUUID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch updated user "})
@@ -130,35 +163,12 @@ func UpdateUserProfile(ctx *gin.Context, queries *repository.Queries) {
 			"gender":                         updatedUser.Gender,
 			"emergency_contact_number":       updatedUser.EmergencyContactNumber,
 			"emergency_contact_relationship": updatedUser.EmergencyContactRelationship,
+			"timezone":                       updatedUser.Timezone,
 		},
 	})
 
 }
 
-func ListDoctorsHandler(ctx *gin.Context, queries *repository.Queries) {
-
-	doctors, err := queries.ListDoctors(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve doctors"})
-		return
-	}
-
-	resp := make([]DoctorResponse, len(doctors))
-	for i, doctor := range doctors {
-		resp[i] = DoctorResponse{
-			ID:             doctor.ID.String(),
-			Name:           doctor.Name,
-			Specialization: doctor.Specialization,
-			Experience:     doctor.Experience,
-			Qualification:  doctor.Qualification,
-			HospitalName:   doctor.HospitalName,
-		}
-	}
-
-	ctx.JSON(http.StatusOK, resp)
-
-}
-
 func GetDoctorByIDHandler(ctx *gin.Context, queries *repository.Queries) {
 	log.Println("GetDoctorByIDHandler: Request received")
 	doctorIDStr := ctx.Param("doctorId")
@@ -177,6 +187,13 @@ func GetDoctorByIDHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
+	// Doctors awaiting review and deactivated doctors are not part of the
+	// directory.
+	if doctor.VerificationStatus != middleware.DoctorVerified || doctor.DeactivatedAt.Valid {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Doctor not found"})
+		return
+	}
+
 	availability, err := queries.GetDoctorAvailabilityByDoctor(ctx, parsedDoctorID) // Assuming you have this function
 	if err != nil {
 		log.Printf("Error fetching availability: %v", err)
@@ -196,13 +213,30 @@ func GetDoctorByIDHandler(ctx *gin.Context, queries *repository.Queries) {
 	}
 
 	resp := DoctorResponse{
-		ID:             doctor.ID.String(),
-		Name:           doctor.Name,
-		Specialization: doctor.Specialization,
-		Experience:     doctor.Experience,
-		Qualification:  doctor.Qualification,
-		HospitalName:   doctor.HospitalName,
-		Availability:   availabilityResponses,
+		ID:              doctor.ID.String(),
+		Name:            doctor.Name,
+		Specialization:  doctor.Specialization,
+		Experience:      doctor.Experience,
+		Qualification:   doctor.Qualification,
+		HospitalName:    doctor.HospitalName,
+		ConsultationFee: doctor.ConsultationFee,
+		RatingAverage:   doctor.RatingAverage,
+		RatingCount:     doctor.RatingCount,
+		Bio:             doctor.Bio,
+		Languages:       doctor.Languages,
+		ClinicAddress:   doctor.ClinicAddress,
+		ClinicLatitude:  doctor.ClinicLatitude,
+		ClinicLongitude: doctor.ClinicLongitude,
+		Availability:    availabilityResponses,
+	}
+	if resp.Languages == nil {
+		resp.Languages = []string{}
+	}
+	if hasPhoto, err := queries.DoctorHasProfilePhoto(ctx, parsedDoctorID); err != nil {
+		log.Printf("GetDoctorByIDHandler: failed to look up photo: %v", err)
+	} else if hasPhoto {
+		url := "/doctors/" + doctor.ID.String() + "/photo"
+		resp.PhotoURL = &url
 	}
 
 	ctx.JSON(http.StatusOK, resp)
@@ -236,6 +270,7 @@ func GetUserProfile(ctx *gin.Context, queries *repository.Queries) {
 		BloodGroup:                   safeDerefString(user.BloodGroup),
 		EmergencyContactNumber:       safeDerefString(user.EmergencyContactNumber),
 		EmergencyContactRelationship: safeDerefString(user.EmergencyContactRelationship),
+		Timezone:                     user.Timezone,
 	}
 
 	ctx.JSON(http.StatusOK, resp)
@@ -258,42 +293,34 @@ func CreateMedicationHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
-	// Validate TimeToNotify format
-	_, err = time.Parse("15:04:05", req.TimeToNotify)
+	// TimeToNotify is read on the user's clock
+	timeToNotify, err := time.Parse("15:04:05", req.TimeToNotify)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_to_notify format. Use HH:MM:SS"})
 		log.Printf("CreateMedicationHandler: Invalid time format: %v", err)
 		return
 	}
-
-	// Parse time in IST
-	ist, err := time.LoadLocation("Asia/Kolkata") // Load IST location
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load IST location"})
-		log.Printf("CreateMedicationHandler: Failed to load IST location: %v", err)
-		return
+	pgxTime := pgtype.Time{
+		Microseconds: int64((timeToNotify.Hour()*3600 + timeToNotify.Minute()*60 + timeToNotify.Second()) * 1e6),
+		Valid:        true,
 	}
 
-	timeToNotify, err := time.ParseInLocation("15:04:05", req.TimeToNotify, ist) // Parse with IST location
+	parsedUserID := pgtype.UUID{Bytes: userID, Valid: true}
+	loc, err := timezone.User(ctx, queries, parsedUserID)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_to_notify format. Use HH:MM:SS in IST"})
-		log.Printf("CreateMedicationHandler: Invalid time format: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load the user's timezone"})
+		log.Printf("CreateMedicationHandler: Failed to load timezone: %v", err)
 		return
 	}
-
-	// Convert time to pgtype.Time (UTC)
-	utcTime := timeToNotify.UTC() // convert time to UTC for storing in database.
-	pgxTime := pgtype.Time{
-		Microseconds: int64((utcTime.Hour()*3600 + utcTime.Minute()*60 + utcTime.Second()) * 1e6),
-		Valid:        true,
-	}
+	nextNotifyAt := timezone.Next(pgxTime, loc, time.Now(), timezone.IntervalDays(req.Frequency))
 
 	medication, err := queries.CreateMedication(ctx, repository.CreateMedicationParams{
-		UserID:         pgtype.UUID{Bytes: userID, Valid: true},
+		UserID:         parsedUserID,
 		MedicationName: req.MedicationName,
 		Dosage:         req.Dosage,
 		TimeToNotify:   pgxTime,
 		Frequency:      req.Frequency,
+		NextNotifyAt:   timezone.Timestamptz(nextNotifyAt),
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create medication"})
@@ -301,14 +328,15 @@ func CreateMedicationHandler(ctx *gin.Context, queries *repository.Queries) {
 		return
 	}
 
-	readableTime := time.Unix(0, medication.TimeToNotify.Microseconds*1000).UTC().Format("15:04:05")
 	response := gin.H{
 		"medication": gin.H{
 			"ID":             medication.ID,
 			"UserID":         medication.UserID,
 			"MedicationName": medication.MedicationName,
 			"Dosage":         medication.Dosage,
-			"TimeToNotify":   readableTime, // Use the readable time
+			"TimeToNotify":   utils.FormatTime(medication.TimeToNotify), // on the user's clock
+			"Timezone":       loc.String(),
+			"NextNotifyAt":   timezone.In(medication.NextNotifyAt, loc),
 			"Frequency":      medication.Frequency,
 			"IsReadbyuser":   medication.IsReadbyuser,
 			"CreatedAt":      medication.CreatedAt,
@@ -321,8 +349,16 @@ func CreateMedicationHandler(ctx *gin.Context, queries *repository.Queries) {
 }
 
 func MarkMedicationAsReadHandler(ctx *gin.Context, queries *repository.Queries) {
+	userIDStr := ctx.Param("user_id")
 	medicationIDStr := ctx.Param("medication_id")
 
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		log.Printf("MarkMedicationAsReadHandler: Invalid user ID: %v", err)
+		return
+	}
+
 	medicationID, err := uuid.Parse(medicationIDStr)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid medication ID"})
@@ -330,6 +366,13 @@ func MarkMedicationAsReadHandler(ctx *gin.Context, queries *repository.Queries)
 		return
 	}
 
+	medication, err := queries.GetMedicationByID(ctx, pgtype.UUID{Bytes: medicationID, Valid: true})
+	if err != nil || medication.UserID != (pgtype.UUID{Bytes: userID, Valid: true}) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Medication not found"})
+		log.Printf("MarkMedicationAsReadHandler: medication %s not found for user %s: %v", medicationIDStr, userIDStr, err)
+		return
+	}
+
 	err = queries.UpdateMedicationReadStatus(ctx, pgtype.UUID{Bytes: medicationID, Valid: true})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark medication as read"})
@@ -356,15 +399,23 @@ func GetMedicationsByUserIDHandler(ctx *gin.Context, queries *repository.Queries
 		return
 	}
 
+	loc, err := timezone.User(ctx, queries, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve medications"})
+		log.Printf("GetMedicationsByUserIDHandler: Failed to load timezone: %v", err)
+		return
+	}
+
 	// Format the response
 	response := make([]gin.H, len(medications))
 	for i, medication := range medications {
-		readableTime := time.Unix(0, medication.TimeToNotify.Microseconds*1000).UTC().Format("15:04:05") //Format time
 		response[i] = gin.H{
 			"ID":             medication.ID,
 			"MedicationName": medication.MedicationName,
 			"Dosage":         medication.Dosage,
-			"TimeToNotify":   readableTime, // Format time
+			"TimeToNotify":   utils.FormatTime(medication.TimeToNotify), // on the user's clock
+			"Timezone":       loc.String(),
+			"NextNotifyAt":   timezone.In(medication.NextNotifyAt, loc),
 			"Frequency":      medication.Frequency,
 			"IsReadbyuser":   medication.IsReadbyuser,
 			"CreatedAt":      medication.CreatedAt,
@@ -374,3 +425,29 @@ func GetMedicationsByUserIDHandler(ctx *gin.Context, queries *repository.Queries
 
 	ctx.JSON(http.StatusOK, gin.H{"medications": response})
 }
+
+// rescheduleMedications keeps the user's reminders at the same time on their
+// new clock, and weekly ones on the same weekday.
+func rescheduleMedications(ctx context.Context, queries *repository.Queries, userID pgtype.UUID, oldLoc, newLoc *time.Location) error {
+	medications, err := queries.GetMedicationsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, medication := range medications {
+		date, _ := timezone.Wall(medication.NextNotifyAt.Time, oldLoc)
+		next := timezone.At(date, medication.TimeToNotify, newLoc)
+		if !next.After(now) {
+			next = timezone.Next(medication.TimeToNotify, newLoc, next, timezone.IntervalDays(medication.Frequency))
+		}
+		err := queries.SetMedicationNextNotifyAt(ctx, repository.SetMedicationNextNotifyAtParams{
+			ID:           medication.ID,
+			NextNotifyAt: timezone.Timestamptz(next),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Package loginguard throttles password logins. Failed attempts are counted
+// per account and per client IP; after a few free attempts every further
+// failure blocks the key for an exponentially growing delay, up to a
+// temporary lockout.
+package loginguard
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+)
+
+// Attempts is the failure state stored for one key.
+type Attempts struct {
+	Failures     int
+	LastFailure  time.Time
+	BlockedUntil time.Time
+}
+
+// AttemptStore persists failure counters.
+type AttemptStore interface {
+	// Get returns the zero Attempts when nothing is stored for key.
+	Get(ctx context.Context, key string) (Attempts, error)
+	// Increment records a failure. The count restarts at 1 when the previous
+	// failure is older than window.
+	Increment(ctx context.Context, key string, window time.Duration) (Attempts, error)
+	Block(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Policy describes how quickly a key gets throttled.
+type Policy struct {
+	FreeAttempts    int           // failures allowed without any delay
+	BaseDelay       time.Duration // delay after the first failure beyond FreeAttempts, doubled for each further one
+	LockoutAfter    int           // failures after which the key is locked for LockoutDuration
+	LockoutDuration time.Duration
+	Window          time.Duration // failures older than this are forgotten
+}
+
+// Delay returns how long a key with the given number of failures is blocked.
+func (p Policy) Delay(failures int) time.Duration {
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	if failures >= p.LockoutAfter {
+		return p.LockoutDuration
+	}
+	delay := p.BaseDelay << (failures - p.FreeAttempts - 1)
+	if delay <= 0 || delay > p.LockoutDuration {
+		return p.LockoutDuration
+	}
+	return delay
+}
+
+var (
+	// DefaultAccountPolicy protects a single account against password
+	// guessing.
+	DefaultAccountPolicy = Policy{
+		FreeAttempts:    3,
+		BaseDelay:       2 * time.Second,
+		LockoutAfter:    10,
+		LockoutDuration: 15 * time.Minute,
+		Window:          15 * time.Minute,
+	}
+	// DefaultIPPolicy is looser since several people may share an address.
+	DefaultIPPolicy = Policy{
+		FreeAttempts:    10,
+		BaseDelay:       time.Second,
+		LockoutAfter:    50,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+)
+
+// Guard applies the account and IP policies on top of a store.
+type Guard struct {
+	Store   AttemptStore
+	Account Policy
+	IP      Policy
+}
+
+func New(store AttemptStore) *Guard {
+	return &Guard{Store: store, Account: DefaultAccountPolicy, IP: DefaultIPPolicy}
+}
+
+// FromEnv picks the store from LOGIN_ATTEMPT_STORE: "postgres" (default,
+// shared between instances) or "memory" (single instance only).
+func FromEnv(queries *repository.Queries) *Guard {
+	if os.Getenv("LOGIN_ATTEMPT_STORE") == "memory" {
+		return New(NewMemoryStore())
+	}
+	return New(NewPostgresStore(queries))
+}
+
+// AccountKey identifies an account of a role by email.
+func AccountKey(role, email string) string {
+	return "account:" + role + ":" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// IPKey identifies a client address.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns how long the caller still has to wait before trying again,
+// or 0 when neither key is blocked.
+func (g *Guard) Check(ctx context.Context, accountKey, ipKey string) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range []string{accountKey, ipKey} {
+		attempts, err := g.Store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if remaining := attempts.BlockedUntil.Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait, nil
+}
+
+// Fail records a failed login and returns how long the caller is now
+// blocked, or 0.
+func (g *Guard) Fail(ctx context.Context, accountKey, ipKey string) (time.Duration, error) {
+	var wait time.Duration
+	for _, k := range []struct {
+		key    string
+		policy Policy
+	}{{accountKey, g.Account}, {ipKey, g.IP}} {
+		attempts, err := g.Store.Increment(ctx, k.key, k.policy.Window)
+		if err != nil {
+			return 0, err
+		}
+		delay := k.policy.Delay(attempts.Failures)
+		if delay == 0 {
+			continue
+		}
+		if err := g.Store.Block(ctx, k.key, time.Now().Add(delay)); err != nil {
+			return 0, err
+		}
+		if delay > wait {
+			wait = delay
+		}
+	}
+	return wait, nil
+}
+
+// Succeed clears the account counter. The IP counter is left alone so a
+// single valid account cannot be used to reset guessing against others.
+func (g *Guard) Succeed(ctx context.Context, accountKey string) error {
+	return g.Store.Reset(ctx, accountKey)
+}
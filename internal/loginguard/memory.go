@@ -0,0 +1,71 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps counters in process memory. Counters are lost on
+// restart and not shared between instances.
+type MemoryStore struct {
+	mu        sync.Mutex
+	attempts  map[string]Attempts
+	maxWindow time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: make(map[string]Attempts)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[key], nil
+}
+
+func (s *MemoryStore) Increment(_ context.Context, key string, window time.Duration) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	a := s.attempts[key]
+	if now.Sub(a.LastFailure) > window {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailure = now
+	s.attempts[key] = a
+	if window > s.maxWindow {
+		s.maxWindow = window
+	}
+	s.prune(now)
+	return a, nil
+}
+
+func (s *MemoryStore) Block(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.attempts[key]
+	a.BlockedUntil = until
+	s.attempts[key] = a
+	return nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// prune drops keys that are neither blocked nor inside the longest window
+// seen so the map does not grow without bound. Callers hold mu.
+func (s *MemoryStore) prune(now time.Time) {
+	for key, a := range s.attempts {
+		if now.Sub(a.LastFailure) > s.maxWindow && now.After(a.BlockedUntil) {
+			delete(s.attempts, key)
+		}
+	}
+}
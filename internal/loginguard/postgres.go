@@ -0,0 +1,61 @@
+package loginguard
+
+import (
+	"context"
+	"time"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PostgresStore keeps counters in the login_attempts table so every
+// instance sees the same state.
+type PostgresStore struct {
+	queries *repository.Queries
+}
+
+func NewPostgresStore(queries *repository.Queries) *PostgresStore {
+	return &PostgresStore{queries: queries}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) (Attempts, error) {
+	row, err := s.queries.GetLoginAttempt(ctx, key)
+	if err == pgx.ErrNoRows {
+		return Attempts{}, nil
+	}
+	if err != nil {
+		return Attempts{}, err
+	}
+	return toAttempts(row), nil
+}
+
+func (s *PostgresStore) Increment(ctx context.Context, key string, window time.Duration) (Attempts, error) {
+	row, err := s.queries.IncrementLoginAttempt(ctx, repository.IncrementLoginAttemptParams{
+		AttemptKey:    key,
+		WindowSeconds: int32(window.Seconds()),
+	})
+	if err != nil {
+		return Attempts{}, err
+	}
+	return toAttempts(row), nil
+}
+
+func (s *PostgresStore) Block(ctx context.Context, key string, until time.Time) error {
+	return s.queries.BlockLoginAttempt(ctx, repository.BlockLoginAttemptParams{
+		AttemptKey:   key,
+		BlockedUntil: pgtype.Timestamptz{Time: until, Valid: true},
+	})
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	return s.queries.DeleteLoginAttempt(ctx, key)
+}
+
+func toAttempts(row repository.LoginAttempt) Attempts {
+	return Attempts{
+		Failures:     int(row.Failures),
+		LastFailure:  row.LastFailureAt.Time,
+		BlockedUntil: row.BlockedUntil.Time,
+	}
+}
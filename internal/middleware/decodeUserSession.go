@@ -3,34 +3,16 @@ package middleware
 import (
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/token"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-var JWTSecretKey []byte
-
-func init() {
-	// Load environment variables from .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Warning: No .env file found")
-	}
-	JWTSecretKey = []byte(os.Getenv("JWT_SECRET")) // Initialize
-}
-
-type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	jwt.StandardClaims
-}
-
-func ValidateJWT() gin.HandlerFunc {
+func ValidateJWT(queries *repository.Queries) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		log.Println("inside middleware")
 		tokenString := ctx.GetHeader("Authorization")
 		if tokenString == "" {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
@@ -38,25 +20,60 @@ func ValidateJWT() gin.HandlerFunc {
 			return
 		}
 
-		tokenString = strings.Replace(tokenString, "Bearer ", "", 1)
-
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return JWTSecretKey, nil
-		})
+		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
+		claims, err := token.Parse(tokenString)
 		if err != nil {
+			log.Printf("ValidateJWT: %v", err)
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			ctx.Abort()
+			return
+		}
+
+		subjectID, err := parseUUID(claims.UserID)
+		if err != nil || claims.IssuedAt == nil {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			ctx.Abort()
 			return
 		}
 
-		if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-			ctx.Set("user_id", claims.UserID)
-			ctx.Set("email", claims.Email)
-			ctx.Next()
-		} else {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+		// Tokens issued before sessions were recorded carry no sid and are
+		// only checked against the other revocations.
+		var sessionID pgtype.UUID
+		if claims.SessionID != "" {
+			if sessionID, err = parseUUID(claims.SessionID); err != nil {
+				ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+				ctx.Abort()
+				return
+			}
+		}
+
+		// Checks the per-token denylist (logout), forced logouts of the whole
+		// account and devices signed out remotely.
+		revoked, err := queries.IsAccessTokenRevoked(ctx, repository.IsAccessTokenRevokedParams{
+			Jti:       claims.ID,
+			SubjectID: subjectID,
+			IssuedAt:  pgtype.Timestamptz{Time: claims.IssuedAt.Time, Valid: true},
+			SessionID: sessionID,
+		})
+		if err != nil {
+			log.Printf("ValidateJWT: failed to check token revocation: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate token"})
 			ctx.Abort()
+			return
 		}
+		if revoked {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set("user_id", claims.UserID)
+		ctx.Set("role", claims.Role)
+		ctx.Set("email", claims.Email)
+		ctx.Set("jti", claims.ID)
+		ctx.Set("session_id", claims.SessionID)
+		ctx.Set("token_expires_at", claims.ExpiresAt.Time)
+		ctx.Next()
 	}
 }
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireDoctorMFA must run after ValidateJWT. Doctors that an admin has
+// required to use two-factor authentication get a 403 until they enroll.
+// Other roles pass through.
+func RequireDoctorMFA(queries *repository.Queries) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString("role") != RoleDoctor {
+			ctx.Next()
+			return
+		}
+
+		callerID, err := parseUUID(ctx.GetString("user_id"))
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+			ctx.Abort()
+			return
+		}
+
+		mfa, err := queries.GetDoctorMfa(ctx, callerID)
+		if err != nil {
+			log.Printf("RequireDoctorMFA: failed to load MFA settings: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			ctx.Abort()
+			return
+		}
+
+		if mfa.MfaRequired && !mfa.MfaEnabledAt.Valid {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "two-factor authentication must be enabled for this account"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// OwnershipException decides whether a caller who does not own the path
+// subject may still access it.
+type OwnershipException func(ctx *gin.Context, callerID, subjectID pgtype.UUID) bool
+
+// RequireOwnership must run after ValidateJWT. It compares the authenticated
+// user_id with the path parameter named param and responds with 403 unless
+// they match or one of the exceptions allows the request.
+func RequireOwnership(param string, exceptions ...OwnershipException) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		callerID, err := parseUUID(ctx.GetString("user_id"))
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+			ctx.Abort()
+			return
+		}
+
+		subjectID, err := parseUUID(ctx.Param(param))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+			ctx.Abort()
+			return
+		}
+
+		if callerID == subjectID {
+			ctx.Next()
+			return
+		}
+
+		for _, allow := range exceptions {
+			if allow(ctx, callerID, subjectID) {
+				ctx.Next()
+				return
+			}
+		}
+
+		log.Printf("RequireOwnership: %s denied access to %s %s", callerID.String(), param, subjectID.String())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "access to this resource is not allowed"})
+		ctx.Abort()
+	}
+}
+
+// DoctorWithPatientBooking lets a doctor access a patient who has booked an
+// appointment with them.
+func DoctorWithPatientBooking(queries *repository.Queries) OwnershipException {
+	return func(ctx *gin.Context, callerID, subjectID pgtype.UUID) bool {
+		if ctx.GetString("role") != RoleDoctor {
+			return false
+		}
+
+		hasBooking, err := queries.DoctorHasBookingWithUser(ctx, repository.DoctorHasBookingWithUserParams{
+			DoctorID: callerID,
+			UserID:   subjectID,
+		})
+		if err != nil {
+			log.Printf("DoctorWithPatientBooking: failed to check bookings: %v", err)
+			return false
+		}
+		return hasBooking
+	}
+}
+
+// RequireBookingOwnership must run after ValidateJWT. It responds with 404,
+// as for a booking that does not exist, unless the booking named by the path
+// parameter param was made by the authenticated user.
+func RequireBookingOwnership(param string, queries *repository.Queries) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		callerID, err := parseUUID(ctx.GetString("user_id"))
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+			ctx.Abort()
+			return
+		}
+
+		bookingID, err := parseUUID(ctx.Param(param))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+			ctx.Abort()
+			return
+		}
+
+		booking, err := queries.GetBookingByID(ctx, bookingID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("RequireBookingOwnership: failed to load booking: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			ctx.Abort()
+			return
+		}
+		if err != nil || booking.UserID != callerID {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// IsCaller reports whether id is the user_id of the authenticated caller.
+func IsCaller(ctx *gin.Context, id pgtype.UUID) bool {
+	callerID, err := parseUUID(ctx.GetString("user_id"))
+	if err != nil {
+		return false
+	}
+	return id.Valid && callerID == id
+}
+
+func parseUUID(s string) (pgtype.UUID, error) {
+	parsed, err := uuid.Parse(s)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}, nil
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles embedded in the JWT by token.Generate.
+const (
+	RoleUser   = "user"
+	RoleDoctor = "doctor"
+	RoleAdmin  = "admin"
+)
+
+// RequireRole must run after ValidateJWT. It rejects the request with 403
+// unless the role claim of the token is one of the given roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role := ctx.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				ctx.Next()
+				return
+			}
+		}
+
+		log.Printf("RequireRole: role %q not allowed for %s", role, ctx.FullPath())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		ctx.Abort()
+	}
+}
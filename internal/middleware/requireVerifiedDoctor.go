@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Doctor verification states, see doctors.verification_status.
+const (
+	DoctorPendingVerification = "pending_verification"
+	DoctorVerified            = "verified"
+	DoctorRejected            = "rejected"
+)
+
+// RequireVerifiedDoctor must run after ValidateJWT and RequireRole(RoleDoctor).
+// Doctors whose credentials have not been approved by an admin get a 403.
+func RequireVerifiedDoctor(queries *repository.Queries) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		callerID, err := parseUUID(ctx.GetString("user_id"))
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+			ctx.Abort()
+			return
+		}
+
+		status, err := queries.GetDoctorVerificationStatus(ctx, callerID)
+		if err != nil {
+			log.Printf("RequireVerifiedDoctor: failed to load verification status: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			ctx.Abort()
+			return
+		}
+
+		if status != DoctorVerified {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":               "doctor credentials have not been verified",
+				"verification_status": status,
+			})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,335 @@
+// Package oidc signs users in with external OpenID Connect providers. It
+// runs the authorization code flow with PKCE and verifies the returned ID
+// token against the provider's published keys.
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MicahParks/keyfunc"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+// Identity is what the provider asserts about the signed in user.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider is implemented by Client. Tests can swap in a fake or point a
+// Client at a local OIDC server.
+type Provider interface {
+	Name() string
+	// AllowsRole reports whether accounts of the given role ("user" or
+	// "doctor") may sign in through this provider.
+	AllowsRole(role string) bool
+	// AuthCodeURL returns the URL the browser is sent to. The PKCE challenge
+	// is derived from verifier.
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	// Exchange trades the authorization code for tokens and returns the
+	// identity from the verified ID token.
+	Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error)
+}
+
+// ClaimMapping names the ID token claims that carry each identity field,
+// for providers that do not use the standard names.
+type ClaimMapping struct {
+	Subject       string
+	Email         string
+	EmailVerified string
+	Name          string
+}
+
+// DefaultClaims are the standard OIDC claim names.
+var DefaultClaims = ClaimMapping{
+	Subject:       "sub",
+	Email:         "email",
+	EmailVerified: "email_verified",
+	Name:          "name",
+}
+
+type Config struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Roles        []string // account roles allowed to sign in, see AllowsRole
+	Claims       ClaimMapping
+	// TrustEmail treats every email from this provider as verified, for
+	// identity providers that do not send email_verified.
+	TrustEmail bool
+
+	// Issuer is used for discovery of the endpoints below through
+	// <Issuer>/.well-known/openid-configuration. Endpoints that are set
+	// explicitly are not discovered.
+	Issuer   string
+	AuthURL  string
+	TokenURL string
+	JWKSURL  string
+	Issuers  []string // accepted "iss" values, defaults to Issuer
+}
+
+// Google returns the configuration for Google sign-in.
+func Google(clientID, clientSecret, redirectURL string) Config {
+	return Config{
+		Name:         "google",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "profile", "email"},
+		Roles:        []string{"user", "doctor"},
+		Claims:       DefaultClaims,
+		Issuer:       "https://accounts.google.com",
+		AuthURL:      google.Endpoint.AuthURL,
+		TokenURL:     google.Endpoint.TokenURL,
+		JWKSURL:      "https://www.googleapis.com/oauth2/v3/certs",
+		Issuers:      []string{"https://accounts.google.com", "accounts.google.com"},
+	}
+}
+
+type Client struct {
+	cfg Config
+
+	mu         sync.Mutex
+	discovered bool
+	jwks       *keyfunc.JWKS
+}
+
+func New(cfg Config) *Client {
+	if cfg.Claims == (ClaimMapping{}) {
+		cfg.Claims = DefaultClaims
+	}
+	if len(cfg.Issuers) == 0 && cfg.Issuer != "" {
+		cfg.Issuers = []string{cfg.Issuer}
+	}
+	return &Client{cfg: cfg}
+}
+
+func (c *Client) Name() string {
+	return c.cfg.Name
+}
+
+func (c *Client) AllowsRole(role string) bool {
+	for _, allowed := range c.cfg.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauth, err := c.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	), nil
+}
+
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	oauth, err := c.oauthConfig(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	tok, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	rawIDToken, _ := tok.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return Identity{}, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+	return c.Verify(ctx, rawIDToken, nonce)
+}
+
+// Verify checks the ID token signature, issuer, audience, expiry and nonce,
+// then maps its claims to an Identity.
+func (c *Client) Verify(ctx context.Context, rawIDToken, nonce string) (Identity, error) {
+	keys, err := c.keys(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	claims := jwt.MapClaims{}
+	t, err := jwt.ParseWithClaims(rawIDToken, claims, keys.Keyfunc, jwt.WithValidMethods([]string{"RS256", "ES256"}))
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if !t.Valid {
+		return Identity{}, ErrInvalidIDToken
+	}
+	if _, ok := claims["exp"]; !ok {
+		return Identity{}, fmt.Errorf("%w: missing exp", ErrInvalidIDToken)
+	}
+	if iss, _ := claims["iss"].(string); !c.trustedIssuer(iss) {
+		return Identity{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, iss)
+	}
+	if !claims.VerifyAudience(c.cfg.ClientID, true) {
+		return Identity{}, fmt.Errorf("%w: unexpected audience", ErrInvalidIDToken)
+	}
+	tokenNonce, _ := claims["nonce"].(string)
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return Identity{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	identity := Identity{
+		Subject: stringClaim(claims, c.cfg.Claims.Subject),
+		Email:   strings.ToLower(stringClaim(claims, c.cfg.Claims.Email)),
+		Name:    stringClaim(claims, c.cfg.Claims.Name),
+	}
+	if identity.Subject == "" {
+		return Identity{}, fmt.Errorf("%w: missing %s claim", ErrInvalidIDToken, c.cfg.Claims.Subject)
+	}
+
+	// email_verified is a bool, or the string "true" with some providers.
+	verified := claims[c.cfg.Claims.EmailVerified]
+	identity.EmailVerified = c.cfg.TrustEmail || verified == true || verified == "true"
+	return identity, nil
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+func (c *Client) trustedIssuer(iss string) bool {
+	for _, trusted := range c.cfg.Issuers {
+		if iss == trusted {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	if err := c.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &oauth2.Config{
+		ClientID:     c.cfg.ClientID,
+		ClientSecret: c.cfg.ClientSecret,
+		RedirectURL:  c.cfg.RedirectURL,
+		Scopes:       c.cfg.Scopes,
+		Endpoint:     oauth2.Endpoint{AuthURL: c.cfg.AuthURL, TokenURL: c.cfg.TokenURL},
+	}, nil
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// discover fills in missing endpoints from the issuer's discovery document.
+// It runs on first use so an unreachable provider does not stop startup;
+// a failed attempt is retried on the next login.
+func (c *Client) discover(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.discovered || (c.cfg.AuthURL != "" && c.cfg.TokenURL != "" && c.cfg.JWKSURL != "") {
+		c.discovered = true
+		return nil
+	}
+	if c.cfg.Issuer == "" {
+		return fmt.Errorf("%s: no issuer configured for discovery", c.cfg.Name)
+	}
+
+	url := strings.TrimSuffix(c.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: failed to fetch discovery document: %w", c.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: discovery document returned %s", c.cfg.Name, resp.Status)
+	}
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("%s: failed to decode discovery document: %w", c.cfg.Name, err)
+	}
+	if doc.Issuer != c.cfg.Issuer && doc.Issuer != strings.TrimSuffix(c.cfg.Issuer, "/") {
+		return fmt.Errorf("%s: discovery document is for issuer %q", c.cfg.Name, doc.Issuer)
+	}
+
+	if c.cfg.AuthURL == "" {
+		c.cfg.AuthURL = doc.AuthorizationEndpoint
+	}
+	if c.cfg.TokenURL == "" {
+		c.cfg.TokenURL = doc.TokenEndpoint
+	}
+	if c.cfg.JWKSURL == "" {
+		c.cfg.JWKSURL = doc.JWKSURI
+	}
+	if len(c.cfg.Issuers) == 0 || !c.trustedIssuer(doc.Issuer) {
+		c.cfg.Issuers = append(c.cfg.Issuers, doc.Issuer)
+	}
+	c.discovered = true
+	return nil
+}
+
+// keys fetches the provider's JWKS on first use. keyfunc keeps it cached,
+// refreshes it hourly and refetches when a token names an unknown kid, so
+// key rotation at the provider is picked up without a restart.
+func (c *Client) keys(ctx context.Context) (*keyfunc.JWKS, error) {
+	if err := c.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.jwks != nil {
+		return c.jwks, nil
+	}
+
+	jwks, err := keyfunc.Get(c.cfg.JWKSURL, keyfunc.Options{
+		RefreshInterval:   time.Hour,
+		RefreshRateLimit:  5 * time.Minute,
+		RefreshTimeout:    10 * time.Second,
+		RefreshUnknownKID: true,
+		RefreshErrorHandler: func(err error) {
+			log.Printf("oidc: failed to refresh %s keys: %v", c.cfg.Name, err)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s keys: %w", c.cfg.Name, err)
+	}
+	c.jwks = jwks
+	return jwks, nil
+}
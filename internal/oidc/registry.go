@@ -0,0 +1,132 @@
+package oidc
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Registry holds the configured providers by name.
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[string]Provider, len(providers))}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns the configured provider names in alphabetical order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var providerName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// FromEnv builds the registry from the environment.
+//
+// Google is enabled when GOOGLE_CLIENT_ID is set (with GOOGLE_CLIENT_SECRET
+// and GOOGLE_REDIRECT_URL). Further providers are listed in OIDC_PROVIDERS
+// as comma separated names, each configured with OIDC_<NAME>_* variables
+// (name upper-cased, dashes become underscores):
+//
+//	ISSUER               issuer URL, used for discovery (required)
+//	CLIENT_ID            (required)
+//	CLIENT_SECRET
+//	REDIRECT_URL         usually <base>/auth/oidc/<name>/callback (required)
+//	SCOPES               space or comma separated, default "openid profile email"
+//	ROLES                account roles that may use it, default "user,doctor"
+//	CLAIM_SUBJECT        claim names, default sub, email, email_verified, name
+//	CLAIM_EMAIL
+//	CLAIM_EMAIL_VERIFIED
+//	CLAIM_NAME
+//	TRUST_EMAIL          "true" when the provider vouches for every email
+func FromEnv() (*Registry, error) {
+	var providers []Provider
+
+	if clientID := os.Getenv("GOOGLE_CLIENT_ID"); clientID != "" {
+		providers = append(providers, New(Google(clientID, os.Getenv("GOOGLE_CLIENT_SECRET"), os.Getenv("GOOGLE_REDIRECT_URL"))))
+	}
+
+	for _, name := range splitList(os.Getenv("OIDC_PROVIDERS")) {
+		if !providerName.MatchString(name) {
+			return nil, fmt.Errorf("OIDC provider name %q must be lower case letters, digits and dashes", name)
+		}
+		cfg, err := configFromEnv(name)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, New(cfg))
+	}
+
+	seen := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		if seen[p.Name()] {
+			return nil, fmt.Errorf("OIDC provider %q is configured twice", p.Name())
+		}
+		seen[p.Name()] = true
+	}
+	return NewRegistry(providers...), nil
+}
+
+func configFromEnv(name string) (Config, error) {
+	prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+	env := func(key, fallback string) string {
+		if v := os.Getenv(prefix + key); v != "" {
+			return v
+		}
+		return fallback
+	}
+
+	cfg := Config{
+		Name:         name,
+		Issuer:       env("ISSUER", ""),
+		ClientID:     env("CLIENT_ID", ""),
+		ClientSecret: env("CLIENT_SECRET", ""),
+		RedirectURL:  env("REDIRECT_URL", ""),
+		Scopes:       splitList(env("SCOPES", "openid profile email")),
+		Roles:        splitList(env("ROLES", "user,doctor")),
+		Claims: ClaimMapping{
+			Subject:       env("CLAIM_SUBJECT", DefaultClaims.Subject),
+			Email:         env("CLAIM_EMAIL", DefaultClaims.Email),
+			EmailVerified: env("CLAIM_EMAIL_VERIFIED", DefaultClaims.EmailVerified),
+			Name:          env("CLAIM_NAME", DefaultClaims.Name),
+		},
+		TrustEmail: env("TRUST_EMAIL", "") == "true",
+	}
+
+	for key, value := range map[string]string{"ISSUER": cfg.Issuer, "CLIENT_ID": cfg.ClientID, "REDIRECT_URL": cfg.RedirectURL} {
+		if value == "" {
+			return Config{}, fmt.Errorf("%s%s is required", prefix, key)
+		}
+	}
+	for _, role := range cfg.Roles {
+		if role != "user" && role != "doctor" {
+			return Config{}, fmt.Errorf("%sROLES: unknown role %q", prefix, role)
+		}
+	}
+	return cfg, nil
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' }) {
+		items = append(items, strings.TrimSpace(item))
+	}
+	return items
+}
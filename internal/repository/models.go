@@ -8,32 +8,102 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Admin struct {
+	ID           pgtype.UUID
+	Email        string
+	PasswordHash string
+	Name         *string
+	CreatedAt    pgtype.Timestamptz
+}
+
+type AvailabilityException struct {
+	ID            pgtype.UUID
+	DoctorID      pgtype.UUID
+	ExceptionDate pgtype.Date
+	Reason        *string
+	CreatedAt     pgtype.Timestamptz
+}
+
+type AvailabilityRule struct {
+	ID                pgtype.UUID
+	DoctorID          pgtype.UUID
+	Weekday           int16
+	StartTime         pgtype.Time
+	EndTime           pgtype.Time
+	SlotMinutes       int32
+	BufferMinutes     int32
+	ValidFrom         pgtype.Date
+	ValidUntil        pgtype.Date
+	MaterializedUntil pgtype.Date
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
 type Booking struct {
-	ID               pgtype.UUID
-	UserID           pgtype.UUID
-	DoctorID         pgtype.UUID
-	AvailabilityID   pgtype.UUID
-	BookingDate      pgtype.Date
-	BookingStartTime pgtype.Time
-	BookingEndTime   pgtype.Time
-	Status           string
-	CreatedAt        pgtype.Timestamp
-	UpdatedAt        pgtype.Timestamp
+	ID                  pgtype.UUID
+	UserID              pgtype.UUID
+	DoctorID            pgtype.UUID
+	AvailabilityID      pgtype.UUID
+	BookingDate         pgtype.Date
+	BookingStartTime    pgtype.Time
+	BookingEndTime      pgtype.Time
+	Status              string
+	CreatedAt           pgtype.Timestamp
+	UpdatedAt           pgtype.Timestamp
+	RescheduledFrom     pgtype.UUID
+	CancelledAt         pgtype.Timestamptz
+	CancellationReason  *string
+	LateCancellationFee bool
+	QueuePosition       *int32
+	StartsAt            pgtype.Timestamptz
+	EndsAt              pgtype.Timestamptz
+}
+
+type BookingStatusHistory struct {
+	ID            pgtype.UUID
+	BookingID     pgtype.UUID
+	FromStatus    *string
+	ToStatus      string
+	ChangedBy     pgtype.UUID
+	ChangedByRole string
+	Reason        *string
+	CreatedAt     pgtype.Timestamptz
 }
 
 type Doctor struct {
-	ID              pgtype.UUID
-	Name            string
-	PasswordHash    *string
-	Specialization  string
-	Experience      int32
-	Qualification   string
-	HospitalName    string
-	ConsultationFee pgtype.Numeric
-	ContactNumber   *string
-	Email           *string
-	CreatedAt       pgtype.Timestamp
-	UpdatedAt       pgtype.Timestamp
+	ID                        pgtype.UUID
+	Name                      string
+	PasswordHash              *string
+	Specialization            string
+	Experience                int32
+	Qualification             string
+	HospitalName              string
+	ConsultationFee           pgtype.Numeric
+	ContactNumber             *string
+	Email                     *string
+	CreatedAt                 pgtype.Timestamp
+	UpdatedAt                 pgtype.Timestamp
+	EmailVerifiedAt           pgtype.Timestamptz
+	VerificationStatus        string
+	VerificationNotes         *string
+	ReviewedAt                pgtype.Timestamptz
+	ReviewedBy                pgtype.UUID
+	SuspendedAt               pgtype.Timestamptz
+	MfaSecret                 []byte
+	MfaEnabledAt              pgtype.Timestamptz
+	MfaRequired               bool
+	MfaLastUsedStep           *int64
+	CancellationNoticeMinutes int32
+	LateCancellationFee       bool
+	Timezone                  string
+	Bio                       *string
+	Languages                 []string
+	ClinicAddress             *string
+	ClinicLatitude            *float64
+	ClinicLongitude           *float64
+	DeactivatedAt             pgtype.Timestamptz
+	RatingAverage             pgtype.Numeric
+	RatingCount               int32
 }
 
 type DoctorAvailability struct {
@@ -45,6 +115,65 @@ type DoctorAvailability struct {
 	IsBooked         *bool
 	CreatedAt        pgtype.Timestamp
 	UpdatedAt        pgtype.Timestamp
+	RuleID           pgtype.UUID
+	SlotMinutes      *int32
+	Capacity         int32
+	BookingMode      string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+}
+
+type DoctorLicenseDocument struct {
+	ID        pgtype.UUID
+	DoctorID  pgtype.UUID
+	FileName  string
+	FileData  []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+type DoctorMfaRecoveryCode struct {
+	ID        pgtype.UUID
+	DoctorID  pgtype.UUID
+	CodeHash  string
+	UsedAt    pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type DoctorProfilePhoto struct {
+	DoctorID    pgtype.UUID
+	ContentType string
+	Data        []byte
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type DoctorReview struct {
+	ID              pgtype.UUID
+	BookingID       pgtype.UUID
+	DoctorID        pgtype.UUID
+	UserID          pgtype.UUID
+	Rating          int16
+	Review          *string
+	DoctorReply     *string
+	RepliedAt       pgtype.Timestamptz
+	Status          string
+	FlagReason      *string
+	FlaggedBy       pgtype.UUID
+	FlaggedAt       pgtype.Timestamptz
+	ModerationNotes *string
+	ModeratedBy     pgtype.UUID
+	ModeratedAt     pgtype.Timestamptz
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
+type EmailVerificationToken struct {
+	ID        pgtype.UUID
+	AccountID pgtype.UUID
+	Role      string
+	TokenHash string
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
 }
 
 type EncryptedFile struct {
@@ -55,6 +184,23 @@ type EncryptedFile struct {
 	CreatedAt pgtype.Timestamp
 }
 
+type LoginAttempt struct {
+	AttemptKey    string
+	Failures      int32
+	LastFailureAt pgtype.Timestamptz
+	BlockedUntil  pgtype.Timestamptz
+}
+
+type LoginAudit struct {
+	ID        pgtype.UUID
+	Email     string
+	Role      string
+	IpAddress *string
+	UserAgent *string
+	Reason    string
+	CreatedAt pgtype.Timestamptz
+}
+
 type Medication struct {
 	ID             pgtype.UUID
 	UserID         pgtype.UUID
@@ -65,14 +211,94 @@ type Medication struct {
 	IsReadbyuser   *bool
 	CreatedAt      pgtype.Timestamptz
 	UpdatedAt      pgtype.Timestamptz
+	NextNotifyAt   pgtype.Timestamptz
+}
+
+type MfaChallenge struct {
+	ID        pgtype.UUID
+	DoctorID  pgtype.UUID
+	TokenHash string
+	Attempts  int32
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type OauthState struct {
+	ID           pgtype.UUID
+	Provider     string
+	StateHash    string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+	Role         string
+}
+
+type PasswordResetToken struct {
+	ID        pgtype.UUID
+	AccountID pgtype.UUID
+	Role      string
+	TokenHash string
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type RefreshToken struct {
+	ID        pgtype.UUID
+	FamilyID  pgtype.UUID
+	SubjectID pgtype.UUID
+	Role      string
+	Email     string
+	TokenHash string
+	ExpiresAt pgtype.Timestamptz
+	RevokedAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type RevokedAccessToken struct {
+	Jti       string
+	ExpiresAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type Session struct {
+	ID         pgtype.UUID
+	SubjectID  pgtype.UUID
+	Role       string
+	DeviceName *string
+	Platform   *string
+	FcmToken   *string
+	IpAddress  *string
+	UserAgent  *string
+	CreatedAt  pgtype.Timestamptz
+	LastSeenAt pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+}
+
+type SubjectTokenRevocation struct {
+	SubjectID     pgtype.UUID
+	RevokedBefore pgtype.Timestamptz
+}
+
+type UpcomingFreeAppointment struct {
+	AvailabilityID   pgtype.UUID
+	DoctorID         pgtype.UUID
+	AvailabilityDate pgtype.Date
+	StartTime        pgtype.Time
+	EndTime          pgtype.Time
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	BookingMode      string
+	Capacity         int32
+	Remaining        int32
 }
 
 type User struct {
 	ID                           pgtype.UUID
 	Email                        string
 	PasswordHash                 *string
-	FcmToken                     *string
-	GoogleID                     *string
 	Name                         *string
 	Age                          *int32
 	Gender                       *string
@@ -81,4 +307,18 @@ type User struct {
 	EmergencyContactRelationship *string
 	CreatedAt                    pgtype.Timestamp
 	UpdatedAt                    pgtype.Timestamp
+	EmailVerifiedAt              pgtype.Timestamptz
+	SuspendedAt                  pgtype.Timestamptz
+	Timezone                     string
+}
+
+type UserIdentity struct {
+	ID          pgtype.UUID
+	Provider    string
+	Subject     string
+	AccountID   pgtype.UUID
+	Role        string
+	Email       *string
+	LastLoginAt pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
 }
@@ -11,10 +11,77 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countSearchDoctors = `-- name: CountSearchDoctors :one
+SELECT COUNT(*)
+FROM doctors d
+WHERE d.verification_status = 'verified'
+  AND d.suspended_at IS NULL
+  AND d.deactivated_at IS NULL
+  AND ($1::text IS NULL
+       OR doctor_search_document(d.name, d.specialization, d.hospital_name) @@ websearch_to_tsquery('simple', $1::text)
+       OR doctor_search_document(d.name, d.specialization, d.hospital_name) @@ websearch_to_tsquery('english', $1::text))
+  AND ($2::text IS NULL OR d.name ILIKE '%' || $2::text || '%' ESCAPE '\')
+  AND ($3::text IS NULL OR lower(d.specialization) = lower($3::text))
+  AND ($4::text IS NULL OR lower(d.hospital_name) = lower($4::text))
+  AND ($5::int IS NULL OR d.experience >= $5::int)
+  AND ($6::float8 IS NULL OR d.consultation_fee >= $6::float8::numeric)
+  AND ($7::float8 IS NULL OR d.consultation_fee <= $7::float8::numeric)
+  AND ($8::date IS NULL OR EXISTS (
+      SELECT 1
+      FROM doctor_availability a
+      WHERE a.doctor_id = d.id
+        AND a.availability_date = $8::date
+        AND a.is_booked IS NOT TRUE
+        AND a.ends_at > NOW()
+  ))
+`
+
+type CountSearchDoctorsParams struct {
+	Query          *string
+	Name           *string
+	Specialization *string
+	HospitalName   *string
+	MinExperience  *int32
+	MinFee         *float64
+	MaxFee         *float64
+	AvailableOn    pgtype.Date
+}
+
+func (q *Queries) CountSearchDoctors(ctx context.Context, arg CountSearchDoctorsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchDoctors,
+		arg.Query,
+		arg.Name,
+		arg.Specialization,
+		arg.HospitalName,
+		arg.MinExperience,
+		arg.MinFee,
+		arg.MaxFee,
+		arg.AvailableOn,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUpcomingActiveBookingsByDoctor = `-- name: CountUpcomingActiveBookingsByDoctor :one
+SELECT COUNT(*)
+FROM bookings
+WHERE doctor_id = $1
+  AND ends_at > NOW()
+  AND status IN ('pending', 'confirmed', 'completed', 'no_show')
+`
+
+func (q *Queries) CountUpcomingActiveBookingsByDoctor(ctx context.Context, doctorID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countUpcomingActiveBookingsByDoctor, doctorID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createBooking = `-- name: CreateBooking :one
-INSERT INTO bookings (user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at
+INSERT INTO bookings (user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, rescheduled_from, queue_position, starts_at, ends_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at, rescheduled_from, cancelled_at, cancellation_reason, late_cancellation_fee, queue_position, starts_at, ends_at
 `
 
 type CreateBookingParams struct {
@@ -25,6 +92,10 @@ type CreateBookingParams struct {
 	BookingStartTime pgtype.Time
 	BookingEndTime   pgtype.Time
 	Status           string
+	RescheduledFrom  pgtype.UUID
+	QueuePosition    *int32
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
 }
 
 func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (Booking, error) {
@@ -36,6 +107,10 @@ func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (B
 		arg.BookingStartTime,
 		arg.BookingEndTime,
 		arg.Status,
+		arg.RescheduledFrom,
+		arg.QueuePosition,
+		arg.StartsAt,
+		arg.EndsAt,
 	)
 	var i Booking
 	err := row.Scan(
@@ -49,10 +124,43 @@ func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (B
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RescheduledFrom,
+		&i.CancelledAt,
+		&i.CancellationReason,
+		&i.LateCancellationFee,
+		&i.QueuePosition,
+		&i.StartsAt,
+		&i.EndsAt,
 	)
 	return i, err
 }
 
+const createBookingStatusHistory = `-- name: CreateBookingStatusHistory :exec
+INSERT INTO booking_status_history (booking_id, from_status, to_status, changed_by, changed_by_role, reason)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateBookingStatusHistoryParams struct {
+	BookingID     pgtype.UUID
+	FromStatus    *string
+	ToStatus      string
+	ChangedBy     pgtype.UUID
+	ChangedByRole string
+	Reason        *string
+}
+
+func (q *Queries) CreateBookingStatusHistory(ctx context.Context, arg CreateBookingStatusHistoryParams) error {
+	_, err := q.db.Exec(ctx, createBookingStatusHistory,
+		arg.BookingID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.ChangedBy,
+		arg.ChangedByRole,
+		arg.Reason,
+	)
+	return err
+}
+
 const createDoctor = `-- name: CreateDoctor :one
 INSERT INTO doctors (
     name,
@@ -126,9 +234,9 @@ func (q *Queries) CreateDoctor(ctx context.Context, arg CreateDoctorParams) (Cre
 }
 
 const createDoctorAvailability = `-- name: CreateDoctorAvailability :one
-INSERT INTO doctor_availability (doctor_id, availability_date, start_time, end_time)
-VALUES ($1, $2, $3, $4)
-RETURNING id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at
+INSERT INTO doctor_availability (doctor_id, availability_date, start_time, end_time, slot_minutes, capacity, booking_mode, starts_at, ends_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at, rule_id, slot_minutes, capacity, booking_mode, starts_at, ends_at
 `
 
 type CreateDoctorAvailabilityParams struct {
@@ -136,6 +244,11 @@ type CreateDoctorAvailabilityParams struct {
 	AvailabilityDate pgtype.Date
 	StartTime        pgtype.Time
 	EndTime          pgtype.Time
+	SlotMinutes      *int32
+	Capacity         int32
+	BookingMode      string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
 }
 
 func (q *Queries) CreateDoctorAvailability(ctx context.Context, arg CreateDoctorAvailabilityParams) (DoctorAvailability, error) {
@@ -144,6 +257,11 @@ func (q *Queries) CreateDoctorAvailability(ctx context.Context, arg CreateDoctor
 		arg.AvailabilityDate,
 		arg.StartTime,
 		arg.EndTime,
+		arg.SlotMinutes,
+		arg.Capacity,
+		arg.BookingMode,
+		arg.StartsAt,
+		arg.EndsAt,
 	)
 	var i DoctorAvailability
 	err := row.Scan(
@@ -155,14 +273,45 @@ func (q *Queries) CreateDoctorAvailability(ctx context.Context, arg CreateDoctor
 		&i.IsBooked,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RuleID,
+		&i.SlotMinutes,
+		&i.Capacity,
+		&i.BookingMode,
+		&i.StartsAt,
+		&i.EndsAt,
 	)
 	return i, err
 }
 
+const createDoctorLicenseDocument = `-- name: CreateDoctorLicenseDocument :one
+INSERT INTO doctor_license_documents (doctor_id, file_name, file_data)
+VALUES ($1, $2, $3)
+RETURNING id, file_name, created_at
+`
+
+type CreateDoctorLicenseDocumentParams struct {
+	DoctorID pgtype.UUID
+	FileName string
+	FileData []byte
+}
+
+type CreateDoctorLicenseDocumentRow struct {
+	ID        pgtype.UUID
+	FileName  string
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateDoctorLicenseDocument(ctx context.Context, arg CreateDoctorLicenseDocumentParams) (CreateDoctorLicenseDocumentRow, error) {
+	row := q.db.QueryRow(ctx, createDoctorLicenseDocument, arg.DoctorID, arg.FileName, arg.FileData)
+	var i CreateDoctorLicenseDocumentRow
+	err := row.Scan(&i.ID, &i.FileName, &i.CreatedAt)
+	return i, err
+}
+
 const createMedication = `-- name: CreateMedication :one
-INSERT INTO medications (user_id, medication_name, dosage, time_to_notify, frequency)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, user_id, medication_name, dosage, time_to_notify, frequency, is_readbyuser, created_at, updated_at
+INSERT INTO medications (user_id, medication_name, dosage, time_to_notify, frequency, next_notify_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, medication_name, dosage, time_to_notify, frequency, is_readbyuser, created_at, updated_at, next_notify_at
 `
 
 type CreateMedicationParams struct {
@@ -171,6 +320,7 @@ type CreateMedicationParams struct {
 	Dosage         string
 	TimeToNotify   pgtype.Time
 	Frequency      string
+	NextNotifyAt   pgtype.Timestamptz
 }
 
 func (q *Queries) CreateMedication(ctx context.Context, arg CreateMedicationParams) (Medication, error) {
@@ -180,6 +330,7 @@ func (q *Queries) CreateMedication(ctx context.Context, arg CreateMedicationPara
 		arg.Dosage,
 		arg.TimeToNotify,
 		arg.Frequency,
+		arg.NextNotifyAt,
 	)
 	var i Medication
 	err := row.Scan(
@@ -192,6 +343,7 @@ func (q *Queries) CreateMedication(ctx context.Context, arg CreateMedicationPara
 		&i.IsReadbyuser,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NextNotifyAt,
 	)
 	return i, err
 }
@@ -229,47 +381,30 @@ func (q *Queries) CreateUserWithEmail(ctx context.Context, arg CreateUserWithEma
 	return i, err
 }
 
-const createUserWithGoogle = `-- name: CreateUserWithGoogle :one
-INSERT INTO users (google_id, email, name)
-VALUES ($1, $2, $3)
-RETURNING id, email, google_id, name
+const createUserWithIdentity = `-- name: CreateUserWithIdentity :one
+INSERT INTO users (email, name, email_verified_at)
+VALUES ($1, $2, NOW())
+RETURNING id, email, name
 `
 
-type CreateUserWithGoogleParams struct {
-	GoogleID *string
-	Email    string
-	Name     *string
+type CreateUserWithIdentityParams struct {
+	Email string
+	Name  *string
 }
 
-type CreateUserWithGoogleRow struct {
-	ID       pgtype.UUID
-	Email    string
-	GoogleID *string
-	Name     *string
+type CreateUserWithIdentityRow struct {
+	ID    pgtype.UUID
+	Email string
+	Name  *string
 }
 
-func (q *Queries) CreateUserWithGoogle(ctx context.Context, arg CreateUserWithGoogleParams) (CreateUserWithGoogleRow, error) {
-	row := q.db.QueryRow(ctx, createUserWithGoogle, arg.GoogleID, arg.Email, arg.Name)
-	var i CreateUserWithGoogleRow
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.GoogleID,
-		&i.Name,
-	)
+func (q *Queries) CreateUserWithIdentity(ctx context.Context, arg CreateUserWithIdentityParams) (CreateUserWithIdentityRow, error) {
+	row := q.db.QueryRow(ctx, createUserWithIdentity, arg.Email, arg.Name)
+	var i CreateUserWithIdentityRow
+	err := row.Scan(&i.ID, &i.Email, &i.Name)
 	return i, err
 }
 
-const deleteBooking = `-- name: DeleteBooking :exec
-DELETE FROM bookings
-WHERE id = $1
-`
-
-func (q *Queries) DeleteBooking(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteBooking, id)
-	return err
-}
-
 const deleteDoctorAvailability = `-- name: DeleteDoctorAvailability :exec
 DELETE FROM doctor_availability
 WHERE id = $1 AND doctor_id = $2
@@ -285,8 +420,75 @@ func (q *Queries) DeleteDoctorAvailability(ctx context.Context, arg DeleteDoctor
 	return err
 }
 
+const deleteDoctorProfilePhoto = `-- name: DeleteDoctorProfilePhoto :execrows
+DELETE FROM doctor_profile_photos
+WHERE doctor_id = $1
+`
+
+func (q *Queries) DeleteDoctorProfilePhoto(ctx context.Context, doctorID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDoctorProfilePhoto, doctorID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteFreeUpcomingAvailabilityByDoctor = `-- name: DeleteFreeUpcomingAvailabilityByDoctor :execrows
+DELETE FROM doctor_availability
+WHERE doctor_id = $1
+  AND ends_at > NOW()
+  AND NOT EXISTS (
+      SELECT 1
+      FROM bookings
+      WHERE bookings.availability_id = doctor_availability.id
+  )
+`
+
+func (q *Queries) DeleteFreeUpcomingAvailabilityByDoctor(ctx context.Context, doctorID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFreeUpcomingAvailabilityByDoctor, doctorID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const doctorHasBookingWithUser = `-- name: DoctorHasBookingWithUser :one
+SELECT EXISTS (
+    SELECT 1
+    FROM bookings
+    WHERE doctor_id = $1 AND user_id = $2 AND status IN ('pending', 'confirmed', 'completed', 'no_show')
+)
+`
+
+type DoctorHasBookingWithUserParams struct {
+	DoctorID pgtype.UUID
+	UserID   pgtype.UUID
+}
+
+func (q *Queries) DoctorHasBookingWithUser(ctx context.Context, arg DoctorHasBookingWithUserParams) (bool, error) {
+	row := q.db.QueryRow(ctx, doctorHasBookingWithUser, arg.DoctorID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const doctorHasProfilePhoto = `-- name: DoctorHasProfilePhoto :one
+SELECT EXISTS (
+    SELECT 1
+    FROM doctor_profile_photos
+    WHERE doctor_id = $1
+)
+`
+
+func (q *Queries) DoctorHasProfilePhoto(ctx context.Context, doctorID pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, doctorHasProfilePhoto, doctorID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const getBookingByID = `-- name: GetBookingByID :one
-SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at
+SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at, rescheduled_from, cancelled_at, cancellation_reason, late_cancellation_fee, queue_position, starts_at, ends_at
 FROM bookings
 WHERE id = $1
 `
@@ -305,12 +507,51 @@ func (q *Queries) GetBookingByID(ctx context.Context, id pgtype.UUID) (Booking,
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RescheduledFrom,
+		&i.CancelledAt,
+		&i.CancellationReason,
+		&i.LateCancellationFee,
+		&i.QueuePosition,
+		&i.StartsAt,
+		&i.EndsAt,
+	)
+	return i, err
+}
+
+const getBookingByIDForUpdate = `-- name: GetBookingByIDForUpdate :one
+SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at, rescheduled_from, cancelled_at, cancellation_reason, late_cancellation_fee, queue_position, starts_at, ends_at
+FROM bookings
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetBookingByIDForUpdate(ctx context.Context, id pgtype.UUID) (Booking, error) {
+	row := q.db.QueryRow(ctx, getBookingByIDForUpdate, id)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.DoctorID,
+		&i.AvailabilityID,
+		&i.BookingDate,
+		&i.BookingStartTime,
+		&i.BookingEndTime,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RescheduledFrom,
+		&i.CancelledAt,
+		&i.CancellationReason,
+		&i.LateCancellationFee,
+		&i.QueuePosition,
+		&i.StartsAt,
+		&i.EndsAt,
 	)
 	return i, err
 }
 
 const getBookingsByAvailabilityID = `-- name: GetBookingsByAvailabilityID :many
-SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at
+SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at, rescheduled_from, cancelled_at, cancellation_reason, late_cancellation_fee, queue_position, starts_at, ends_at
 FROM bookings
 WHERE availability_id = $1
 `
@@ -335,6 +576,13 @@ func (q *Queries) GetBookingsByAvailabilityID(ctx context.Context, availabilityI
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RescheduledFrom,
+			&i.CancelledAt,
+			&i.CancellationReason,
+			&i.LateCancellationFee,
+			&i.QueuePosition,
+			&i.StartsAt,
+			&i.EndsAt,
 		); err != nil {
 			return nil, err
 		}
@@ -347,7 +595,7 @@ func (q *Queries) GetBookingsByAvailabilityID(ctx context.Context, availabilityI
 }
 
 const getBookingsByDoctorID = `-- name: GetBookingsByDoctorID :many
-SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at
+SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at, rescheduled_from, cancelled_at, cancellation_reason, late_cancellation_fee, queue_position, starts_at, ends_at
 FROM bookings
 WHERE doctor_id = $1
 `
@@ -372,6 +620,13 @@ func (q *Queries) GetBookingsByDoctorID(ctx context.Context, doctorID pgtype.UUI
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RescheduledFrom,
+			&i.CancelledAt,
+			&i.CancellationReason,
+			&i.LateCancellationFee,
+			&i.QueuePosition,
+			&i.StartsAt,
+			&i.EndsAt,
 		); err != nil {
 			return nil, err
 		}
@@ -384,7 +639,7 @@ func (q *Queries) GetBookingsByDoctorID(ctx context.Context, doctorID pgtype.UUI
 }
 
 const getBookingsByUserID = `-- name: GetBookingsByUserID :many
-SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at
+SELECT id, user_id, doctor_id, availability_id, booking_date, booking_start_time, booking_end_time, status, created_at, updated_at, rescheduled_from, cancelled_at, cancellation_reason, late_cancellation_fee, queue_position, starts_at, ends_at
 FROM bookings
 WHERE user_id = $1
 `
@@ -409,6 +664,13 @@ func (q *Queries) GetBookingsByUserID(ctx context.Context, userID pgtype.UUID) (
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RescheduledFrom,
+			&i.CancelledAt,
+			&i.CancellationReason,
+			&i.LateCancellationFee,
+			&i.QueuePosition,
+			&i.StartsAt,
+			&i.EndsAt,
 		); err != nil {
 			return nil, err
 		}
@@ -421,7 +683,7 @@ func (q *Queries) GetBookingsByUserID(ctx context.Context, userID pgtype.UUID) (
 }
 
 const getDoctorAvailabilityByDoctor = `-- name: GetDoctorAvailabilityByDoctor :many
-SELECT id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at
+SELECT id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at, rule_id, slot_minutes, capacity, booking_mode, starts_at, ends_at
 FROM doctor_availability
 WHERE doctor_id = $1
 `
@@ -444,6 +706,12 @@ func (q *Queries) GetDoctorAvailabilityByDoctor(ctx context.Context, doctorID pg
 			&i.IsBooked,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RuleID,
+			&i.SlotMinutes,
+			&i.Capacity,
+			&i.BookingMode,
+			&i.StartsAt,
+			&i.EndsAt,
 		); err != nil {
 			return nil, err
 		}
@@ -456,7 +724,7 @@ func (q *Queries) GetDoctorAvailabilityByDoctor(ctx context.Context, doctorID pg
 }
 
 const getDoctorAvailabilityByDoctorAndDate = `-- name: GetDoctorAvailabilityByDoctorAndDate :many
-SELECT id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at
+SELECT id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at, rule_id, slot_minutes, capacity, booking_mode, starts_at, ends_at
 FROM doctor_availability
 WHERE doctor_id = $1 AND availability_date = $2
 `
@@ -484,6 +752,12 @@ func (q *Queries) GetDoctorAvailabilityByDoctorAndDate(ctx context.Context, arg
 			&i.IsBooked,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RuleID,
+			&i.SlotMinutes,
+			&i.Capacity,
+			&i.BookingMode,
+			&i.StartsAt,
+			&i.EndsAt,
 		); err != nil {
 			return nil, err
 		}
@@ -496,7 +770,7 @@ func (q *Queries) GetDoctorAvailabilityByDoctorAndDate(ctx context.Context, arg
 }
 
 const getDoctorAvailabilityByID = `-- name: GetDoctorAvailabilityByID :one
-SELECT id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at
+SELECT id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at, rule_id, slot_minutes, capacity, booking_mode, starts_at, ends_at
 FROM doctor_availability
 WHERE id = $1
 `
@@ -513,29 +787,58 @@ func (q *Queries) GetDoctorAvailabilityByID(ctx context.Context, id pgtype.UUID)
 		&i.IsBooked,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RuleID,
+		&i.SlotMinutes,
+		&i.Capacity,
+		&i.BookingMode,
+		&i.StartsAt,
+		&i.EndsAt,
 	)
 	return i, err
 }
 
+const getDoctorBookingState = `-- name: GetDoctorBookingState :one
+SELECT verification_status, suspended_at, deactivated_at
+FROM doctors
+WHERE id = $1
+`
+
+type GetDoctorBookingStateRow struct {
+	VerificationStatus string
+	SuspendedAt        pgtype.Timestamptz
+	DeactivatedAt      pgtype.Timestamptz
+}
+
+func (q *Queries) GetDoctorBookingState(ctx context.Context, id pgtype.UUID) (GetDoctorBookingStateRow, error) {
+	row := q.db.QueryRow(ctx, getDoctorBookingState, id)
+	var i GetDoctorBookingStateRow
+	err := row.Scan(&i.VerificationStatus, &i.SuspendedAt, &i.DeactivatedAt)
+	return i, err
+}
+
 const getDoctorByEmail = `-- name: GetDoctorByEmail :one
-SELECT id, name, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, password_hash, created_at, updated_at
+SELECT id, name, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, password_hash, created_at, updated_at, verification_status, suspended_at, mfa_enabled_at, mfa_required
 FROM doctors
 WHERE email = $1
 `
 
 type GetDoctorByEmailRow struct {
-	ID              pgtype.UUID
-	Name            string
-	Specialization  string
-	Experience      int32
-	Qualification   string
-	HospitalName    string
-	ConsultationFee pgtype.Numeric
-	ContactNumber   *string
-	Email           *string
-	PasswordHash    *string
-	CreatedAt       pgtype.Timestamp
-	UpdatedAt       pgtype.Timestamp
+	ID                 pgtype.UUID
+	Name               string
+	Specialization     string
+	Experience         int32
+	Qualification      string
+	HospitalName       string
+	ConsultationFee    pgtype.Numeric
+	ContactNumber      *string
+	Email              *string
+	PasswordHash       *string
+	CreatedAt          pgtype.Timestamp
+	UpdatedAt          pgtype.Timestamp
+	VerificationStatus string
+	SuspendedAt        pgtype.Timestamptz
+	MfaEnabledAt       pgtype.Timestamptz
+	MfaRequired        bool
 }
 
 func (q *Queries) GetDoctorByEmail(ctx context.Context, email *string) (GetDoctorByEmailRow, error) {
@@ -554,12 +857,16 @@ func (q *Queries) GetDoctorByEmail(ctx context.Context, email *string) (GetDocto
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.VerificationStatus,
+		&i.SuspendedAt,
+		&i.MfaEnabledAt,
+		&i.MfaRequired,
 	)
 	return i, err
 }
 
 const getDoctorByID = `-- name: GetDoctorByID :one
-SELECT id, name, password_hash, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, created_at, updated_at
+SELECT id, name, password_hash, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, created_at, updated_at, email_verified_at, verification_status, verification_notes, reviewed_at, reviewed_by, suspended_at, mfa_secret, mfa_enabled_at, mfa_required, mfa_last_used_step, cancellation_notice_minutes, late_cancellation_fee, timezone, bio, languages, clinic_address, clinic_latitude, clinic_longitude, deactivated_at, rating_average, rating_count
 FROM doctors
 WHERE id = $1
 `
@@ -580,10 +887,130 @@ func (q *Queries) GetDoctorByID(ctx context.Context, id pgtype.UUID) (Doctor, er
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.VerificationStatus,
+		&i.VerificationNotes,
+		&i.ReviewedAt,
+		&i.ReviewedBy,
+		&i.SuspendedAt,
+		&i.MfaSecret,
+		&i.MfaEnabledAt,
+		&i.MfaRequired,
+		&i.MfaLastUsedStep,
+		&i.CancellationNoticeMinutes,
+		&i.LateCancellationFee,
+		&i.Timezone,
+		&i.Bio,
+		&i.Languages,
+		&i.ClinicAddress,
+		&i.ClinicLatitude,
+		&i.ClinicLongitude,
+		&i.DeactivatedAt,
+		&i.RatingAverage,
+		&i.RatingCount,
+	)
+	return i, err
+}
+
+const getDoctorCancellationPolicy = `-- name: GetDoctorCancellationPolicy :one
+SELECT cancellation_notice_minutes, late_cancellation_fee
+FROM doctors
+WHERE id = $1
+`
+
+type GetDoctorCancellationPolicyRow struct {
+	CancellationNoticeMinutes int32
+	LateCancellationFee       bool
+}
+
+func (q *Queries) GetDoctorCancellationPolicy(ctx context.Context, id pgtype.UUID) (GetDoctorCancellationPolicyRow, error) {
+	row := q.db.QueryRow(ctx, getDoctorCancellationPolicy, id)
+	var i GetDoctorCancellationPolicyRow
+	err := row.Scan(&i.CancellationNoticeMinutes, &i.LateCancellationFee)
+	return i, err
+}
+
+const getDoctorEmailVerifiedAt = `-- name: GetDoctorEmailVerifiedAt :one
+SELECT email_verified_at
+FROM doctors
+WHERE id = $1
+`
+
+func (q *Queries) GetDoctorEmailVerifiedAt(ctx context.Context, id pgtype.UUID) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getDoctorEmailVerifiedAt, id)
+	var email_verified_at pgtype.Timestamptz
+	err := row.Scan(&email_verified_at)
+	return email_verified_at, err
+}
+
+const getDoctorLicenseDocument = `-- name: GetDoctorLicenseDocument :one
+SELECT id, doctor_id, file_name, file_data, created_at
+FROM doctor_license_documents
+WHERE id = $1 AND doctor_id = $2
+`
+
+type GetDoctorLicenseDocumentParams struct {
+	ID       pgtype.UUID
+	DoctorID pgtype.UUID
+}
+
+func (q *Queries) GetDoctorLicenseDocument(ctx context.Context, arg GetDoctorLicenseDocumentParams) (DoctorLicenseDocument, error) {
+	row := q.db.QueryRow(ctx, getDoctorLicenseDocument, arg.ID, arg.DoctorID)
+	var i DoctorLicenseDocument
+	err := row.Scan(
+		&i.ID,
+		&i.DoctorID,
+		&i.FileName,
+		&i.FileData,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDoctorProfilePhoto = `-- name: GetDoctorProfilePhoto :one
+SELECT doctor_id, content_type, data, updated_at
+FROM doctor_profile_photos
+WHERE doctor_id = $1
+`
+
+func (q *Queries) GetDoctorProfilePhoto(ctx context.Context, doctorID pgtype.UUID) (DoctorProfilePhoto, error) {
+	row := q.db.QueryRow(ctx, getDoctorProfilePhoto, doctorID)
+	var i DoctorProfilePhoto
+	err := row.Scan(
+		&i.DoctorID,
+		&i.ContentType,
+		&i.Data,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getDoctorTimezone = `-- name: GetDoctorTimezone :one
+SELECT timezone
+FROM doctors
+WHERE id = $1
+`
+
+func (q *Queries) GetDoctorTimezone(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getDoctorTimezone, id)
+	var timezone string
+	err := row.Scan(&timezone)
+	return timezone, err
+}
+
+const getDoctorVerificationStatus = `-- name: GetDoctorVerificationStatus :one
+SELECT verification_status
+FROM doctors
+WHERE id = $1
+`
+
+func (q *Queries) GetDoctorVerificationStatus(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getDoctorVerificationStatus, id)
+	var verification_status string
+	err := row.Scan(&verification_status)
+	return verification_status, err
+}
+
 const getEncryptedFile = `-- name: GetEncryptedFile :one
 SELECT file_name, file_data FROM encrypted_files WHERE user_id = $1 AND id = $2
 `
@@ -605,6 +1032,30 @@ func (q *Queries) GetEncryptedFile(ctx context.Context, arg GetEncryptedFilePara
 	return i, err
 }
 
+const getMedicationByID = `-- name: GetMedicationByID :one
+SELECT id, user_id, medication_name, dosage, time_to_notify, frequency, is_readbyuser, created_at, updated_at, next_notify_at
+FROM medications
+WHERE id = $1
+`
+
+func (q *Queries) GetMedicationByID(ctx context.Context, id pgtype.UUID) (Medication, error) {
+	row := q.db.QueryRow(ctx, getMedicationByID, id)
+	var i Medication
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.MedicationName,
+		&i.Dosage,
+		&i.TimeToNotify,
+		&i.Frequency,
+		&i.IsReadbyuser,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextNotifyAt,
+	)
+	return i, err
+}
+
 const getMedicationsByUserID = `-- name: GetMedicationsByUserID :many
 SELECT
     id,
@@ -615,7 +1066,8 @@ SELECT
     frequency,
     is_readbyuser,
     created_at,
-    updated_at
+    updated_at,
+    next_notify_at
 FROM
     medications
 WHERE
@@ -641,6 +1093,7 @@ func (q *Queries) GetMedicationsByUserID(ctx context.Context, userID pgtype.UUID
 			&i.IsReadbyuser,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.NextNotifyAt,
 		); err != nil {
 			return nil, err
 		}
@@ -653,21 +1106,35 @@ func (q *Queries) GetMedicationsByUserID(ctx context.Context, userID pgtype.UUID
 }
 
 const getMedicationsToNotify = `-- name: GetMedicationsToNotify :many
-SELECT id, user_id, medication_name, dosage, time_to_notify, frequency, is_readbyuser, created_at, updated_at
-FROM medications
-WHERE time_to_notify = $1
-  AND (frequency = 'daily' OR (frequency = 'weekly' AND EXTRACT(DOW FROM NOW()) = EXTRACT(DOW FROM updated_at)))
+SELECT m.id, m.user_id, m.medication_name, m.dosage, m.time_to_notify, m.frequency, m.is_readbyuser, m.created_at, m.updated_at, m.next_notify_at, u.timezone
+FROM medications m
+JOIN users u ON u.id = m.user_id
+WHERE m.next_notify_at <= $1
 `
 
-func (q *Queries) GetMedicationsToNotify(ctx context.Context, timeToNotify pgtype.Time) ([]Medication, error) {
-	rows, err := q.db.Query(ctx, getMedicationsToNotify, timeToNotify)
+type GetMedicationsToNotifyRow struct {
+	ID             pgtype.UUID
+	UserID         pgtype.UUID
+	MedicationName string
+	Dosage         string
+	TimeToNotify   pgtype.Time
+	Frequency      string
+	IsReadbyuser   *bool
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+	NextNotifyAt   pgtype.Timestamptz
+	Timezone       string
+}
+
+func (q *Queries) GetMedicationsToNotify(ctx context.Context, nextNotifyAt pgtype.Timestamptz) ([]GetMedicationsToNotifyRow, error) {
+	rows, err := q.db.Query(ctx, getMedicationsToNotify, nextNotifyAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Medication
+	var items []GetMedicationsToNotifyRow
 	for rows.Next() {
-		var i Medication
+		var i GetMedicationsToNotifyRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -678,6 +1145,8 @@ func (q *Queries) GetMedicationsToNotify(ctx context.Context, timeToNotify pgtyp
 			&i.IsReadbyuser,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.NextNotifyAt,
+			&i.Timezone,
 		); err != nil {
 			return nil, err
 		}
@@ -690,7 +1159,7 @@ func (q *Queries) GetMedicationsToNotify(ctx context.Context, timeToNotify pgtyp
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password_hash, google_id, name, created_at, updated_at
+SELECT id, email, password_hash, name, created_at, updated_at, suspended_at
 FROM users
 WHERE email = $1
 `
@@ -699,10 +1168,10 @@ type GetUserByEmailRow struct {
 	ID           pgtype.UUID
 	Email        string
 	PasswordHash *string
-	GoogleID     *string
 	Name         *string
 	CreatedAt    pgtype.Timestamp
 	UpdatedAt    pgtype.Timestamp
+	SuspendedAt  pgtype.Timestamptz
 }
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error) {
@@ -712,45 +1181,16 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEm
 		&i.ID,
 		&i.Email,
 		&i.PasswordHash,
-		&i.GoogleID,
-		&i.Name,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
-}
-
-const getUserByGoogleID = `-- name: GetUserByGoogleID :one
-SELECT id, email, google_id, name, created_at, updated_at
-FROM users
-WHERE google_id = $1
-`
-
-type GetUserByGoogleIDRow struct {
-	ID        pgtype.UUID
-	Email     string
-	GoogleID  *string
-	Name      *string
-	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
-}
-
-func (q *Queries) GetUserByGoogleID(ctx context.Context, googleID *string) (GetUserByGoogleIDRow, error) {
-	row := q.db.QueryRow(ctx, getUserByGoogleID, googleID)
-	var i GetUserByGoogleIDRow
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.GoogleID,
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-select id, email, name, age, gender, blood_group, emergency_contact_number, emergency_contact_relationship, updated_at 
+select id, email, name, age, gender, blood_group, emergency_contact_number, emergency_contact_relationship, timezone, updated_at 
 FROM users 
 WHERE id = $1
 `
@@ -764,6 +1204,7 @@ type GetUserByIDRow struct {
 	BloodGroup                   *string
 	EmergencyContactNumber       *string
 	EmergencyContactRelationship *string
+	Timezone                     string
 	UpdatedAt                    pgtype.Timestamp
 }
 
@@ -779,22 +1220,23 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (GetUserByIDR
 		&i.BloodGroup,
 		&i.EmergencyContactNumber,
 		&i.EmergencyContactRelationship,
+		&i.Timezone,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getUserFCMToken = `-- name: GetUserFCMToken :one
-SELECT fcm_token
+const getUserEmailVerifiedAt = `-- name: GetUserEmailVerifiedAt :one
+SELECT email_verified_at
 FROM users
 WHERE id = $1
 `
 
-func (q *Queries) GetUserFCMToken(ctx context.Context, id pgtype.UUID) (*string, error) {
-	row := q.db.QueryRow(ctx, getUserFCMToken, id)
-	var fcm_token *string
-	err := row.Scan(&fcm_token)
-	return fcm_token, err
+func (q *Queries) GetUserEmailVerifiedAt(ctx context.Context, id pgtype.UUID) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getUserEmailVerifiedAt, id)
+	var email_verified_at pgtype.Timestamptz
+	err := row.Scan(&email_verified_at)
+	return email_verified_at, err
 }
 
 const getUserFiles = `-- name: GetUserFiles :many
@@ -831,7 +1273,7 @@ func (q *Queries) GetUserFiles(ctx context.Context, userID pgtype.UUID) ([]Encry
 }
 
 const getUserProfileByID = `-- name: GetUserProfileByID :one
-SELECT id, email, password_hash, fcm_token, google_id, name, age, gender, blood_group, emergency_contact_number, emergency_contact_relationship, created_at, updated_at
+SELECT id, email, password_hash, name, age, gender, blood_group, emergency_contact_number, emergency_contact_relationship, created_at, updated_at, email_verified_at, suspended_at, timezone
 FROM users
 WHERE id = $1
 `
@@ -843,8 +1285,6 @@ func (q *Queries) GetUserProfileByID(ctx context.Context, id pgtype.UUID) (User,
 		&i.ID,
 		&i.Email,
 		&i.PasswordHash,
-		&i.FcmToken,
-		&i.GoogleID,
 		&i.Name,
 		&i.Age,
 		&i.Gender,
@@ -853,41 +1293,46 @@ func (q *Queries) GetUserProfileByID(ctx context.Context, id pgtype.UUID) (User,
 		&i.EmergencyContactRelationship,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.Timezone,
 	)
 	return i, err
 }
 
-const listDoctors = `-- name: ListDoctors :many
-SELECT id, name, password_hash, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, created_at, updated_at
-FROM doctors
+const getUserTimezone = `-- name: GetUserTimezone :one
+SELECT timezone
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserTimezone(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getUserTimezone, id)
+	var timezone string
+	err := row.Scan(&timezone)
+	return timezone, err
+}
+
+const listActiveBookingStarts = `-- name: ListActiveBookingStarts :many
+SELECT booking_start_time
+FROM bookings
+WHERE availability_id = $1
+  AND status IN ('pending', 'confirmed', 'completed', 'no_show')
 `
 
-func (q *Queries) ListDoctors(ctx context.Context) ([]Doctor, error) {
-	rows, err := q.db.Query(ctx, listDoctors)
+func (q *Queries) ListActiveBookingStarts(ctx context.Context, availabilityID pgtype.UUID) ([]pgtype.Time, error) {
+	rows, err := q.db.Query(ctx, listActiveBookingStarts, availabilityID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Doctor
+	var items []pgtype.Time
 	for rows.Next() {
-		var i Doctor
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.PasswordHash,
-			&i.Specialization,
-			&i.Experience,
-			&i.Qualification,
-			&i.HospitalName,
-			&i.ConsultationFee,
-			&i.ContactNumber,
-			&i.Email,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
+		var booking_start_time pgtype.Time
+		if err := rows.Scan(&booking_start_time); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, booking_start_time)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -895,21 +1340,453 @@ func (q *Queries) ListDoctors(ctx context.Context) ([]Doctor, error) {
 	return items, nil
 }
 
-const storeEncryptedFile = `-- name: StoreEncryptedFile :one
-INSERT INTO encrypted_files (user_id, file_name, file_data)
-VALUES ($1, $2, $3)
-RETURNING id, created_at
+const listActiveBookingStartsByDoctorAndDate = `-- name: ListActiveBookingStartsByDoctorAndDate :many
+SELECT availability_id, booking_start_time
+FROM bookings
+WHERE doctor_id = $1
+  AND booking_date = $2
+  AND status IN ('pending', 'confirmed', 'completed', 'no_show')
 `
 
-type StoreEncryptedFileParams struct {
-	UserID   pgtype.UUID
-	FileName string
-	FileData []byte
+type ListActiveBookingStartsByDoctorAndDateParams struct {
+	DoctorID    pgtype.UUID
+	BookingDate pgtype.Date
 }
 
-type StoreEncryptedFileRow struct {
-	ID        pgtype.UUID
-	CreatedAt pgtype.Timestamp
+type ListActiveBookingStartsByDoctorAndDateRow struct {
+	AvailabilityID   pgtype.UUID
+	BookingStartTime pgtype.Time
+}
+
+func (q *Queries) ListActiveBookingStartsByDoctorAndDate(ctx context.Context, arg ListActiveBookingStartsByDoctorAndDateParams) ([]ListActiveBookingStartsByDoctorAndDateRow, error) {
+	rows, err := q.db.Query(ctx, listActiveBookingStartsByDoctorAndDate, arg.DoctorID, arg.BookingDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveBookingStartsByDoctorAndDateRow
+	for rows.Next() {
+		var i ListActiveBookingStartsByDoctorAndDateRow
+		if err := rows.Scan(&i.AvailabilityID, &i.BookingStartTime); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookingStatusHistory = `-- name: ListBookingStatusHistory :many
+SELECT id, booking_id, from_status, to_status, changed_by, changed_by_role, reason, created_at
+FROM booking_status_history
+WHERE booking_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListBookingStatusHistory(ctx context.Context, bookingID pgtype.UUID) ([]BookingStatusHistory, error) {
+	rows, err := q.db.Query(ctx, listBookingStatusHistory, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookingStatusHistory
+	for rows.Next() {
+		var i BookingStatusHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookingID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.ChangedBy,
+			&i.ChangedByRole,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDoctorLicenseDocuments = `-- name: ListDoctorLicenseDocuments :many
+SELECT id, file_name, created_at
+FROM doctor_license_documents
+WHERE doctor_id = $1
+ORDER BY created_at DESC
+`
+
+type ListDoctorLicenseDocumentsRow struct {
+	ID        pgtype.UUID
+	FileName  string
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) ListDoctorLicenseDocuments(ctx context.Context, doctorID pgtype.UUID) ([]ListDoctorLicenseDocumentsRow, error) {
+	rows, err := q.db.Query(ctx, listDoctorLicenseDocuments, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDoctorLicenseDocumentsRow
+	for rows.Next() {
+		var i ListDoctorLicenseDocumentsRow
+		if err := rows.Scan(&i.ID, &i.FileName, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUpcomingAvailabilityByDoctor = `-- name: ListUpcomingAvailabilityByDoctor :many
+SELECT id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at, rule_id, slot_minutes, capacity, booking_mode, starts_at, ends_at
+FROM doctor_availability
+WHERE doctor_id = $1
+  AND ends_at > NOW()
+`
+
+func (q *Queries) ListUpcomingAvailabilityByDoctor(ctx context.Context, doctorID pgtype.UUID) ([]DoctorAvailability, error) {
+	rows, err := q.db.Query(ctx, listUpcomingAvailabilityByDoctor, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DoctorAvailability
+	for rows.Next() {
+		var i DoctorAvailability
+		if err := rows.Scan(
+			&i.ID,
+			&i.DoctorID,
+			&i.AvailabilityDate,
+			&i.StartTime,
+			&i.EndTime,
+			&i.IsBooked,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RuleID,
+			&i.SlotMinutes,
+			&i.Capacity,
+			&i.BookingMode,
+			&i.StartsAt,
+			&i.EndsAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAvailability = `-- name: LockAvailability :one
+SELECT id, doctor_id, availability_date, start_time, end_time, is_booked, created_at, updated_at, rule_id, slot_minutes, capacity, booking_mode, starts_at, ends_at
+FROM doctor_availability
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockAvailability(ctx context.Context, id pgtype.UUID) (DoctorAvailability, error) {
+	row := q.db.QueryRow(ctx, lockAvailability, id)
+	var i DoctorAvailability
+	err := row.Scan(
+		&i.ID,
+		&i.DoctorID,
+		&i.AvailabilityDate,
+		&i.StartTime,
+		&i.EndTime,
+		&i.IsBooked,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RuleID,
+		&i.SlotMinutes,
+		&i.Capacity,
+		&i.BookingMode,
+		&i.StartsAt,
+		&i.EndsAt,
+	)
+	return i, err
+}
+
+const markBookingCancelled = `-- name: MarkBookingCancelled :exec
+UPDATE bookings
+SET
+    cancelled_at = NOW(),
+    cancellation_reason = $2,
+    late_cancellation_fee = $3
+WHERE id = $1
+`
+
+type MarkBookingCancelledParams struct {
+	ID                  pgtype.UUID
+	CancellationReason  *string
+	LateCancellationFee bool
+}
+
+func (q *Queries) MarkBookingCancelled(ctx context.Context, arg MarkBookingCancelledParams) error {
+	_, err := q.db.Exec(ctx, markBookingCancelled, arg.ID, arg.CancellationReason, arg.LateCancellationFee)
+	return err
+}
+
+const markDoctorEmailVerified = `-- name: MarkDoctorEmailVerified :exec
+UPDATE doctors
+SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkDoctorEmailVerified(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markDoctorEmailVerified, id)
+	return err
+}
+
+const markUserEmailVerified = `-- name: MarkUserEmailVerified :exec
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkUserEmailVerified(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markUserEmailVerified, id)
+	return err
+}
+
+const nextQueuePosition = `-- name: NextQueuePosition :one
+SELECT (COALESCE(MAX(queue_position), 0) + 1)::integer AS next_position
+FROM bookings
+WHERE availability_id = $1
+`
+
+func (q *Queries) NextQueuePosition(ctx context.Context, availabilityID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, nextQueuePosition, availabilityID)
+	var next_position int32
+	err := row.Scan(&next_position)
+	return next_position, err
+}
+
+const resubmitRejectedDoctor = `-- name: ResubmitRejectedDoctor :execrows
+UPDATE doctors
+SET verification_status = 'pending_verification', updated_at = NOW()
+WHERE id = $1 AND verification_status = 'rejected'
+`
+
+// Puts a rejected doctor back in the review queue.
+func (q *Queries) ResubmitRejectedDoctor(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, resubmitRejectedDoctor, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchDoctors = `-- name: SearchDoctors :many
+WITH candidates AS (
+  SELECT d.id, d.name, d.specialization, d.experience, d.qualification, d.hospital_name,
+         d.consultation_fee, d.timezone, d.rating_average, d.rating_count,
+         next_free.starts_at AS next_available_at,
+         CASE $1::text
+           WHEN 'fee' THEN d.consultation_fee::float8
+           WHEN '-fee' THEN -d.consultation_fee::float8
+           WHEN 'experience' THEN d.experience::float8
+           WHEN '-experience' THEN -d.experience::float8
+           WHEN '-rating' THEN -coalesce(d.rating_average, 0)::float8
+           WHEN 'relevance' THEN -ts_rank(doctor_search_document(d.name, d.specialization, d.hospital_name),
+                                          websearch_to_tsquery('simple', coalesce($2::text, ''))
+                                          || websearch_to_tsquery('english', coalesce($2::text, '')))::float8
+           ELSE coalesce(extract(epoch FROM next_free.starts_at)::float8, 'Infinity'::float8)
+         END AS sort_key
+  FROM doctors d
+  LEFT JOIN LATERAL (
+    SELECT min(a.starts_at) AS starts_at
+    FROM doctor_availability a
+    WHERE a.doctor_id = d.id
+      AND a.is_booked IS NOT TRUE
+      AND a.starts_at > NOW()
+  ) next_free ON TRUE
+  WHERE d.verification_status = 'verified'
+    AND d.suspended_at IS NULL
+    AND d.deactivated_at IS NULL
+    AND ($2::text IS NULL
+         OR doctor_search_document(d.name, d.specialization, d.hospital_name) @@ websearch_to_tsquery('simple', $2::text)
+         OR doctor_search_document(d.name, d.specialization, d.hospital_name) @@ websearch_to_tsquery('english', $2::text))
+    AND ($3::text IS NULL OR d.name ILIKE '%' || $3::text || '%' ESCAPE '\')
+    AND ($4::text IS NULL OR lower(d.specialization) = lower($4::text))
+    AND ($5::text IS NULL OR lower(d.hospital_name) = lower($5::text))
+    AND ($6::int IS NULL OR d.experience >= $6::int)
+    AND ($7::float8 IS NULL OR d.consultation_fee >= $7::float8::numeric)
+    AND ($8::float8 IS NULL OR d.consultation_fee <= $8::float8::numeric)
+    AND ($9::date IS NULL OR EXISTS (
+        SELECT 1
+        FROM doctor_availability a
+        WHERE a.doctor_id = d.id
+          AND a.availability_date = $9::date
+          AND a.is_booked IS NOT TRUE
+          AND a.ends_at > NOW()
+    ))
+)
+SELECT id::uuid AS id, name::text AS name, specialization::text AS specialization, experience::int AS experience,
+       qualification::text AS qualification, hospital_name::text AS hospital_name,
+       consultation_fee::numeric AS consultation_fee, timezone::text AS timezone,
+       rating_average::numeric AS rating_average, rating_count::int AS rating_count,
+       next_available_at::timestamptz AS next_available_at, sort_key::float8 AS sort_key
+FROM candidates
+WHERE $10::uuid IS NULL
+   OR (sort_key, id) > ($11::float8, $10::uuid)
+ORDER BY sort_key, id
+LIMIT $12
+`
+
+type SearchDoctorsParams struct {
+	SortBy         string
+	Query          *string
+	Name           *string
+	Specialization *string
+	HospitalName   *string
+	MinExperience  *int32
+	MinFee         *float64
+	MaxFee         *float64
+	AvailableOn    pgtype.Date
+	AfterID        pgtype.UUID
+	AfterKey       *float64
+	PageSize       int32
+}
+
+type SearchDoctorsRow struct {
+	ID              pgtype.UUID
+	Name            string
+	Specialization  string
+	Experience      int32
+	Qualification   string
+	HospitalName    string
+	ConsultationFee pgtype.Numeric
+	Timezone        string
+	RatingAverage   pgtype.Numeric
+	RatingCount     int32
+	NextAvailableAt pgtype.Timestamptz
+	SortKey         float64
+}
+
+// Verified doctors matching the filters, ordered by sort_key and id. The
+// page starts after the (after_key, after_id) cursor when one is given.
+func (q *Queries) SearchDoctors(ctx context.Context, arg SearchDoctorsParams) ([]SearchDoctorsRow, error) {
+	rows, err := q.db.Query(ctx, searchDoctors,
+		arg.SortBy,
+		arg.Query,
+		arg.Name,
+		arg.Specialization,
+		arg.HospitalName,
+		arg.MinExperience,
+		arg.MinFee,
+		arg.MaxFee,
+		arg.AvailableOn,
+		arg.AfterID,
+		arg.AfterKey,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchDoctorsRow
+	for rows.Next() {
+		var i SearchDoctorsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Specialization,
+			&i.Experience,
+			&i.Qualification,
+			&i.HospitalName,
+			&i.ConsultationFee,
+			&i.Timezone,
+			&i.RatingAverage,
+			&i.RatingCount,
+			&i.NextAvailableAt,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAvailabilityInstants = `-- name: SetAvailabilityInstants :exec
+UPDATE doctor_availability
+SET starts_at = $1, ends_at = $2, updated_at = NOW()
+WHERE id = $3
+`
+
+type SetAvailabilityInstantsParams struct {
+	StartsAt pgtype.Timestamptz
+	EndsAt   pgtype.Timestamptz
+	ID       pgtype.UUID
+}
+
+func (q *Queries) SetAvailabilityInstants(ctx context.Context, arg SetAvailabilityInstantsParams) error {
+	_, err := q.db.Exec(ctx, setAvailabilityInstants, arg.StartsAt, arg.EndsAt, arg.ID)
+	return err
+}
+
+const setDoctorDeactivated = `-- name: SetDoctorDeactivated :exec
+UPDATE doctors
+SET deactivated_at = $1, updated_at = NOW()
+WHERE id = $2
+`
+
+type SetDoctorDeactivatedParams struct {
+	DeactivatedAt pgtype.Timestamptz
+	ID            pgtype.UUID
+}
+
+func (q *Queries) SetDoctorDeactivated(ctx context.Context, arg SetDoctorDeactivatedParams) error {
+	_, err := q.db.Exec(ctx, setDoctorDeactivated, arg.DeactivatedAt, arg.ID)
+	return err
+}
+
+const setMedicationNextNotifyAt = `-- name: SetMedicationNextNotifyAt :exec
+UPDATE medications
+SET next_notify_at = $1
+WHERE id = $2
+`
+
+type SetMedicationNextNotifyAtParams struct {
+	NextNotifyAt pgtype.Timestamptz
+	ID           pgtype.UUID
+}
+
+func (q *Queries) SetMedicationNextNotifyAt(ctx context.Context, arg SetMedicationNextNotifyAtParams) error {
+	_, err := q.db.Exec(ctx, setMedicationNextNotifyAt, arg.NextNotifyAt, arg.ID)
+	return err
+}
+
+const storeEncryptedFile = `-- name: StoreEncryptedFile :one
+INSERT INTO encrypted_files (user_id, file_name, file_data)
+VALUES ($1, $2, $3)
+RETURNING id, created_at
+`
+
+type StoreEncryptedFileParams struct {
+	UserID   pgtype.UUID
+	FileName string
+	FileData []byte
+}
+
+type StoreEncryptedFileRow struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamp
 }
 
 func (q *Queries) StoreEncryptedFile(ctx context.Context, arg StoreEncryptedFileParams) (StoreEncryptedFileRow, error) {
@@ -961,6 +1838,8 @@ SET
     start_time = COALESCE($1, start_time),
     end_time = COALESCE($2, end_time),
     is_booked = COALESCE($3, is_booked),
+    starts_at = COALESCE($6, starts_at),
+    ends_at = COALESCE($7, ends_at),
     updated_at = NOW()
 WHERE id = $4 AND doctor_id = $5
 `
@@ -971,6 +1850,8 @@ type UpdateDoctorAvailabilityParams struct {
 	IsBooked  *bool
 	ID        pgtype.UUID
 	DoctorID  pgtype.UUID
+	StartsAt  pgtype.Timestamptz
+	EndsAt    pgtype.Timestamptz
 }
 
 func (q *Queries) UpdateDoctorAvailability(ctx context.Context, arg UpdateDoctorAvailabilityParams) error {
@@ -980,7 +1861,162 @@ func (q *Queries) UpdateDoctorAvailability(ctx context.Context, arg UpdateDoctor
 		arg.IsBooked,
 		arg.ID,
 		arg.DoctorID,
+		arg.StartsAt,
+		arg.EndsAt,
+	)
+	return err
+}
+
+const updateDoctorCancellationPolicy = `-- name: UpdateDoctorCancellationPolicy :exec
+UPDATE doctors
+SET
+    cancellation_notice_minutes = $2,
+    late_cancellation_fee = $3,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateDoctorCancellationPolicyParams struct {
+	ID                        pgtype.UUID
+	CancellationNoticeMinutes int32
+	LateCancellationFee       bool
+}
+
+func (q *Queries) UpdateDoctorCancellationPolicy(ctx context.Context, arg UpdateDoctorCancellationPolicyParams) error {
+	_, err := q.db.Exec(ctx, updateDoctorCancellationPolicy, arg.ID, arg.CancellationNoticeMinutes, arg.LateCancellationFee)
+	return err
+}
+
+const updateDoctorPassword = `-- name: UpdateDoctorPassword :exec
+UPDATE doctors
+SET password_hash = $2, updated_at = NOW()
+WHERE email = $1
+`
+
+type UpdateDoctorPasswordParams struct {
+	Email        *string
+	PasswordHash *string
+}
+
+func (q *Queries) UpdateDoctorPassword(ctx context.Context, arg UpdateDoctorPasswordParams) error {
+	_, err := q.db.Exec(ctx, updateDoctorPassword, arg.Email, arg.PasswordHash)
+	return err
+}
+
+const updateDoctorProfile = `-- name: UpdateDoctorProfile :one
+UPDATE doctors
+SET name = COALESCE($1, name),
+    specialization = COALESCE($2, specialization),
+    experience = COALESCE($3, experience),
+    qualification = COALESCE($4, qualification),
+    hospital_name = COALESCE($5, hospital_name),
+    consultation_fee = COALESCE($6, consultation_fee),
+    contact_number = COALESCE($7, contact_number),
+    bio = COALESCE($8, bio),
+    languages = COALESCE($9, languages),
+    clinic_address = COALESCE($10, clinic_address),
+    clinic_latitude = COALESCE($11, clinic_latitude),
+    clinic_longitude = COALESCE($12, clinic_longitude),
+    verification_status = CASE
+        WHEN (COALESCE($1, name),
+              COALESCE($2, specialization),
+              COALESCE($3, experience),
+              COALESCE($4, qualification),
+              COALESCE($5, hospital_name))
+             IS DISTINCT FROM (name, specialization, experience, qualification, hospital_name)
+        THEN 'pending_verification'
+        ELSE verification_status
+    END,
+    updated_at = NOW()
+WHERE id = $13
+RETURNING id, name, password_hash, specialization, experience, qualification, hospital_name, consultation_fee, contact_number, email, created_at, updated_at, email_verified_at, verification_status, verification_notes, reviewed_at, reviewed_by, suspended_at, mfa_secret, mfa_enabled_at, mfa_required, mfa_last_used_step, cancellation_notice_minutes, late_cancellation_fee, timezone, bio, languages, clinic_address, clinic_latitude, clinic_longitude, deactivated_at, rating_average, rating_count
+`
+
+type UpdateDoctorProfileParams struct {
+	Name            *string
+	Specialization  *string
+	Experience      *int32
+	Qualification   *string
+	HospitalName    *string
+	ConsultationFee pgtype.Numeric
+	ContactNumber   *string
+	Bio             *string
+	Languages       []string
+	ClinicAddress   *string
+	ClinicLatitude  *float64
+	ClinicLongitude *float64
+	ID              pgtype.UUID
+}
+
+// Changing a credential an admin verified sends the doctor back to review.
+func (q *Queries) UpdateDoctorProfile(ctx context.Context, arg UpdateDoctorProfileParams) (Doctor, error) {
+	row := q.db.QueryRow(ctx, updateDoctorProfile,
+		arg.Name,
+		arg.Specialization,
+		arg.Experience,
+		arg.Qualification,
+		arg.HospitalName,
+		arg.ConsultationFee,
+		arg.ContactNumber,
+		arg.Bio,
+		arg.Languages,
+		arg.ClinicAddress,
+		arg.ClinicLatitude,
+		arg.ClinicLongitude,
+		arg.ID,
+	)
+	var i Doctor
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PasswordHash,
+		&i.Specialization,
+		&i.Experience,
+		&i.Qualification,
+		&i.HospitalName,
+		&i.ConsultationFee,
+		&i.ContactNumber,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.VerificationStatus,
+		&i.VerificationNotes,
+		&i.ReviewedAt,
+		&i.ReviewedBy,
+		&i.SuspendedAt,
+		&i.MfaSecret,
+		&i.MfaEnabledAt,
+		&i.MfaRequired,
+		&i.MfaLastUsedStep,
+		&i.CancellationNoticeMinutes,
+		&i.LateCancellationFee,
+		&i.Timezone,
+		&i.Bio,
+		&i.Languages,
+		&i.ClinicAddress,
+		&i.ClinicLatitude,
+		&i.ClinicLongitude,
+		&i.DeactivatedAt,
+		&i.RatingAverage,
+		&i.RatingCount,
 	)
+	return i, err
+}
+
+const updateDoctorTimezone = `-- name: UpdateDoctorTimezone :exec
+UPDATE doctors
+SET timezone = $1, updated_at = NOW()
+WHERE id = $2
+`
+
+type UpdateDoctorTimezoneParams struct {
+	Timezone string
+	ID       pgtype.UUID
+}
+
+func (q *Queries) UpdateDoctorTimezone(ctx context.Context, arg UpdateDoctorTimezoneParams) error {
+	_, err := q.db.Exec(ctx, updateDoctorTimezone, arg.Timezone, arg.ID)
 	return err
 }
 
@@ -1020,6 +2056,7 @@ SET
     blood_group = COALESCE($4, blood_group),
     emergency_contact_number = COALESCE($5, emergency_contact_number),
     emergency_contact_relationship = COALESCE($6, emergency_contact_relationship),
+    timezone = COALESCE($8, timezone),
     updated_at = NOW()
 WHERE id = $7
 `
@@ -1032,6 +2069,7 @@ type UpdateUserProfileParams struct {
 	EmergencyContactNumber       *string
 	EmergencyContactRelationship *string
 	ID                           pgtype.UUID
+	Timezone                     *string
 }
 
 func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
@@ -1043,6 +2081,41 @@ func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfilePa
 		arg.EmergencyContactNumber,
 		arg.EmergencyContactRelationship,
 		arg.ID,
+		arg.Timezone,
 	)
 	return err
 }
+
+const updateUserTimezone = `-- name: UpdateUserTimezone :exec
+UPDATE users
+SET timezone = $1, updated_at = NOW()
+WHERE id = $2
+`
+
+type UpdateUserTimezoneParams struct {
+	Timezone string
+	ID       pgtype.UUID
+}
+
+func (q *Queries) UpdateUserTimezone(ctx context.Context, arg UpdateUserTimezoneParams) error {
+	_, err := q.db.Exec(ctx, updateUserTimezone, arg.Timezone, arg.ID)
+	return err
+}
+
+const upsertDoctorProfilePhoto = `-- name: UpsertDoctorProfilePhoto :exec
+INSERT INTO doctor_profile_photos (doctor_id, content_type, data)
+VALUES ($1, $2, $3)
+ON CONFLICT (doctor_id) DO UPDATE
+SET content_type = EXCLUDED.content_type, data = EXCLUDED.data, updated_at = NOW()
+`
+
+type UpsertDoctorProfilePhotoParams struct {
+	DoctorID    pgtype.UUID
+	ContentType string
+	Data        []byte
+}
+
+func (q *Queries) UpsertDoctorProfilePhoto(ctx context.Context, arg UpsertDoctorProfilePhotoParams) error {
+	_, err := q.db.Exec(ctx, upsertDoctorProfilePhoto, arg.DoctorID, arg.ContentType, arg.Data)
+	return err
+}
@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/admin"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoutes(r *gin.Engine, queries *repository.Queries, mail mailer.Mailer) {
+	adminGroup := r.Group("/admin")
+	adminGroup.Use(middleware.ValidateJWT(queries), middleware.RequireRole(middleware.RoleAdmin))
+	{
+		adminGroup.GET("/users", func(ctx *gin.Context) {
+			admin.ListUsersHandler(ctx, queries)
+		})
+		adminGroup.POST("/users/:userId/suspend", func(ctx *gin.Context) {
+			admin.SuspendUserHandler(ctx, queries)
+		})
+		adminGroup.POST("/users/:userId/unsuspend", func(ctx *gin.Context) {
+			admin.UnsuspendUserHandler(ctx, queries)
+		})
+		adminGroup.POST("/users/:userId/logout", func(ctx *gin.Context) {
+			admin.ForceLogoutUserHandler(ctx, queries)
+		})
+
+		adminGroup.GET("/doctors", func(ctx *gin.Context) {
+			admin.ListDoctorsHandler(ctx, queries)
+		})
+		adminGroup.GET("/doctors/:doctorId/license-documents", func(ctx *gin.Context) {
+			admin.ListDoctorLicenseDocumentsHandler(ctx, queries)
+		})
+		adminGroup.GET("/doctors/:doctorId/license-documents/:documentId", func(ctx *gin.Context) {
+			admin.DownloadDoctorLicenseDocumentHandler(ctx, queries)
+		})
+		adminGroup.POST("/doctors/:doctorId/approve", func(ctx *gin.Context) {
+			admin.ApproveDoctorHandler(ctx, queries, mail)
+		})
+		adminGroup.POST("/doctors/:doctorId/reject", func(ctx *gin.Context) {
+			admin.RejectDoctorHandler(ctx, queries, mail)
+		})
+		adminGroup.POST("/doctors/:doctorId/suspend", func(ctx *gin.Context) {
+			admin.SuspendDoctorHandler(ctx, queries)
+		})
+		adminGroup.POST("/doctors/:doctorId/unsuspend", func(ctx *gin.Context) {
+			admin.UnsuspendDoctorHandler(ctx, queries)
+		})
+		adminGroup.POST("/doctors/:doctorId/logout", func(ctx *gin.Context) {
+			admin.ForceLogoutDoctorHandler(ctx, queries)
+		})
+		adminGroup.POST("/doctors/:doctorId/mfa/require", func(ctx *gin.Context) {
+			admin.RequireDoctorMFAHandler(ctx, queries)
+		})
+		adminGroup.POST("/doctors/:doctorId/mfa/optional", func(ctx *gin.Context) {
+			admin.MakeDoctorMFAOptionalHandler(ctx, queries)
+		})
+		adminGroup.POST("/doctors/:doctorId/mfa/reset", func(ctx *gin.Context) {
+			admin.ResetDoctorMFAHandler(ctx, queries)
+		})
+
+		adminGroup.GET("/bookings", func(ctx *gin.Context) {
+			admin.ListBookingsHandler(ctx, queries)
+		})
+		adminGroup.GET("/medications", func(ctx *gin.Context) {
+			admin.ListMedicationsHandler(ctx, queries)
+		})
+
+		adminGroup.GET("/availability", func(ctx *gin.Context) {
+			admin.ListAvailabilityHandler(ctx, queries)
+		})
+		adminGroup.DELETE("/availability/stale", func(ctx *gin.Context) {
+			admin.DeleteStaleAvailabilityHandler(ctx, queries)
+		})
+		adminGroup.DELETE("/availability/:availabilityId", func(ctx *gin.Context) {
+			admin.DeleteAvailabilityHandler(ctx, queries)
+		})
+
+		adminGroup.GET("/reviews/flagged", func(ctx *gin.Context) {
+			admin.ListFlaggedReviewsHandler(ctx, queries)
+		})
+		adminGroup.POST("/reviews/:reviewId/moderate", func(ctx *gin.Context) {
+			admin.ModerateReviewHandler(ctx, queries)
+		})
+
+		adminGroup.GET("/login-audit", func(ctx *gin.Context) {
+			admin.ListLoginAuditHandler(ctx, queries)
+		})
+	}
+}
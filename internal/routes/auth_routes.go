@@ -2,28 +2,122 @@ package routes
 
 import (
 	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/auth"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/loginguard"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/oidc"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthRoutes(r *gin.Engine, queries *repository.Queries) {
+func AuthRoutes(r *gin.Engine, queries *repository.Queries, mail mailer.Mailer, guard *loginguard.Guard, providers *oidc.Registry) {
+	r.GET("/.well-known/jwks.json", auth.JWKSHandler)
+
 	authGroup := r.Group("/auth")
 	{
 		authGroup.POST("/register/user", func(ctx *gin.Context) {
-			auth.UserRegisterHandler(ctx, queries)
+			auth.UserRegisterHandler(ctx, queries, mail)
 		})
 
 		authGroup.POST("/register/doctor", func(ctx *gin.Context) {
-			auth.DoctorRegisterHandler(ctx, queries)
+			auth.DoctorRegisterHandler(ctx, queries, mail)
 		})
 		authGroup.POST("/login/user", func(ctx *gin.Context) {
-			auth.UserLoginHandler(ctx, queries)
+			auth.UserLoginHandler(ctx, queries, guard)
 		})
 		authGroup.POST("/login/doctor", func(ctx *gin.Context) {
-			auth.DoctorLoginHandler(ctx, queries)
+			auth.DoctorLoginHandler(ctx, queries, guard)
+		})
+		authGroup.POST("/login/doctor/mfa", func(ctx *gin.Context) {
+			auth.DoctorMFALoginHandler(ctx, queries, guard)
+		})
+		authGroup.POST("/login/admin", func(ctx *gin.Context) {
+			auth.AdminLoginHandler(ctx, queries, guard)
+		})
+		authGroup.GET("/oidc/providers", func(ctx *gin.Context) {
+			auth.OIDCProvidersHandler(ctx, providers)
+		})
+		authGroup.GET("/oidc/:provider/start", func(ctx *gin.Context) {
+			auth.OIDCStartHandler(ctx, queries, providers, ctx.Param("provider"))
+		})
+		authGroup.GET("/oidc/:provider/callback", func(ctx *gin.Context) {
+			auth.OIDCCallbackHandler(ctx, queries, providers, ctx.Param("provider"))
+		})
+		// Kept for the redirect URL already registered with Google.
+		authGroup.GET("/google/start", func(ctx *gin.Context) {
+			auth.OIDCStartHandler(ctx, queries, providers, "google")
 		})
 		authGroup.GET("/google/callback", func(ctx *gin.Context) {
-			auth.GoogleAuthhandler(ctx, queries)
+			auth.OIDCCallbackHandler(ctx, queries, providers, "google")
+		})
+		authGroup.POST("/refresh", func(ctx *gin.Context) {
+			auth.RefreshTokenHandler(ctx, queries)
+		})
+		authGroup.POST("/logout", middleware.ValidateJWT(queries), func(ctx *gin.Context) {
+			auth.LogoutHandler(ctx, queries)
+		})
+		authGroup.POST("/password/forgot", func(ctx *gin.Context) {
+			auth.ForgotPasswordHandler(ctx, queries, mail)
+		})
+		authGroup.POST("/password/reset", func(ctx *gin.Context) {
+			auth.ResetPasswordHandler(ctx, queries)
+		})
+		authGroup.GET("/verify-email", func(ctx *gin.Context) {
+			auth.VerifyEmailHandler(ctx, queries)
+		})
+		authGroup.POST("/verify-email", func(ctx *gin.Context) {
+			auth.VerifyEmailHandler(ctx, queries)
+		})
+		authGroup.POST("/verify-email/resend", middleware.ValidateJWT(queries), func(ctx *gin.Context) {
+			auth.ResendVerificationEmailHandler(ctx, queries, mail)
+		})
+	}
+
+	// External identities linked to the signed in account.
+	identityGroup := r.Group("/auth/identities")
+	identityGroup.Use(middleware.ValidateJWT(queries), middleware.RequireRole(middleware.RoleUser, middleware.RoleDoctor))
+	{
+		identityGroup.GET("/", func(ctx *gin.Context) {
+			auth.ListIdentitiesHandler(ctx, queries)
+		})
+		identityGroup.DELETE("/:identityId", func(ctx *gin.Context) {
+			auth.UnlinkIdentityHandler(ctx, queries)
+		})
+	}
+
+	// Devices signed in to the caller's account.
+	sessionGroup := r.Group("/auth/sessions")
+	sessionGroup.Use(middleware.ValidateJWT(queries), middleware.RequireRole(middleware.RoleUser, middleware.RoleDoctor))
+	{
+		sessionGroup.GET("/", func(ctx *gin.Context) {
+			auth.ListSessionsHandler(ctx, queries)
+		})
+		sessionGroup.PUT("/current/device", func(ctx *gin.Context) {
+			auth.UpdateCurrentDeviceHandler(ctx, queries)
+		})
+		sessionGroup.DELETE("/:sessionId", func(ctx *gin.Context) {
+			auth.RevokeSessionHandler(ctx, queries)
+		})
+	}
+
+	// Two-factor authentication settings of the signed in doctor.
+	mfaGroup := r.Group("/auth/mfa")
+	mfaGroup.Use(middleware.ValidateJWT(queries), middleware.RequireRole(middleware.RoleDoctor))
+	{
+		mfaGroup.GET("/", func(ctx *gin.Context) {
+			auth.MFAStatusHandler(ctx, queries)
+		})
+		mfaGroup.POST("/enroll", func(ctx *gin.Context) {
+			auth.MFAEnrollHandler(ctx, queries)
+		})
+		mfaGroup.POST("/confirm", func(ctx *gin.Context) {
+			auth.MFAConfirmHandler(ctx, queries)
+		})
+		mfaGroup.POST("/recovery-codes", func(ctx *gin.Context) {
+			auth.MFARecoveryCodesHandler(ctx, queries)
+		})
+		mfaGroup.POST("/disable", func(ctx *gin.Context) {
+			auth.MFADisableHandler(ctx, queries)
 		})
 	}
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/booking"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/doctor"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/review"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
 	"github.com/gin-gonic/gin"
@@ -10,28 +11,157 @@ import (
 
 func DoctorRoutes(r *gin.Engine, queries *repository.Queries) {
 	doctorGroup := r.Group("/doctors/:doctorId/availability")
-	doctorGroup.Use(middleware.ValidateJWT())
+	doctorGroup.Use(middleware.ValidateJWT(queries))
+	// Mutations and booking details are restricted to the doctor who owns
+	// the :doctorId path.
+	doctorOnly := middleware.RequireRole(middleware.RoleDoctor)
+	ownsDoctorID := middleware.RequireOwnership("doctorId")
+	// Only doctors approved by an admin may publish availability.
+	verifiedDoctor := middleware.RequireVerifiedDoctor(queries)
+	// Patient data stays locked until doctors required to use two-factor
+	// authentication have enrolled.
+	doctorMFA := middleware.RequireDoctorMFA(queries)
 	{
-		doctorGroup.POST("/", func(ctx *gin.Context) {
+		doctorGroup.POST("/", doctorOnly, ownsDoctorID, verifiedDoctor, func(ctx *gin.Context) {
 			doctor.CreateAvailabilityHandler(ctx, queries)
 		})
-		doctorGroup.GET("/", func(ctx *gin.Context) {
+		doctorGroup.GET("/", middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser), func(ctx *gin.Context) {
 			doctor.GetAvailabilityByDoctorHandler(ctx, queries)
 		})
-		doctorGroup.GET("/date/:date", func(ctx *gin.Context) {
+		doctorGroup.GET("/date/:date", middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser), func(ctx *gin.Context) {
 			doctor.GetAvailabilityByDoctorAndDateHandler(ctx, queries)
 		})
-		doctorGroup.PUT("/:availabilityId/update", func(ctx *gin.Context) {
+		doctorGroup.GET("/next", middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser), func(ctx *gin.Context) {
+			doctor.GetNextFreeSlotsHandler(ctx, queries)
+		})
+		doctorGroup.GET("/calendar", middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser), func(ctx *gin.Context) {
+			doctor.GetAvailabilityCalendarHandler(ctx, queries)
+		})
+		doctorGroup.PUT("/:availabilityId/update", doctorOnly, ownsDoctorID, verifiedDoctor, func(ctx *gin.Context) {
 			doctor.UpdateAvailabilityHandler(ctx, queries)
 		})
-		doctorGroup.GET("/bookings", func(ctx *gin.Context) {
+		doctorGroup.GET("/bookings", doctorOnly, ownsDoctorID, doctorMFA, func(ctx *gin.Context) {
 			booking.GetBookingsByDoctorIDHandler(ctx, queries)
 		})
-		doctorGroup.PUT("/bookings/:bookingId/status", func(ctx *gin.Context) {
+		doctorGroup.PUT("/bookings/:bookingId/status", doctorOnly, ownsDoctorID, doctorMFA, func(ctx *gin.Context) {
 			booking.UpdateBookingStatusHandler(ctx, queries)
 		})
-		doctorGroup.DELETE("/:availabilityId/delete", func(ctx *gin.Context) {
+		doctorGroup.GET("/bookings/:bookingId/history", doctorOnly, ownsDoctorID, doctorMFA, func(ctx *gin.Context) {
+			booking.GetBookingStatusHistoryHandler(ctx, queries)
+		})
+		doctorGroup.DELETE("/:availabilityId/delete", doctorOnly, ownsDoctorID, func(ctx *gin.Context) {
 			doctor.DeleteAvailabilityHandler(ctx, queries)
 		})
 	}
+
+	// Recurring availability and the dates it does not apply to.
+	ruleGroup := r.Group("/doctors/:doctorId/availability-rules")
+	ruleGroup.Use(middleware.ValidateJWT(queries), doctorOnly, ownsDoctorID)
+	{
+		ruleGroup.POST("/", verifiedDoctor, func(ctx *gin.Context) {
+			doctor.CreateAvailabilityRuleHandler(ctx, queries)
+		})
+		ruleGroup.GET("/", func(ctx *gin.Context) {
+			doctor.ListAvailabilityRulesHandler(ctx, queries)
+		})
+		ruleGroup.POST("/preview", func(ctx *gin.Context) {
+			doctor.PreviewAvailabilityRuleHandler(ctx, queries)
+		})
+		ruleGroup.DELETE("/:ruleId", func(ctx *gin.Context) {
+			doctor.DeleteAvailabilityRuleHandler(ctx, queries)
+		})
+	}
+
+	exceptionGroup := r.Group("/doctors/:doctorId/availability-exceptions")
+	exceptionGroup.Use(middleware.ValidateJWT(queries), doctorOnly, ownsDoctorID)
+	{
+		exceptionGroup.POST("/", func(ctx *gin.Context) {
+			doctor.CreateAvailabilityExceptionHandler(ctx, queries)
+		})
+		exceptionGroup.GET("/", func(ctx *gin.Context) {
+			doctor.ListAvailabilityExceptionsHandler(ctx, queries)
+		})
+		exceptionGroup.DELETE("/:exceptionId", func(ctx *gin.Context) {
+			doctor.DeleteAvailabilityExceptionHandler(ctx, queries)
+		})
+	}
+
+	policyGroup := r.Group("/doctors/:doctorId/cancellation-policy")
+	policyGroup.Use(middleware.ValidateJWT(queries))
+	{
+		policyGroup.GET("/", middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser), func(ctx *gin.Context) {
+			doctor.GetCancellationPolicyHandler(ctx, queries)
+		})
+		policyGroup.PUT("/", doctorOnly, ownsDoctorID, func(ctx *gin.Context) {
+			doctor.UpdateCancellationPolicyHandler(ctx, queries)
+		})
+	}
+
+	// The authenticated doctor's own profile.
+	meGroup := r.Group("/doctor/me")
+	meGroup.Use(middleware.ValidateJWT(queries), doctorOnly)
+	{
+		meGroup.GET("", func(ctx *gin.Context) {
+			doctor.GetMyDoctorProfileHandler(ctx, queries)
+		})
+		meGroup.PATCH("", func(ctx *gin.Context) {
+			doctor.UpdateMyDoctorProfileHandler(ctx, queries)
+		})
+		meGroup.DELETE("", func(ctx *gin.Context) {
+			doctor.DeactivateDoctorHandler(ctx, queries)
+		})
+		meGroup.POST("/reactivate", func(ctx *gin.Context) {
+			doctor.ReactivateDoctorHandler(ctx, queries)
+		})
+		meGroup.PUT("/photo", func(ctx *gin.Context) {
+			doctor.UploadProfilePhotoHandler(ctx, queries)
+		})
+		meGroup.DELETE("/photo", func(ctx *gin.Context) {
+			doctor.DeleteProfilePhotoHandler(ctx, queries)
+		})
+	}
+
+	photoGroup := r.Group("/doctors/:doctorId/photo")
+	photoGroup.Use(middleware.ValidateJWT(queries), middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser))
+	{
+		photoGroup.GET("", func(ctx *gin.Context) {
+			doctor.GetProfilePhotoHandler(ctx, queries)
+		})
+	}
+
+	timezoneGroup := r.Group("/doctors/:doctorId/timezone")
+	timezoneGroup.Use(middleware.ValidateJWT(queries))
+	{
+		timezoneGroup.GET("/", middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser), func(ctx *gin.Context) {
+			doctor.GetDoctorTimezoneHandler(ctx, queries)
+		})
+		timezoneGroup.PUT("/", doctorOnly, ownsDoctorID, func(ctx *gin.Context) {
+			doctor.UpdateDoctorTimezoneHandler(ctx, queries)
+		})
+	}
+
+	reviewGroup := r.Group("/doctors/:doctorId/reviews")
+	reviewGroup.Use(middleware.ValidateJWT(queries))
+	{
+		reviewGroup.GET("/", middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser), func(ctx *gin.Context) {
+			review.ListDoctorReviewsHandler(ctx, queries)
+		})
+		reviewGroup.PUT("/:reviewId/reply", doctorOnly, ownsDoctorID, func(ctx *gin.Context) {
+			review.ReplyToReviewHandler(ctx, queries)
+		})
+		reviewGroup.POST("/:reviewId/flag", middleware.RequireRole(middleware.RoleDoctor, middleware.RoleUser), func(ctx *gin.Context) {
+			review.FlagReviewHandler(ctx, queries)
+		})
+	}
+
+	licenseGroup := r.Group("/doctors/:doctorId/license-documents")
+	licenseGroup.Use(middleware.ValidateJWT(queries), doctorOnly, ownsDoctorID)
+	{
+		licenseGroup.POST("/", func(ctx *gin.Context) {
+			doctor.UploadLicenseDocumentHandler(ctx, queries)
+		})
+		licenseGroup.GET("/", func(ctx *gin.Context) {
+			doctor.ListLicenseDocumentsHandler(ctx, queries)
+		})
+	}
 }
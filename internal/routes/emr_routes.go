@@ -9,18 +9,22 @@ import (
 
 func EMRRoutes(r *gin.Engine, queries *repository.Queries) {
 	recordGroup := r.Group("/EMR")
-	recordGroup.Use(middleware.ValidateJWT())
+	recordGroup.Use(middleware.ValidateJWT(queries), middleware.RequireRole(middleware.RoleUser, middleware.RoleDoctor))
+	// Patients manage their own records; doctors may read the records of
+	// patients who booked with them.
+	ownerOrTreatingDoctor := middleware.RequireOwnership("userid", middleware.DoctorWithPatientBooking(queries))
+	doctorMFA := middleware.RequireDoctorMFA(queries)
 	{
-		recordGroup.POST("/:userid/emr/upload", func(ctx *gin.Context) {
+		recordGroup.POST("/:userid/emr/upload", middleware.RequireOwnership("userid"), middleware.RequireVerifiedEmail(queries), func(ctx *gin.Context) {
 			records.UploadMedicalRecord(ctx, queries)
 		})
-		recordGroup.GET("/:userid/emr/list", func(ctx *gin.Context) {
+		recordGroup.GET("/:userid/emr/list", ownerOrTreatingDoctor, doctorMFA, func(ctx *gin.Context) {
 			records.ListMedicalRecords(ctx, queries)
 		})
-		recordGroup.GET("/:userid/record/:fileid/emr/download", func(ctx *gin.Context) {
+		recordGroup.GET("/:userid/record/:fileid/emr/download", ownerOrTreatingDoctor, doctorMFA, func(ctx *gin.Context) {
 			records.DownloadMedicalRecord(ctx, queries)
 		})
-		recordGroup.GET("/:userid/emr/view", func(ctx *gin.Context) {
+		recordGroup.GET("/:userid/emr/view", ownerOrTreatingDoctor, doctorMFA, func(ctx *gin.Context) {
 			records.ViewMedicalRecord(ctx, queries)
 		})
 	}
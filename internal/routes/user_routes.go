@@ -3,51 +3,68 @@ package routes
 import (
 	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/booking"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/doctor"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/review"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/handler/user"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/mailer"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func UserRoutes(r *gin.Engine, queries *repository.Queries) {
+func UserRoutes(r *gin.Engine, queries *repository.Queries, mail mailer.Mailer) {
 	userGroup := r.Group("/user")
-	userGroup.Use(middleware.ValidateJWT())
+	userGroup.Use(middleware.ValidateJWT(queries), middleware.RequireRole(middleware.RoleUser))
 	{
 
-		userGroup.GET("/:userid/profile", func(ctx *gin.Context) {
+		userGroup.GET("/:userid/profile", middleware.RequireOwnership("userid"), func(ctx *gin.Context) {
 			user.GetUserProfile(ctx, queries)
 		})
-		userGroup.PUT("/updateprofile/:id", func(ctx *gin.Context) {
+		userGroup.PUT("/updateprofile/:id", middleware.RequireOwnership("id"), func(ctx *gin.Context) {
 			user.UpdateUserProfile(ctx, queries)
 		})
 		userGroup.GET("/doctors", func(ctx *gin.Context) {
 			user.ListDoctorsHandler(ctx, queries)
 		})
+		userGroup.GET("/doctors/next-available", func(ctx *gin.Context) {
+			doctor.GetNextFreeSlotsBySpecializationHandler(ctx, queries)
+		})
 		userGroup.GET("/doctors/:doctorId", func(ctx *gin.Context) {
 			user.GetDoctorByIDHandler(ctx, queries)
 		})
 		userGroup.GET("/doctors/:doctorId/availability", func(ctx *gin.Context) {
 			doctor.GetAvailabilityByDoctorHandler(ctx, queries)
 		})
-		userGroup.POST("/bookings/users/:userId/availability/:availabilityId", func(ctx *gin.Context) {
+		userGroup.POST("/bookings/users/:userId/availability/:availabilityId", middleware.RequireOwnership("userId"), middleware.RequireVerifiedEmail(queries), func(ctx *gin.Context) {
 			booking.CreateBookingHandler(ctx, queries)
 		})
+		userGroup.POST("/bookings/:bookingId/review", func(ctx *gin.Context) {
+			review.CreateReviewHandler(ctx, queries)
+		})
 		userGroup.GET("/bookings/:bookingId", func(ctx *gin.Context) {
 			booking.GetBookingByIDHandler(ctx, queries)
 		})
-		userGroup.GET("/bookings/users/:userId", func(ctx *gin.Context) {
+		userGroup.GET("/bookings/users/:userId", middleware.RequireOwnership("userId"), func(ctx *gin.Context) {
 			booking.GetBookingsByUserIDHandler(ctx, queries)
 		})
+		userGroup.PUT("/bookings/:bookingId/status", func(ctx *gin.Context) {
+			booking.UpdateBookingStatusHandler(ctx, queries)
+		})
+		userGroup.POST("/bookings/:bookingId/reschedule", middleware.RequireBookingOwnership("bookingId", queries), middleware.RequireVerifiedEmail(queries), func(ctx *gin.Context) {
+			booking.RescheduleBookingHandler(ctx, queries, mail)
+		})
+		userGroup.GET("/bookings/:bookingId/history", func(ctx *gin.Context) {
+			booking.GetBookingStatusHistoryHandler(ctx, queries)
+		})
 		userGroup.DELETE("/bookings/:bookingId", func(ctx *gin.Context) {
-			booking.DeleteBookingHandler(ctx, queries)
+			booking.CancelBookingHandler(ctx, queries)
 		})
-		userGroup.POST("/:user_id/medications", func(ctx *gin.Context) {
+		userGroup.POST("/:user_id/medications", middleware.RequireOwnership("user_id"), func(ctx *gin.Context) {
 			user.CreateMedicationHandler(ctx, queries)
 		})
-		userGroup.PUT("/:user_id/medications/:medication_id/read", func(ctx *gin.Context) {
+		userGroup.PUT("/:user_id/medications/:medication_id/read", middleware.RequireOwnership("user_id"), func(ctx *gin.Context) {
 			user.MarkMedicationAsReadHandler(ctx, queries)
 		})
-		userGroup.GET("/getmedications/:user_id", func(c *gin.Context) {
+		userGroup.GET("/getmedications/:user_id", middleware.RequireOwnership("user_id"), func(c *gin.Context) {
 			user.GetMedicationsByUserIDHandler(c, queries)
 		})
 	}
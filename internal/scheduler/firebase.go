@@ -2,11 +2,13 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/messaging"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
 	"github.com/joho/godotenv"
 	"google.golang.org/api/option"
 	// Replace with your actual import path
@@ -60,25 +62,50 @@ func InitializeFirebase() error {
 	return nil
 }
 
-// sendPushNotification sends a push notification using FCM.
-func sendPushNotification(_, medicationName, dosage string) error {
+// sendPushNotification sends a push notification to every given FCM token.
+// Tokens FCM reports as no longer registered belong to uninstalled apps and
+// are removed from their sessions. It fails only when no device was reached.
+func sendPushNotification(ctx context.Context, queries *repository.Queries, tokens []*string, medicationName, dosage string) error {
 	if firebaseApp == nil || messagingClient == nil {
 		log.Println("Firebase not initialized")
 		return os.ErrInvalid
 	}
 
-	message := &messaging.Message{
-		Token: "fwrAaiXwTBqN2XWdJuAm2A:APA91bExbnr3-F9yBQn4M-uXmW348KrL8KAgFq4uRlL8dUI0uZHmbOZFaZLB0_wMCiT-qpk_jW1D6fJ5R_YMwp0BE_NtD7O6bwZRXS1PdywfrGHGpoqFlDA",
+	registrationTokens := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token != nil {
+			registrationTokens = append(registrationTokens, *token)
+		}
+	}
+
+	message := &messaging.MulticastMessage{
+		Tokens: registrationTokens,
 		Notification: &messaging.Notification{
 			Title: "Medication Reminder",
 			Body:  medicationName + " - Dosage: " + dosage,
 		},
 	}
 
-	_, err := messagingClient.Send(context.Background(), message)
+	resp, err := messagingClient.SendEachForMulticast(ctx, message)
 	if err != nil {
 		return err
 	}
 
+	for i, result := range resp.Responses {
+		if result.Success {
+			continue
+		}
+		if messaging.IsRegistrationTokenNotRegistered(result.Error) {
+			if err := queries.ClearFCMToken(ctx, &registrationTokens[i]); err != nil {
+				log.Printf("Error clearing unregistered FCM token: %v", err)
+			}
+			continue
+		}
+		log.Printf("Error sending push notification to a device: %v", result.Error)
+	}
+
+	if resp.SuccessCount == 0 {
+		return fmt.Errorf("push notification reached none of %d devices", len(registrationTokens))
+	}
 	return nil
 }
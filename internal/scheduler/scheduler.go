@@ -7,39 +7,57 @@ import (
 	"time"
 
 	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/timezone"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-// checkMedicationsToNotify checks for medications that need to be notified.
+// checkMedicationsToNotify sends the reminders that are due and schedules
+// the next one of each medication at the same time on the user's clock.
 func checkMedicationsToNotify(ctx context.Context, queries *repository.Queries) {
-	now := time.Now().UTC() // Get current UTC time.
-	log.Printf("now time %v", now)
-	currentTime := pgtype.Time{
-		Microseconds: int64((now.Hour()*3600 + now.Minute()*60 + now.Second()) * 1e6),
-		Valid:        true,
-	}
-	readableTime := time.Unix(0, currentTime.Microseconds*1000).UTC().Format("15:04:05")
-	log.Printf("Checking medications for time: %v", readableTime)
+	now := time.Now()
+	log.Printf("Checking medications due by: %v", now.Format(time.RFC3339))
 
-	medications, err := queries.GetMedicationsToNotify(ctx, currentTime)
+	medications, err := queries.GetMedicationsToNotify(ctx, timezone.Timestamptz(now))
 	if err != nil {
 		log.Printf("Error retrieving medications: %v", err)
 		return
 	}
 
 	for _, medication := range medications {
-		go func(medication repository.Medication) { // Launch a Go routine for each medication
-			// Retrieve user's FCM token
-			fcmToken, err := queries.GetUserFCMToken(ctx, medication.UserID)
+		// Moving next_notify_at on before sending keeps the next tick from
+		// sending the same reminder again. Reminders missed while the
+		// scheduler was down are skipped.
+		loc := timezone.Stored(medication.Timezone)
+		days := timezone.IntervalDays(medication.Frequency)
+		next := timezone.Next(medication.TimeToNotify, loc, medication.NextNotifyAt.Time, days)
+		for !next.After(now) {
+			next = timezone.Next(medication.TimeToNotify, loc, next, days)
+		}
+		err := queries.SetMedicationNextNotifyAt(ctx, repository.SetMedicationNextNotifyAtParams{
+			ID:           medication.ID,
+			NextNotifyAt: timezone.Timestamptz(next),
+		})
+		if err != nil {
+			log.Printf("Error scheduling next reminder: %v", err)
+			continue
+		}
+
+		go func(medication repository.GetMedicationsToNotifyRow) { // Launch a Go routine for each medication
+			// Every device the user is signed in on gets the reminder.
+			tokens, err := queries.GetActiveFCMTokensByUser(ctx, medication.UserID)
 			if err != nil {
-				log.Printf("Error retrieving FCM token: %v", err)
+				log.Printf("Error retrieving FCM tokens: %v", err)
+				return
+			}
+			if len(tokens) == 0 {
+				log.Printf("No signed in devices to notify for medication: %s", medication.MedicationName)
 				return
 			}
 
 			// Send push notification using FCM
-			err = sendPushNotification(*fcmToken, medication.MedicationName, medication.Dosage) // Replace with your FCM logic
+			err = sendPushNotification(ctx, queries, tokens, medication.MedicationName, medication.Dosage)
 			if err != nil {
 				log.Printf("Error sending push notification: %v", err)
 				return
@@ -0,0 +1,98 @@
+// Package testdb connects handler tests to a PostgreSQL database that has
+// the migrations in db/migrations applied. Tests that need one are skipped
+// unless TEST_DATABASE_URL is set. Point it at a throwaway database: the
+// tests create their own accounts and slots and leave them behind.
+package testdb
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/database"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/middleware"
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Connect opens the test database and makes it database.DB, which the
+// handlers begin their transactions on.
+func Connect(t testing.TB) *repository.Queries {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), databaseURL)
+	if err != nil {
+		t.Fatalf("testdb: failed to open database: %v", err)
+	}
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		t.Fatalf("testdb: failed to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	database.DB = pool
+	return repository.New(pool)
+}
+
+// Email returns an address no other test run uses.
+func Email(prefix string) string {
+	return prefix + "-" + uuid.NewString() + "@example.test"
+}
+
+// NewUser creates a patient with a verified email address.
+func NewUser(t testing.TB, queries *repository.Queries) repository.CreateUserWithEmailRow {
+	t.Helper()
+	ctx := context.Background()
+
+	name := "Test Patient"
+	user, err := queries.CreateUserWithEmail(ctx, repository.CreateUserWithEmailParams{
+		Email: Email("patient"),
+		Name:  &name,
+	})
+	if err != nil {
+		t.Fatalf("testdb: failed to create user: %v", err)
+	}
+	if err := queries.MarkUserEmailVerified(ctx, user.ID); err != nil {
+		t.Fatalf("testdb: failed to verify user email: %v", err)
+	}
+	return user
+}
+
+// NewDoctor creates a verified doctor in the default timezone.
+func NewDoctor(t testing.TB, queries *repository.Queries) repository.CreateDoctorRow {
+	t.Helper()
+	ctx := context.Background()
+
+	var fee pgtype.Numeric
+	if err := fee.Scan("500.00"); err != nil {
+		t.Fatalf("testdb: %v", err)
+	}
+	email := Email("doctor")
+	doctor, err := queries.CreateDoctor(ctx, repository.CreateDoctorParams{
+		Name:            "Test Doctor",
+		Specialization:  "General Medicine",
+		Experience:      5,
+		Qualification:   "MBBS",
+		HospitalName:    "Test Hospital",
+		ConsultationFee: fee,
+		Email:           &email,
+	})
+	if err != nil {
+		t.Fatalf("testdb: failed to create doctor: %v", err)
+	}
+	_, err = queries.UpdateDoctorVerificationStatus(ctx, repository.UpdateDoctorVerificationStatusParams{
+		ID:                 doctor.ID,
+		VerificationStatus: middleware.DoctorVerified,
+	})
+	if err != nil {
+		t.Fatalf("testdb: failed to verify doctor: %v", err)
+	}
+	return doctor
+}
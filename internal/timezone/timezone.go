@@ -0,0 +1,126 @@
+// Package timezone converts between the wall clock times users and doctors
+// work with, in their own IANA timezone, and the instants stored as
+// timestamptz. Handlers and jobs go through it instead of doing the
+// arithmetic themselves.
+package timezone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	_ "time/tzdata" // the IANA database, for hosts without one
+
+	"github.com/SRIRAMGJ007/Health-Sync/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Default is the timezone of accounts that have not picked one.
+const Default = "Asia/Kolkata"
+
+// Load validates an IANA timezone name such as "Europe/Berlin".
+func Load(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("timezone must be an IANA name such as Europe/Berlin")
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// Stored loads a timezone read back from the database. A name the host does
+// not know falls back to Default rather than failing the request.
+func Stored(name string) *time.Location {
+	loc, err := Load(name)
+	if err != nil {
+		log.Printf("timezone: %v, using %s", err, Default)
+		loc, _ = time.LoadLocation(Default)
+	}
+	return loc
+}
+
+// Doctor returns the doctor's timezone.
+func Doctor(ctx context.Context, queries *repository.Queries, doctorID pgtype.UUID) (*time.Location, error) {
+	name, err := queries.GetDoctorTimezone(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	return Stored(name), nil
+}
+
+// User returns the user's timezone.
+func User(ctx context.Context, queries *repository.Queries, userID pgtype.UUID) (*time.Location, error) {
+	name, err := queries.GetUserTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return Stored(name), nil
+}
+
+// At is the instant a DATE and TIME read on a clock in loc. Wall clock
+// times that are skipped or repeated by a daylight saving change resolve to
+// one of the instants around it.
+func At(date pgtype.Date, clock pgtype.Time, loc *time.Location) time.Time {
+	y, m, d := date.Time.Date()
+	c := time.Duration(clock.Microseconds) * time.Microsecond
+	return time.Date(y, m, d, int(c/time.Hour), int(c%time.Hour/time.Minute), int(c%time.Minute/time.Second), int(c%time.Second), loc)
+}
+
+// Wall is the DATE and TIME a clock in loc shows at t.
+func Wall(t time.Time, loc *time.Location) (pgtype.Date, pgtype.Time) {
+	t = t.In(loc)
+	y, m, d := t.Date()
+	h, min, sec := t.Clock()
+	c := time.Duration(h)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second + time.Duration(t.Nanosecond())
+	return pgtype.Date{Time: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), Valid: true},
+		pgtype.Time{Microseconds: c.Microseconds(), Valid: true}
+}
+
+// Timestamptz wraps an instant for a timestamptz column.
+func Timestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+// In returns a stored instant on the clock of loc, so that it is written
+// out with loc's offset.
+func In(t pgtype.Timestamptz, loc *time.Location) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	local := t.Time.In(loc)
+	return &local
+}
+
+// ParseInstant parses an RFC 3339 timestamp. The offset is required.
+func ParseInstant(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New("timestamps must be RFC 3339 with an offset, e.g. 2025-03-10T09:00:00+05:30")
+	}
+	return t, nil
+}
+
+// Next is the first time after `after` that a clock in loc reads clock, on
+// after's local date or every days days from it. It keeps reminders at the
+// same local time across daylight saving changes.
+func Next(clock pgtype.Time, loc *time.Location, after time.Time, days int) time.Time {
+	date, _ := Wall(after, loc)
+	next := At(date, clock, loc)
+	for !next.After(after) {
+		date.Time = date.Time.AddDate(0, 0, days)
+		next = At(date, clock, loc)
+	}
+	return next
+}
+
+// IntervalDays is the number of days between two reminders of a medication
+// taken at frequency, "daily" or "weekly", for Next.
+func IntervalDays(frequency string) int {
+	if frequency == "weekly" {
+		return 7
+	}
+	return 1
+}
@@ -0,0 +1,134 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func date(y int, m time.Month, d int) pgtype.Date {
+	return pgtype.Date{Time: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), Valid: true}
+}
+
+func clock(h, m int) pgtype.Time {
+	return pgtype.Time{Microseconds: (time.Duration(h)*time.Hour + time.Duration(m)*time.Minute).Microseconds(), Valid: true}
+}
+
+func utc(y int, m time.Month, d, h, min int) time.Time {
+	return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+}
+
+func TestAt(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+	kolkata := mustLoad(t, "Asia/Kolkata")
+
+	tests := []struct {
+		name  string
+		date  pgtype.Date
+		clock pgtype.Time
+		loc   *time.Location
+		want  []time.Time // any of them
+	}{
+		{"standard time", date(2025, time.January, 15), clock(9, 0), newYork, []time.Time{utc(2025, time.January, 15, 14, 0)}},
+		{"daylight time", date(2025, time.July, 15), clock(9, 0), newYork, []time.Time{utc(2025, time.July, 15, 13, 0)}},
+		// 02:30 does not exist on March 9: the clock jumps from 02:00 EST to 03:00 EDT.
+		{"gap", date(2025, time.March, 9), clock(2, 30), newYork, []time.Time{utc(2025, time.March, 9, 6, 30), utc(2025, time.March, 9, 7, 30)}},
+		{"after the gap", date(2025, time.March, 9), clock(9, 0), newYork, []time.Time{utc(2025, time.March, 9, 13, 0)}},
+		// 01:30 happens twice on November 2, first in EDT and then in EST.
+		{"overlap", date(2025, time.November, 2), clock(1, 30), newYork, []time.Time{utc(2025, time.November, 2, 5, 30), utc(2025, time.November, 2, 6, 30)}},
+		{"after the overlap", date(2025, time.November, 2), clock(9, 0), newYork, []time.Time{utc(2025, time.November, 2, 14, 0)}},
+		{"half hour offset", date(2025, time.March, 10), clock(9, 0), kolkata, []time.Time{utc(2025, time.March, 10, 3, 30)}},
+		{"previous day in UTC", date(2025, time.March, 10), clock(2, 0), kolkata, []time.Time{utc(2025, time.March, 9, 20, 30)}},
+	}
+	for _, tt := range tests {
+		got := At(tt.date, tt.clock, tt.loc)
+		ok := false
+		for _, want := range tt.want {
+			ok = ok || got.Equal(want)
+		}
+		if !ok {
+			t.Errorf("%s: At = %s, want one of %v", tt.name, got.UTC(), tt.want)
+		}
+	}
+}
+
+func TestWall(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+	kolkata := mustLoad(t, "Asia/Kolkata")
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		loc       *time.Location
+		wantDate  pgtype.Date
+		wantClock pgtype.Time
+	}{
+		{"before the gap", utc(2025, time.March, 9, 6, 59), newYork, date(2025, time.March, 9), clock(1, 59)},
+		{"after the gap", utc(2025, time.March, 9, 7, 0), newYork, date(2025, time.March, 9), clock(3, 0)},
+		{"overlap in EDT", utc(2025, time.November, 2, 5, 30), newYork, date(2025, time.November, 2), clock(1, 30)},
+		{"overlap in EST", utc(2025, time.November, 2, 6, 30), newYork, date(2025, time.November, 2), clock(1, 30)},
+		{"next day in Kolkata", utc(2025, time.March, 9, 20, 30), kolkata, date(2025, time.March, 10), clock(2, 0)},
+	}
+	for _, tt := range tests {
+		gotDate, gotClock := Wall(tt.at, tt.loc)
+		if !gotDate.Time.Equal(tt.wantDate.Time) || gotClock != tt.wantClock {
+			t.Errorf("%s: Wall = %s %d, want %s %d", tt.name, gotDate.Time.Format("2006-01-02"), gotClock.Microseconds, tt.wantDate.Time.Format("2006-01-02"), tt.wantClock.Microseconds)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+	kolkata := mustLoad(t, "Asia/Kolkata")
+
+	tests := []struct {
+		name  string
+		clock pgtype.Time
+		loc   *time.Location
+		after time.Time
+		days  int
+		want  time.Time
+	}{
+		{"later today", clock(9, 0), kolkata, utc(2025, time.March, 10, 3, 0), 1, utc(2025, time.March, 10, 3, 30)},
+		{"at the time", clock(9, 0), kolkata, utc(2025, time.March, 10, 3, 30), 1, utc(2025, time.March, 11, 3, 30)},
+		{"weekly", clock(9, 0), kolkata, utc(2025, time.March, 10, 4, 0), 7, utc(2025, time.March, 17, 3, 30)},
+		// The reminder stays at 09:00 local, an hour earlier in UTC.
+		{"daily across spring forward", clock(9, 0), newYork, utc(2025, time.March, 8, 15, 0), 1, utc(2025, time.March, 9, 13, 0)},
+		{"daily across fall back", clock(9, 0), newYork, utc(2025, time.November, 1, 14, 0), 1, utc(2025, time.November, 2, 14, 0)},
+		{"weekly across fall back", clock(9, 0), newYork, utc(2025, time.October, 27, 14, 0), 7, utc(2025, time.November, 3, 14, 0)},
+	}
+	for _, tt := range tests {
+		if got := Next(tt.clock, tt.loc, tt.after, tt.days); !got.Equal(tt.want) {
+			t.Errorf("%s: Next = %s, want %s", tt.name, got.UTC(), tt.want)
+		}
+	}
+}
+
+// TestNextInGap checks that a reminder set inside the skipped hour still
+// fires once on that day, after the given time.
+func TestNextInGap(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+	after := utc(2025, time.March, 9, 5, 0) // 00:00 EST
+	got := Next(clock(2, 30), newYork, after, 1)
+	if !got.After(after) || got.After(utc(2025, time.March, 9, 8, 0)) {
+		t.Errorf("Next = %s, want a time on March 9 around the change", got.UTC())
+	}
+}
+
+func TestIntervalDays(t *testing.T) {
+	for frequency, want := range map[string]int{"daily": 1, "weekly": 7} {
+		if got := IntervalDays(frequency); got != want {
+			t.Errorf("IntervalDays(%q) = %d, want %d", frequency, got, want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// KeySet holds the key used to sign new tokens and every key that is still
+// accepted for verification, indexed by kid.
+type KeySet struct {
+	method           jwt.SigningMethod
+	activeKID        string
+	signingKey       interface{}
+	verificationKeys map[string]interface{}
+}
+
+// LoadKeySetFromEnv builds the key set from the environment:
+//
+//	JWT_ALGORITHM   HS256 (default), RS256 or EdDSA
+//	JWT_KEYS        comma separated kid:value pairs. For HS256 the value is
+//	                the shared secret, otherwise the path to a PEM file. A
+//	                public key PEM keeps a retired key valid for verification.
+//	JWT_ACTIVE_KID  kid used to sign new tokens, defaults to the first key
+//	JWT_SECRET      legacy single HS256 secret, used with kid "default" when
+//	                JWT_KEYS is not set
+//
+// To rotate, add the new key to JWT_KEYS, point JWT_ACTIVE_KID at it, and
+// drop the old key once the tokens it signed have expired.
+func LoadKeySetFromEnv() (*KeySet, error) {
+	alg := os.Getenv("JWT_ALGORITHM")
+	if alg == "" {
+		alg = jwt.SigningMethodHS256.Alg()
+	}
+
+	var method jwt.SigningMethod
+	switch alg {
+	case jwt.SigningMethodHS256.Alg():
+		method = jwt.SigningMethodHS256
+	case jwt.SigningMethodRS256.Alg():
+		method = jwt.SigningMethodRS256
+	case jwt.SigningMethodEdDSA.Alg():
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", alg)
+	}
+
+	entries, err := parseKeyEntries(os.Getenv("JWT_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 && method == jwt.SigningMethodHS256 && os.Getenv("JWT_SECRET") != "" {
+		entries = []keyEntry{{kid: "default", value: os.Getenv("JWT_SECRET")}}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no JWT signing keys configured, set JWT_KEYS or JWT_SECRET")
+	}
+
+	activeKID := os.Getenv("JWT_ACTIVE_KID")
+	if activeKID == "" {
+		activeKID = entries[0].kid
+	}
+
+	keys := &KeySet{
+		method:           method,
+		activeKID:        activeKID,
+		verificationKeys: make(map[string]interface{}, len(entries)),
+	}
+
+	for _, entry := range entries {
+		signingKey, verificationKey, err := loadKey(method, entry.value)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", entry.kid, err)
+		}
+		keys.verificationKeys[entry.kid] = verificationKey
+		if entry.kid == activeKID {
+			if signingKey == nil {
+				return nil, fmt.Errorf("JWT key %q is a public key and cannot be the active key", entry.kid)
+			}
+			keys.signingKey = signingKey
+		}
+	}
+
+	if keys.signingKey == nil {
+		return nil, fmt.Errorf("JWT_ACTIVE_KID %q is not one of the configured keys", activeKID)
+	}
+	return keys, nil
+}
+
+type keyEntry struct {
+	kid   string
+	value string
+}
+
+func parseKeyEntries(raw string) ([]keyEntry, error) {
+	var entries []keyEntry
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kid, value, ok := strings.Cut(pair, ":")
+		if !ok || kid == "" || value == "" {
+			return nil, fmt.Errorf("JWT_KEYS entry %q must be kid:value", pair)
+		}
+		entries = append(entries, keyEntry{kid: kid, value: value})
+	}
+	return entries, nil
+}
+
+// loadKey returns the signing and verification key for one entry. The
+// signing key is nil when only a public key was configured.
+func loadKey(method jwt.SigningMethod, value string) (interface{}, interface{}, error) {
+	if method == jwt.SigningMethodHS256 {
+		secret := []byte(value)
+		return secret, secret, nil
+	}
+
+	pemData, err := os.ReadFile(value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if method == jwt.SigningMethodRS256 {
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(pemData); err == nil {
+			return private, &private.PublicKey, nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(pemData)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, public, nil
+	}
+
+	if private, err := jwt.ParseEdPrivateKeyFromPEM(pemData); err == nil {
+		return private, private.(ed25519.PrivateKey).Public(), nil
+	}
+	public, err := jwt.ParseEdPublicKeyFromPEM(pemData)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, public, nil
+}
+
+// publicKeys returns the asymmetric verification keys by kid.
+func (k *KeySet) publicKeys() map[string]crypto.PublicKey {
+	keys := make(map[string]crypto.PublicKey)
+	for kid, key := range k.verificationKeys {
+		switch key.(type) {
+		case *rsa.PublicKey, ed25519.PublicKey:
+			keys[kid] = key
+		}
+	}
+	return keys
+}
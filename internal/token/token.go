@@ -0,0 +1,117 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// Access tokens are short lived; clients renew them with the refresh token.
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are carried by every access token issued by Health-Sync.
+type Claims struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+	Email  string `json:"email"`
+	// SessionID names the login session (refresh token family) the token
+	// was issued for, so signing out a device also rejects its access token.
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+var (
+	defaultKeys     *KeySet
+	defaultKeysErr  error
+	defaultKeysOnce sync.Once
+)
+
+// Keys returns the key set configured through the environment. It is loaded
+// on first use so that main has already read the .env file.
+func Keys() (*KeySet, error) {
+	defaultKeysOnce.Do(func() {
+		defaultKeys, defaultKeysErr = LoadKeySetFromEnv()
+		if defaultKeysErr != nil {
+			log.Printf("token: failed to load signing keys: %v", defaultKeysErr)
+		}
+	})
+	return defaultKeys, defaultKeysErr
+}
+
+// Generate signs an access token for the given subject and session with the
+// active key.
+func Generate(userID, email, role, sessionID string) (string, error) {
+	keys, err := Keys()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := Claims{
+		UserID:    userID,
+		Role:      role,
+		Email:     email,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(), // jti, used to revoke the token on logout
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	}
+
+	return keys.Sign(claims)
+}
+
+// Parse verifies an access token against the configured keys and returns
+// its claims.
+func Parse(tokenString string) (*Claims, error) {
+	keys, err := Keys()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Parse(tokenString)
+}
+
+// Sign signs the claims with the active key and sets its kid header.
+func (k *KeySet) Sign(claims Claims) (string, error) {
+	t := jwt.NewWithClaims(k.method, claims)
+	t.Header["kid"] = k.activeKID
+
+	signed, err := t.SignedString(k.signingKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return signed, nil
+}
+
+// Parse verifies the token signature with the key named by its kid header,
+// only accepting the configured algorithm, and validates the claims.
+func (k *KeySet) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	t, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := k.verificationKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	}, jwt.WithValidMethods([]string{k.method.Alg()}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !t.Valid || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
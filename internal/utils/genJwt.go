@@ -1,52 +0,0 @@
-package utils
-
-import (
-	"fmt"
-	"log"
-	"os"
-	"time"
-
-	"github.com/dgrijalva/jwt-go"
-	"github.com/joho/godotenv"
-)
-
-var JWTSecretKey []byte // Declare as package-level variable
-
-func init() {
-	// Load environment variables from .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Warning: No .env file found")
-	}
-	JWTSecretKey = []byte(os.Getenv("JWT_SECRET")) // Initialize
-}
-
-type Claims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
-	Email  string `json:"email"`
-	jwt.StandardClaims
-}
-
-func GenerateJWT(userID, email, role string) (string, error) {
-
-	expirationTime := time.Now().Add(24 * time.Hour)
-
-	claims := &Claims{
-		UserID: userID,
-		Role:   role,
-		Email:  email,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString(JWTSecretKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to sign JWT: %w", err) // Return the error
-	}
-
-	return tokenString, nil
-}